@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Options of adaptive AutoEvents, which poll at the min interval while the
+// readings are changing and back off up to the max interval while they are
+// stable. The Frequency is the initial interval, e.g. a Frequency of "1s"
+// with min "100ms", max "30s" and threshold "0.5".
+const (
+	// MinIntervalOption is the shortest interval, the Frequency by default.
+	MinIntervalOption = "min"
+	// MaxIntervalOption is the longest interval, the Frequency by default.
+	MaxIntervalOption = "max"
+	// ThresholdOption is the change of a numeric reading since the previous
+	// run which is considered stable. It is an absolute value, e.g. "0.5",
+	// or a percentage of the previous value, e.g. "2%". Any change counts by
+	// default, other readings count if their value changed.
+	ThresholdOption = "threshold"
+	// BackoffOption is the factor the interval grows by after every stable
+	// run, 2 by default.
+	BackoffOption = "backoff"
+
+	DefaultBackoff = 2.0
+)
+
+// adaptiveSchedule runs at an interval between min and max adapted to the
+// readings of the previous runs. Unlike intervalSchedule it is not aligned
+// to the wall clock.
+type adaptiveSchedule struct {
+	min       time.Duration
+	max       time.Duration
+	threshold tolerance
+	backoff   float64
+	window    *timeWindow
+	mutex     sync.Mutex
+	period    time.Duration
+	last      map[string]string // previous values by reading name
+}
+
+func newAdaptiveSchedule(values map[string]string, frequency time.Duration, window *timeWindow) (*adaptiveSchedule, error) {
+	s := &adaptiveSchedule{window: window, period: frequency, backoff: DefaultBackoff, last: make(map[string]string)}
+	var err error
+	s.min, err = parsePositiveDuration(values, MinIntervalOption, frequency)
+	if err != nil {
+		return nil, err
+	}
+	s.max, err = parsePositiveDuration(values, MaxIntervalOption, frequency)
+	if err != nil {
+		return nil, err
+	}
+	if s.min > frequency || s.max < frequency {
+		return nil, fmt.Errorf("frequency %v must be between the %s option %v and the %s option %v",
+			frequency, MinIntervalOption, s.min, MaxIntervalOption, s.max)
+	}
+	s.threshold, err = parseTolerance(values, ThresholdOption)
+	if err != nil {
+		return nil, err
+	}
+	if v := values[BackoffOption]; v != "" {
+		s.backoff, err = strconv.ParseFloat(v, 64)
+		if err != nil || s.backoff <= 1 || math.IsInf(s.backoff, 0) {
+			return nil, fmt.Errorf("%s option %s must be a number greater than 1", BackoffOption, v)
+		}
+	}
+	return s, nil
+}
+
+func (s *adaptiveSchedule) next(after time.Time) time.Time {
+	t := after.Add(s.currentPeriod())
+	if s.window == nil || s.window.contains(t) {
+		return t
+	}
+	return s.window.nextStart(t)
+}
+
+// currentPeriod returns the current interval.
+func (s *adaptiveSchedule) currentPeriod() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.period
+}
+
+// adapt compares the readings of the events of a run to those of the
+// previous run, the interval is reset to min if any changed beyond the
+// threshold and grows by the backoff factor up to max otherwise. It returns
+// the new interval.
+func (s *adaptiveSchedule) adapt(evts ...*dsModels.Event) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := false
+	for _, evt := range evts {
+		for i, r := range evt.Readings {
+			value := r.Value
+			checksum, binary := evt.Checksum(i)
+			if binary {
+				value = strconv.FormatUint(checksum, 16)
+			}
+			last, ok := s.last[r.Name]
+			if ok && s.changed(r, binary, last, value) {
+				changed = true
+			}
+			s.last[r.Name] = value
+		}
+	}
+
+	if changed {
+		s.period = s.min
+	} else if s.period < s.max {
+		s.period = time.Duration(math.Min(float64(s.period)*s.backoff, float64(s.max)))
+	}
+	return s.period
+}
+
+// changed reports whether the value of the reading changed beyond the
+// threshold since the last value, binary values are compared by checksum.
+func (s *adaptiveSchedule) changed(r contract.Reading, binary bool, last string, value string) bool {
+	if last == value {
+		return false
+	}
+	v, err := common.ReadingValueToFloat64(r)
+	if err != nil || binary {
+		return true
+	}
+	r.Value = last
+	l, err := common.ReadingValueToFloat64(r)
+	if err != nil {
+		return true
+	}
+	return s.threshold.exceeded(l, v)
+}
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestAdaptiveSchedule(t *testing.T) {
+	opts, err := parseFrequency("1s", map[string]string{"min": "100ms", "max": "4s", "threshold": "0.5"})
+	if err != nil {
+		t.Fatalf("Fail to parse adaptive frequency: %v", err)
+	}
+	s, ok := opts.schedule.(*adaptiveSchedule)
+	if !ok {
+		t.Fatalf("Unexpect schedule %T", opts.schedule)
+	}
+	reading := func(value string) *dsModels.Event {
+		return &dsModels.Event{Event: contract.Event{Readings: []contract.Reading{{Name: "Level", Value: value, ValueType: contract.ValueTypeFloat64}}}}
+	}
+
+	tests := []struct {
+		value  string
+		period time.Duration
+	}{
+		{"10", 2 * time.Second},          // the first run only records the value
+		{"10.4", 4 * time.Second},        // within the threshold
+		{"10.4", 4 * time.Second},        // capped at max
+		{"12", 100 * time.Millisecond},   // changing
+		{"13", 100 * time.Millisecond},   // still changing
+		{"13.2", 200 * time.Millisecond}, // stable, backing off
+		{"13.2", 400 * time.Millisecond}, // stable
+		{"13.2", 800 * time.Millisecond}, // stable
+		{"14.0", 100 * time.Millisecond}, // changing again
+		{"on", 100 * time.Millisecond},   // non-numeric values change
+		{"on", 200 * time.Millisecond},   // non-numeric values are stable
+	}
+	for i, tt := range tests {
+		if period := s.adapt(reading(tt.value)); period != tt.period {
+			t.Fatalf("Unexpect period %v after run %d, should be %v", period, i, tt.period)
+		}
+	}
+	now := time.Now()
+	if next := s.next(now); !next.Equal(now.Add(200 * time.Millisecond)) {
+		t.Fatalf("Unexpect next run %v", next)
+	}
+}
+
+func TestParseFrequency_adaptive(t *testing.T) {
+	opts, err := parseFrequency("10s", map[string]string{"max": "1m", "backoff": "1.5"})
+	if err != nil {
+		t.Fatalf("Fail to parse adaptive frequency: %v", err)
+	}
+	s := opts.schedule.(*adaptiveSchedule)
+	if s.min != 10*time.Second || s.max != time.Minute || s.backoff != 1.5 {
+		t.Fatalf("Unexpect adaptive schedule %+v", s)
+	}
+	for _, options := range []map[string]string{
+		{"min": "2s"},
+		{"max": "500ms"},
+		{"min": "100ms", "backoff": "1"},
+		{"min": "100ms", "threshold": "abc"},
+		{"min": "100ms", "jitter": "200ms"},
+		{"min": "100ms", "aggregate": "max", "interval": "10s"},
+	} {
+		if _, err := parseFrequency("1s", options); err == nil {
+			t.Fatalf("Options %v should be invalid", options)
+		}
+	}
+}
+
+func TestExecutorAdapt(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	setOptions("device", "Level", common.AutoEventOptionsInfo{MinIntervalOption: "500ms", MaxIntervalOption: "2s"})
+	e, err := NewExecutor("device", contract.AutoEvent{Resource: "Level", Frequency: "1s"})
+	setOptions("device", "Level", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Metrics().Period != time.Second || e.Status().Period != time.Second {
+		t.Fatalf("Unexpect initial period %v", e.Metrics().Period)
+	}
+	evt := &dsModels.Event{Event: contract.Event{Readings: []contract.Reading{{Name: "Level", Value: "1", ValueType: contract.ValueTypeInt32}}}}
+	e.(*executor).adapt(evt)
+	e.(*executor).adapt(nil)
+	if e.Metrics().Period != 2*time.Second {
+		t.Fatalf("Unexpect period %v, failed reads should not adapt it", e.Metrics().Period)
+	}
+
+	fixed, _ := NewExecutor("device", contract.AutoEvent{Resource: "Level", Frequency: "15s"})
+	cron, _ := NewExecutor("device", contract.AutoEvent{Resource: "Level", Frequency: "cron:* * * * *"})
+	if fixed.Metrics().Period != 15*time.Second || cron.Metrics().Period != 0 {
+		t.Fatalf("Unexpect periods %v and %v", fixed.Metrics().Period, cron.Metrics().Period)
+	}
+}
@@ -0,0 +1,219 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Aggregate functions of the AggregateOption. min, max and last publish one of
+// the sampled values with its ValueType, mean and stddev publish a Float64 and
+// count an Uint64 reading.
+const (
+	AggregateMin    = "min"
+	AggregateMax    = "max"
+	AggregateMean   = "mean"
+	AggregateLast   = "last"
+	AggregateCount  = "count"
+	AggregateStddev = "stddev"
+
+	// AggregateSeparator joins the DeviceResource name and the aggregate
+	// function in the name of an aggregate reading, e.g. "Vibration_max".
+	AggregateSeparator = "_"
+)
+
+type sample struct {
+	reading contract.Reading
+	value   float64
+	numeric bool
+	at      time.Time
+}
+
+// aggregation collects the readings sampled by an Executor and summarizes them
+// over a tumbling or sliding window. Aggregate readings and their event take
+// the end of the window as Origin.
+type aggregation struct {
+	functions   []string
+	interval    time.Duration
+	sliding     bool
+	size        time.Duration
+	samples     []sample
+	windowStart time.Time
+}
+
+func newAggregation(values map[string]string, frequency time.Duration) (*aggregation, error) {
+	a := &aggregation{}
+	for _, f := range strings.Split(values[AggregateOption], ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch f {
+		case AggregateMin, AggregateMax, AggregateMean, AggregateLast, AggregateCount, AggregateStddev:
+			a.functions = append(a.functions, f)
+		default:
+			return nil, fmt.Errorf("unsupported aggregate function %s", f)
+		}
+	}
+
+	if values[IntervalOption] == "" {
+		return nil, fmt.Errorf("%s option is required to aggregate readings", IntervalOption)
+	}
+	interval, err := parsePositiveDuration(values, IntervalOption, 0)
+	if err != nil {
+		return nil, err
+	}
+	if interval < frequency {
+		return nil, fmt.Errorf("%s option %v is shorter than the frequency %v", IntervalOption, interval, frequency)
+	}
+	a.interval = interval
+
+	switch strings.ToLower(values[WindowOption]) {
+	case "", TumblingWindow:
+	case SlidingWindow:
+		a.sliding = true
+		a.size, err = parsePositiveDuration(values, WindowSizeOption, interval)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s option %s", WindowOption, values[WindowOption])
+	}
+	return a, nil
+}
+
+// add collects the readings of the event, binary readings are ignored.
+func (a *aggregation) add(evt *dsModels.Event, now time.Time) {
+	if a.windowStart.IsZero() {
+		a.windowStart = now
+	}
+	if evt == nil {
+		return
+	}
+	for _, r := range evt.Readings {
+		if len(r.BinaryValue) > 0 {
+			continue
+		}
+		s := sample{reading: r, at: now}
+		v, err := common.ReadingValueToFloat64(r)
+		if err == nil {
+			s.value, s.numeric = v, true
+		}
+		a.samples = append(a.samples, s)
+	}
+}
+
+// due reports whether the current window is complete.
+func (a *aggregation) due(now time.Time) bool {
+	return !a.windowStart.IsZero() && now.Sub(a.windowStart) >= a.interval
+}
+
+// event summarizes the samples of the window ending now and starts the next
+// window. It returns nil if no reading was sampled in the window.
+func (a *aggregation) event(deviceName string, now time.Time) *dsModels.Event {
+	a.windowStart = now
+	samples := a.samples
+	if a.sliding {
+		start := now.Add(-a.size)
+		i := 0
+		for i < len(a.samples) && !a.samples[i].at.After(start) {
+			i++
+		}
+		a.samples = a.samples[i:]
+		samples = a.samples
+	} else {
+		a.samples = nil
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var names []string
+	groups := make(map[string][]sample)
+	for _, s := range samples {
+		if _, ok := groups[s.reading.Name]; !ok {
+			names = append(names, s.reading.Name)
+		}
+		groups[s.reading.Name] = append(groups[s.reading.Name], s)
+	}
+
+	origin := now.UnixNano()
+	readings := make([]contract.Reading, 0, len(names)*len(a.functions))
+	for _, name := range names {
+		for _, f := range a.functions {
+			r, ok := aggregate(f, groups[name], deviceName)
+			if !ok {
+				continue
+			}
+			r.Name = name + AggregateSeparator + f
+			r.Origin = origin
+			readings = append(readings, r)
+		}
+	}
+	if len(readings) == 0 {
+		return nil
+	}
+	return &dsModels.Event{Event: contract.Event{Device: deviceName, Origin: origin, Readings: readings}}
+}
+
+// aggregate applies the aggregate function to the samples of one reading name.
+// Functions other than last and count are skipped for non-numeric readings.
+func aggregate(function string, samples []sample, deviceName string) (contract.Reading, bool) {
+	last := samples[len(samples)-1]
+	switch function {
+	case AggregateLast:
+		return last.reading, true
+	case AggregateCount:
+		cv, _ := dsModels.NewUint64Value("", 0, uint64(len(samples)))
+		reading := common.CommandValueToReading(cv, deviceName, "", "")
+		defer common.ReleaseReading(reading)
+		return *reading, true
+	}
+
+	numeric := make([]sample, 0, len(samples))
+	for _, s := range samples {
+		if s.numeric {
+			numeric = append(numeric, s)
+		}
+	}
+	if len(numeric) == 0 {
+		return contract.Reading{}, false
+	}
+
+	switch function {
+	case AggregateMin, AggregateMax:
+		selected := numeric[0]
+		for _, s := range numeric[1:] {
+			if (function == AggregateMin && s.value < selected.value) || (function == AggregateMax && s.value > selected.value) {
+				selected = s
+			}
+		}
+		return selected.reading, true
+	default:
+		mean := 0.0
+		for _, s := range numeric {
+			mean += s.value
+		}
+		mean /= float64(len(numeric))
+		result := mean
+		if function == AggregateStddev {
+			variance := 0.0
+			for _, s := range numeric {
+				variance += (s.value - mean) * (s.value - mean)
+			}
+			result = math.Sqrt(variance / float64(len(numeric)))
+		}
+		cv, _ := dsModels.NewFloat64Value("", 0, result)
+		reading := common.CommandValueToReading(cv, deviceName, "", last.reading.FloatEncoding)
+		defer common.ReleaseReading(reading)
+		return *reading, true
+	}
+}
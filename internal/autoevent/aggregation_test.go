@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func int16Event(values ...int16) *dsModels.Event {
+	readings := make([]contract.Reading, len(values))
+	for i, v := range values {
+		cv, _ := dsModels.NewInt16Value("Vibration", 0, v)
+		readings[i] = *common.CommandValueToReading(cv, "device", "", "")
+	}
+	return &dsModels.Event{Event: contract.Event{Device: "device", Readings: readings}}
+}
+
+func readingsByName(evt *dsModels.Event) map[string]contract.Reading {
+	result := make(map[string]contract.Reading)
+	for _, r := range evt.Readings {
+		result[r.Name] = r
+	}
+	return result
+}
+
+func TestParseFrequency(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		options   map[string]string
+		aggregate bool
+		valid     bool
+	}{
+		{"plain duration", "500ms", nil, false, true},
+		{"tumbling window", "100ms", map[string]string{"aggregate": "min,max", "interval": "10s"}, true, true},
+		{"sliding window", "100ms", map[string]string{"aggregate": "mean", "interval": "1s", "window": "sliding", "size": "1m"}, true, true},
+		{"missing interval", "100ms", map[string]string{"aggregate": "min"}, false, false},
+		{"interval shorter than frequency", "1s", map[string]string{"aggregate": "min", "interval": "100ms"}, false, false},
+		{"unknown function", "100ms", map[string]string{"aggregate": "median", "interval": "1s"}, false, false},
+		{"unknown window", "100ms", map[string]string{"aggregate": "min", "interval": "1s", "window": "hopping"}, false, false},
+		{"invalid duration", "fast", nil, false, false},
+		{"options in frequency", "100ms?aggregate=min&interval=1s", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseFrequency(tt.frequency, tt.options)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Unexpect test result, error '%v' for frequency %s and options %v", err, tt.frequency, tt.options)
+			}
+			if (opts.aggregation != nil) != tt.aggregate {
+				t.Fatalf("Unexpect test result, aggregation '%v' for frequency %s and options %v", opts.aggregation, tt.frequency, tt.options)
+			}
+		})
+	}
+}
+
+func TestAggregation_tumbling(t *testing.T) {
+	opts, _ := parseFrequency("1s", map[string]string{"aggregate": "min,max,mean,last,count,stddev", "interval": "3s"})
+	a := opts.aggregation
+	start := time.Unix(0, 0)
+
+	a.add(int16Event(2), start)
+	a.add(int16Event(4, -1), start.Add(time.Second))
+	if a.due(start.Add(2 * time.Second)) {
+		t.Fatal("window should not be complete before the interval")
+	}
+	a.add(int16Event(7), start.Add(3*time.Second))
+	end := start.Add(3 * time.Second)
+	if !a.due(end) {
+		t.Fatal("window should be complete after the interval")
+	}
+
+	evt := a.event("device", end)
+
+	if evt == nil || evt.Origin != end.UnixNano() {
+		t.Fatalf("Unexpect aggregate event %v", evt)
+	}
+	readings := readingsByName(evt)
+	expected := map[string]string{
+		"Vibration_min":   "-1",
+		"Vibration_max":   "7",
+		"Vibration_last":  "7",
+		"Vibration_count": "4",
+	}
+	for name, value := range expected {
+		if readings[name].Value != value || readings[name].Origin != end.UnixNano() {
+			t.Fatalf("Unexpect aggregate reading %s: %v, value should be %s", name, readings[name], value)
+		}
+	}
+	mean, _ := common.ReadingValueToFloat64(readings["Vibration_mean"])
+	if mean != 3 || readings["Vibration_mean"].ValueType != "Float64" {
+		t.Fatalf("Unexpect mean %v", readings["Vibration_mean"])
+	}
+	stddev, _ := common.ReadingValueToFloat64(readings["Vibration_stddev"])
+	if math.Abs(stddev-math.Sqrt(8.5)) > 1e-9 {
+		t.Fatalf("Unexpect stddev %v", stddev)
+	}
+
+	if a.event("device", end.Add(3*time.Second)) != nil {
+		t.Fatal("tumbling window should start empty")
+	}
+}
+
+func TestAggregation_sliding(t *testing.T) {
+	opts, _ := parseFrequency("1s", map[string]string{"aggregate": "count", "interval": "2s", "window": "sliding", "size": "3s"})
+	a := opts.aggregation
+	start := time.Unix(0, 0)
+	for i := 0; i < 4; i++ {
+		a.add(int16Event(int16(i)), start.Add(time.Duration(i)*time.Second))
+	}
+
+	evt := a.event("device", start.Add(4*time.Second))
+
+	if readingsByName(evt)["Vibration_count"].Value != "2" {
+		t.Fatalf("Unexpect aggregate event %v, samples older than the window size should be dropped", evt)
+	}
+	a.add(int16Event(5), start.Add(5*time.Second))
+	evt = a.event("device", start.Add(6*time.Second))
+	if readingsByName(evt)["Vibration_count"].Value != "1" {
+		t.Fatalf("Unexpect aggregate event %v, sliding window should keep recent samples", evt)
+	}
+}
+
+func TestAggregation_nonNumeric(t *testing.T) {
+	opts, _ := parseFrequency("1s", map[string]string{"aggregate": "max,last", "interval": "1s"})
+	a := opts.aggregation
+	now := time.Unix(0, 0)
+	a.add(&dsModels.Event{Event: contract.Event{Readings: []contract.Reading{{Name: "Status", ValueType: "String", Value: "OK"}}}}, now)
+
+	evt := a.event("device", now.Add(time.Second))
+
+	readings := readingsByName(evt)
+	if len(readings) != 1 || readings["Status_last"].Value != "OK" {
+		t.Fatalf("Unexpect aggregate event %v, only last should apply to string readings", evt)
+	}
+}
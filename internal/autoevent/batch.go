@@ -0,0 +1,121 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// groupAutoEvents groups the AutoEvents of the Device with the same Frequency
+// and options, in the order of their first occurrence.
+func groupAutoEvents(deviceName string, autoEvents []contract.AutoEvent) [][]contract.AutoEvent {
+	var groups [][]contract.AutoEvent
+	index := make(map[string]int)
+	for _, ae := range autoEvents {
+		options := url.Values{}
+		for name, v := range autoEventOptions(deviceName, ae.Resource) {
+			options.Set(name, v)
+		}
+		key := ae.Frequency + " " + options.Encode()
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], ae)
+	}
+	return groups
+}
+
+// newBatchExecutor creates an Executor reading the AutoEvents, which share
+// the same Frequency, with as few driver calls as possible. Every AutoEvent
+// keeps its own aggregation and OnChange state.
+func newBatchExecutor(deviceName string, autoEvents []contract.AutoEvent) (Executor, error) {
+	exec, err := NewExecutor(deviceName, autoEvents[0])
+	if err != nil {
+		return nil, err
+	}
+	leader := exec.(*executor)
+	resources := []string{autoEvents[0].Resource}
+	for _, ae := range autoEvents[1:] {
+		exec, err := NewExecutor(deviceName, ae)
+		if err != nil {
+			return nil, err
+		}
+		leader.batch = append(leader.batch, exec.(*executor))
+		resources = append(resources, ae.Resource)
+	}
+	leader.metrics.Resource = strings.Join(resources, ",")
+	return leader, nil
+}
+
+// executeBatch reads the resources of the batch and sends an event per
+// AutoEvent, or a single merged event with the EventMerged option.
+func (e *executor) executeBatch() {
+	members := e.members()
+	resources := make([]string, len(members))
+	for i, m := range members {
+		resources[i] = m.autoEvent.Resource
+	}
+	common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - executing batch %v of Device %s", resources, e.deviceName))
+
+	evts, appErrs := handler.BatchReadHandler(e.deviceName, resources)
+	e.adapt(evts...)
+	var events []*dsModels.Event
+	for i, m := range members {
+		if appErrs[i] != nil {
+			e.recordError(appErrs[i])
+		}
+		if event := m.process(evts[i], appErrs[i]); event != nil {
+			events = append(events, event)
+		}
+	}
+	if e.event == EventMerged && len(events) > 1 {
+		events = []*dsModels.Event{mergeEvents(events)}
+	}
+	for _, event := range events {
+		e.send(event)
+	}
+}
+
+// mergeEvents merges the readings and metadata of the events of a Device into
+// one event with a new origin, its command joins the commands of the events.
+func mergeEvents(events []*dsModels.Event) *dsModels.Event {
+	merged := &dsModels.Event{}
+	merged.Device = events[0].Device
+	cmds := make([]string, len(events))
+	for i, evt := range events {
+		cmds[i] = evt.Command
+		// the metadata follows the readings to their index in the merged event
+		offset := len(merged.Readings)
+		merged.Readings = append(merged.Readings, evt.Readings...)
+		for index, flags := range evt.ReadingFlags {
+			merged.AddReadingFlags(offset+index, flags)
+		}
+		for index, quality := range evt.ReadingQuality {
+			merged.AddReadingQuality(offset+index, quality)
+		}
+		for index, tags := range evt.ReadingTags {
+			merged.AddReadingTags(offset+index, tags)
+		}
+		for index, checksum := range evt.ReadingChecksums {
+			merged.AddReadingChecksum(offset+index, checksum)
+		}
+		merged.AddTags(evt.Tags)
+	}
+	merged.Command = strings.Join(cmds, ",")
+	merged.Origin = common.GetUniqueOrigin()
+	return merged
+}
@@ -0,0 +1,99 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestGroupAutoEvents(t *testing.T) {
+	defer resetOptions()
+	setOptions("Device", "b", common.AutoEventOptionsInfo{"event": "merged"})
+	setOptions("Device", "d", common.AutoEventOptionsInfo{"Event": "merged"})
+	autoEvents := []contract.AutoEvent{
+		{Resource: "a", Frequency: "1s"},
+		{Resource: "b", Frequency: "1s"},
+		{Resource: "c", Frequency: "1s"},
+		{Resource: "d", Frequency: "1s"},
+		{Resource: "e", Frequency: "5s"},
+	}
+	groups := groupAutoEvents("Device", autoEvents)
+	expected := [][]string{{"a", "c"}, {"b", "d"}, {"e"}}
+	if len(groups) != len(expected) {
+		t.Fatalf("Unexpect groups %v", groups)
+	}
+	for i, group := range groups {
+		if len(group) != len(expected[i]) {
+			t.Fatalf("Unexpect groups %v", groups)
+		}
+		for j, ae := range group {
+			if ae.Resource != expected[i][j] {
+				t.Fatalf("Unexpect groups %v", groups)
+			}
+		}
+	}
+}
+
+func TestNewBatchExecutor(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	defer resetOptions()
+	setOptions("Device", "a", common.AutoEventOptionsInfo{"event": "merged"})
+	setOptions("Device", "b", common.AutoEventOptionsInfo{"event": "merged"})
+	exec, err := newBatchExecutor("Device", []contract.AutoEvent{
+		{Resource: "a", Frequency: "1s", OnChange: true},
+		{Resource: "b", Frequency: "1s"},
+	})
+	if err != nil {
+		t.Fatalf("Fail to create batch executor: %v", err)
+	}
+	e := exec.(*executor)
+	if len(e.batch) != 1 || e.batch[0].autoEvent.Resource != "b" || e.event != EventMerged {
+		t.Fatalf("Unexpect batch executor %+v", e)
+	}
+	if e.Metrics().Resource != "a,b" {
+		t.Fatalf("Unexpect metrics resource %s", e.Metrics().Resource)
+	}
+
+	setOptions("Device", "a", common.AutoEventOptionsInfo{"event": "joined"})
+	if _, err := newBatchExecutor("Device", []contract.AutoEvent{{Resource: "a", Frequency: "1s"}}); err == nil {
+		t.Fatal("Unsupported event option should be rejected")
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	a := &dsModels.Event{}
+	a.Device = "Device"
+	a.Origin = 1
+	a.Readings = []contract.Reading{{Name: "a", Value: "1"}}
+	a.AddReadingQuality(0, dsModels.Quality{Status: dsModels.QualityBad})
+	a.AddTags(map[string]string{"site": "A"})
+	b := &dsModels.Event{}
+	b.Device = "Device"
+	b.Origin = 2
+	// a reading of the same resource as the one of a, with good quality
+	b.Readings = []contract.Reading{{Name: "b", Value: "2"}, {Name: "c", Value: "3"}, {Name: "a", Value: "4"}}
+	b.AddReadingFlags(0, []string{dsModels.FlagBlobReference})
+	b.AddReadingTags(1, map[string]string{"unit": "C"})
+	b.AddTags(map[string]string{"line": "1"})
+
+	merged := mergeEvents([]*dsModels.Event{a, b})
+	if merged.Device != "Device" || len(merged.Readings) != 4 || merged.Origin == 0 {
+		t.Fatalf("Unexpect merged event %v", merged)
+	}
+	if merged.QualityOf(0).Status != dsModels.QualityBad || !merged.QualityOf(3).IsGood() || len(merged.ReadingFlags[1]) != 1 ||
+		merged.ReadingTags[2]["unit"] != "C" {
+		t.Fatalf("Reading metadata should be merged, got %+v", merged)
+	}
+	if merged.Tags["site"] != "A" || merged.Tags["line"] != "1" {
+		t.Fatalf("Unexpect merged tags %v", merged.Tags)
+	}
+}
@@ -9,74 +9,250 @@ package autoevent
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/OneOfOne/xxhash"
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/filter"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	"github.com/edgexfoundry/device-sdk-go/internal/pipeline"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
 type Executor interface {
+	// Run executes the AutoEvent according to its schedule until ctx is done
+	// or the Executor is stopped, it calls wg.Done when it returns.
 	Run(ctx context.Context, wg *sync.WaitGroup)
+	// Stop stops the Executor, a run in progress is completed.
 	Stop()
+	// Metrics returns the run statistics of the Executor.
+	Metrics() common.AutoEventMetrics
+	// Status returns the state of the Executor.
+	Status() Status
+	// Pause suspends the scheduled runs, Resume resumes them.
+	Pause()
+	Resume()
+	// Trigger requests an immediate run besides the scheduled ones, even if
+	// the Executor is paused. It returns false if the Executor is stopped.
+	Trigger() bool
 }
 
 type executor struct {
 	deviceName   string
 	autoEvent    contract.AutoEvent
 	lastReadings map[string]interface{}
-	duration     time.Duration
-	stop         bool
-	rwmutex      sync.RWMutex
+	lastQuality  map[string]dsModels.Quality
+	schedule     schedule
+	aggregation  *aggregation
+	jitter       time.Duration
+	overrun      string
+	event        string
+	tolerance    tolerance
+	heartbeat    time.Duration
+	// lastPublished is when an event of an OnChange AutoEvent was last
+	// published, for the heartbeat.
+	lastPublished time.Time
+	// batch holds the Executors of the AutoEvents read together with this
+	// one, which run them as part of its own runs.
+	batch    []*executor
+	stop     chan struct{}
+	stopOnce sync.Once
+	trigger  chan struct{}
+	// metrics and the status fields below are guarded by metricsMutex.
+	metrics       common.AutoEventMetrics
+	paused        bool
+	lastError     string
+	lastErrorTime time.Time
+	nextRun       time.Time
+	metricsMutex  sync.Mutex
+	rwmutex       sync.RWMutex
 }
 
-// Run triggers this Executor executes the handler for the resource periodically
+// Run triggers this Executor executes the handler for the resource at the
+// ticks of its schedule. The ticks are computed from the previous tick instead
+// of the end of the previous run, so they don't drift by the time the reads
+// take.
 func (e *executor) Run(ctx context.Context, wg *sync.WaitGroup) {
-	wg.Add(1)
 	defer wg.Done()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	tick := e.schedule.next(time.Now())
 	for {
+		if tick.IsZero() {
+			common.LoggingClient.Info(fmt.Sprintf("AutoEvent - no more runs scheduled for %v", e.autoEvent))
+			return
+		}
+		e.metricsMutex.Lock()
+		e.nextRun = tick
+		e.metricsMutex.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(tick) + e.jitterDelay())
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(e.duration):
-			if e.stop {
-				return
-			}
+		case <-e.stop:
+			return
+		case <-e.trigger:
+			common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - triggered run of %v", e.autoEvent))
+			start := time.Now()
+			e.execute()
+			e.recordRun(start, time.Now())
+			continue
+		case <-timer.C:
+		}
 
-			common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvent))
-			evt, appErr := readResource(e)
-			if appErr != nil {
-				common.LoggingClient.Error(fmt.Sprintf("AutoEvent - error occurs when reading resource %s",
-					e.autoEvent.Resource))
-				continue
-			}
+		if e.isPaused() {
+			tick = e.schedule.next(tick)
+			continue
+		}
+		start := time.Now()
+		e.execute()
+		end := time.Now()
+		tick = e.nextTick(tick, start, end)
+	}
+}
 
-			if evt != nil {
-				if e.autoEvent.OnChange {
-					if compareReadings(e, evt.Readings, evt.HasBinaryValue()) {
-						common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - readings are the same as previous one %v", e.lastReadings))
-						continue
-					}
-				}
-				if evt.HasBinaryValue() {
-					common.LoggingClient.Debug("AutoEvent - pushing CBOR event")
-				} else {
-					common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - pushing event %s", evt.String()))
-				}
-				event := &dsModels.Event{Event: evt.Event}
-				// Attach origin timestamp for events if none yet specified
-				if event.Origin == 0 {
-					event.Origin = common.GetUniqueOrigin()
-				}
-				go common.SendEvent(event)
-			} else {
-				common.LoggingClient.Info(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", e.autoEvent.Resource))
-			}
+// jitterDelay returns a random delay up to the jitter option.
+func (e *executor) jitterDelay() time.Duration {
+	if e.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(e.jitter)))
+}
+
+// nextTick returns the tick following the given one and records the run. If
+// the run ended after the following tick the missed ticks are skipped, or the
+// last one is run right away with the OverrunQueue policy.
+func (e *executor) nextTick(tick time.Time, start time.Time, end time.Time) time.Time {
+	next := e.schedule.next(tick)
+
+	e.recordRun(start, end)
+
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	if next.IsZero() || next.After(end) {
+		return next
+	}
+
+	e.metrics.Overruns++
+	e.metrics.OverrunTime += end.Sub(next)
+	missed := next
+	var count uint64
+	for !next.IsZero() && !next.After(end) {
+		missed = next
+		count++
+		next = e.schedule.next(next)
+	}
+	if e.overrun == OverrunQueue {
+		count--
+		next = missed
+	}
+	e.metrics.MissedTicks += count
+	if count > 0 {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - run of %v took %v and missed %d ticks", e.autoEvent, e.metrics.LastDuration, count))
+	}
+	return next
+}
+
+// recordRun records a run in the metrics.
+func (e *executor) recordRun(start time.Time, end time.Time) {
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	e.metrics.Runs++
+	e.metrics.LastRun = start
+	e.metrics.LastDuration = end.Sub(start)
+}
+
+// recordError records the error of a read for the status.
+func (e *executor) recordError(appErr common.AppError) {
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	e.lastError = appErr.Message()
+	e.lastErrorTime = time.Now()
+}
+
+// execute reads the resource, or the resources of the batch, and sends the
+// resulting events.
+func (e *executor) execute() {
+	if len(e.batch) > 0 {
+		e.executeBatch()
+		return
+	}
+	common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvent))
+	evt, appErr := readResource(e)
+	if appErr != nil {
+		e.recordError(appErr)
+	}
+	e.adapt(evt)
+	if event := e.process(evt, appErr); event != nil {
+		e.send(event)
+	}
+}
+
+// process returns the event to be sent for the result of a read, or nil if it
+// is aggregated, filtered or unchanged.
+func (e *executor) process(evt *dsModels.Event, appErr common.AppError) *dsModels.Event {
+	if appErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - error occurs when reading resource %s",
+			e.autoEvent.Resource))
+		if e.aggregation == nil {
+			return nil
+		}
+	}
+
+	if e.aggregation != nil {
+		now := time.Now()
+		e.aggregation.add(evt, now)
+		if !e.aggregation.due(now) {
+			return nil
 		}
+		evt = e.aggregation.event(e.deviceName, now)
+	}
+
+	if evt == nil {
+		common.LoggingClient.Info(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", e.autoEvent.Resource))
+		return nil
+	}
+	if !filterReadings(e, evt) {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - all readings of resource %s are within the deadband", e.autoEvent.Resource))
+		return nil
+	}
+	if e.autoEvent.OnChange && !e.publishOnChange(evt, time.Now()) {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - readings are the same as previous one %v", e.lastReadings))
+		return nil
 	}
+	event := &dsModels.Event{Event: evt.Event, ReadingFlags: evt.ReadingFlags,
+		ReadingQuality: evt.ReadingQuality, ReadingTags: evt.ReadingTags, Tags: evt.Tags,
+		Command: e.autoEvent.Resource, ReadingChecksums: evt.ReadingChecksums}
+	// Attach origin timestamp for events if none yet specified
+	if event.Origin == 0 {
+		event.Origin = common.GetUniqueOrigin()
+	}
+	return event
+}
+
+// send queues the event in the event pipeline. A full pipeline blocks the run
+// unless the pipeline drops events, so a slow Core Data delays this Executor
+// and is accounted as overrun instead of piling up goroutines.
+func (e *executor) send(event *dsModels.Event) {
+	if event.HasBinaryValue() {
+		common.LoggingClient.Debug("AutoEvent - pushing CBOR event")
+	} else {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - pushing event %s", event.String()))
+	}
+	pipeline.Send(event)
+	EvaluateTriggers(event)
 }
 
 func readResource(e *executor) (*dsModels.Event, common.AppError) {
@@ -88,53 +264,217 @@ func readResource(e *executor) (*dsModels.Event, common.AppError) {
 	return evt, appErr
 }
 
-func compareReadings(e *executor, readings []contract.Reading, hasBinary bool) bool {
+// filterReadings applies the deadband filter to the readings of the event and
+// reports whether any reading is left to be sent.
+func filterReadings(e *executor, evt *dsModels.Event) bool {
+	device, ok := cache.Devices().ForName(e.deviceName)
+	if !ok {
+		return true
+	}
+	return filter.Deadbands().Filter(evt, device.Profile.Name)
+}
+
+// publishOnChange reports whether the event of an OnChange AutoEvent should
+// be published, i.e. its readings or their quality changed, or the heartbeat
+// period passed since the last published event.
+func (e *executor) publishOnChange(evt *dsModels.Event, now time.Time) bool {
+	sameValues := compareReadings(e, evt.Readings, evt.HasBinaryValue(), evt.ReadingChecksums)
+	sameQuality := compareQuality(e, evt)
+	if sameValues && sameQuality {
+		if e.heartbeat <= 0 || now.Sub(e.lastPublished) < e.heartbeat {
+			return false
+		}
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - publishing unchanged readings of resource %s as heartbeat", e.autoEvent.Resource))
+	}
+	e.rwmutex.Lock()
+	e.lastPublished = now
+	e.rwmutex.Unlock()
+	return true
+}
+
+// compareReadings reports whether the readings are the same as the last
+// published ones and records the changed ones. Numeric readings within the
+// tolerance of the AutoEvent are considered unchanged, their last published
+// value is kept so that slow drifts are detected. Readings referring to a
+// stored binary value are compared by the checksums of the values.
+func compareReadings(e *executor, readings []contract.Reading, hasBinary bool, checksums map[int]uint64) bool {
 	var identical bool = true
-	e.rwmutex.RLock()
-	defer e.rwmutex.RUnlock()
-	for _, r := range readings {
-		switch e.lastReadings[r.Name].(type) {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	for i, r := range readings {
+		checksum, referenced := checksums[i]
+		switch last := e.lastReadings[r.Name].(type) {
 		case uint64:
-			checksum := xxhash.Checksum64(r.BinaryValue)
-			if e.lastReadings[r.Name] != checksum {
+			if !referenced {
+				checksum = xxhash.Checksum64(r.BinaryValue)
+			}
+			if last != checksum {
 				e.lastReadings[r.Name] = checksum
 				identical = false
 			}
-		case string:
-			v, ok := e.lastReadings[r.Name]
-			if !ok || v != r.Value {
+		case float64:
+			v, err := common.ReadingValueToFloat64(r)
+			if err != nil {
 				e.lastReadings[r.Name] = r.Value
 				identical = false
+			} else if e.tolerance.exceeded(last, v) {
+				e.lastReadings[r.Name] = e.lastValue(r)
+				identical = false
+			}
+		case string:
+			if referenced {
+				e.lastReadings[r.Name] = checksum
+				identical = false
+			} else if last != r.Value {
+				e.lastReadings[r.Name] = e.lastValue(r)
+				identical = false
 			}
 		case nil:
-			if hasBinary && len(r.BinaryValue) > 0 {
+			if referenced {
+				e.lastReadings[r.Name] = checksum
+			} else if hasBinary && len(r.BinaryValue) > 0 {
 				e.lastReadings[r.Name] = xxhash.Checksum64(r.BinaryValue)
 			} else {
-				e.lastReadings[r.Name] = r.Value
+				e.lastReadings[r.Name] = e.lastValue(r)
 			}
 			identical = false
 		default:
-			common.LoggingClient.Error("Error: unsupported reading type (%T) in autoevent - %v\n", e.lastReadings[r.Name], e.autoEvent)
+			common.LoggingClient.Error(fmt.Sprintf("Error: unsupported reading type (%T) in autoevent - %v", e.lastReadings[r.Name], e.autoEvent))
+			identical = false
+		}
+	}
+	return identical
+}
+
+// lastValue returns the value of the reading recorded for OnChange, numeric
+// values are recorded as float64 if the AutoEvent has a tolerance.
+func (e *executor) lastValue(r contract.Reading) interface{} {
+	if e.tolerance.value > 0 {
+		if v, err := common.ReadingValueToFloat64(r); err == nil {
+			return v
+		}
+	}
+	return r.Value
+}
+
+// compareQuality reports whether the quality of every reading is the same as
+// the previous one, so that a change of quality alone is published by OnChange.
+func compareQuality(e *executor, evt *dsModels.Event) bool {
+	identical := true
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	for i, r := range evt.Readings {
+		q := evt.QualityOf(i)
+		if last, ok := e.lastQuality[r.Name]; !ok || last != q {
+			e.lastQuality[r.Name] = q
 			identical = false
 		}
 	}
 	return identical
 }
 
-// Stop marks this Executor stopped
+// Stop stops this Executor, it may be called more than once.
 func (e *executor) Stop() {
-	e.stop = true
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+func (e *executor) Metrics() common.AutoEventMetrics {
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	metrics := e.metrics
+	metrics.Period = e.period()
+	return metrics
+}
+
+// period returns the current interval of the schedule, 0 for cron schedules.
+func (e *executor) period() time.Duration {
+	switch s := e.schedule.(type) {
+	case *adaptiveSchedule:
+		return s.currentPeriod()
+	case intervalSchedule:
+		return s.interval
+	default:
+		return 0
+	}
+}
+
+// adapt adapts the interval of an adaptive schedule to the readings of a
+// run, the interval is kept if the reads failed.
+func (e *executor) adapt(evts ...*dsModels.Event) {
+	s, ok := e.schedule.(*adaptiveSchedule)
+	if !ok {
+		return
+	}
+	var read []*dsModels.Event
+	for _, evt := range evts {
+		if evt != nil && len(evt.Readings) > 0 {
+			read = append(read, evt)
+		}
+	}
+	if len(read) == 0 {
+		return
+	}
+	before := s.currentPeriod()
+	if after := s.adapt(read...); after != before {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - interval of %v adapted from %v to %v", e.autoEvent, before, after))
+	}
+}
+
+func (e *executor) Status() Status {
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	return Status{Device: e.metrics.Device, Resource: e.metrics.Resource, Frequency: e.metrics.Frequency,
+		Period: e.period(), Paused: e.paused, Runs: e.metrics.Runs, LastRun: e.metrics.LastRun, LastDuration: e.metrics.LastDuration,
+		LastError: e.lastError, LastErrorTime: e.lastErrorTime, NextRun: e.nextRun}
+}
+
+func (e *executor) Pause() {
+	e.metricsMutex.Lock()
+	e.paused = true
+	e.metricsMutex.Unlock()
+}
+
+func (e *executor) Resume() {
+	e.metricsMutex.Lock()
+	e.paused = false
+	e.metricsMutex.Unlock()
+}
+
+func (e *executor) isPaused() bool {
+	e.metricsMutex.Lock()
+	defer e.metricsMutex.Unlock()
+	return e.paused
+}
+
+// Trigger requests a run, requests made while a run is pending are merged.
+func (e *executor) Trigger() bool {
+	select {
+	case <-e.stop:
+		return false
+	default:
+	}
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+	return true
 }
 
 // NewExecutor creates an Executor for an AutoEvent
 func NewExecutor(deviceName string, ae contract.AutoEvent) (Executor, error) {
 	// check Frequency
-	duration, err := time.ParseDuration(ae.Frequency)
+	opts, err := parseFrequency(ae.Frequency, autoEventOptions(deviceName, ae.Resource))
 	if err != nil {
-		common.LoggingClient.Error(fmt.Sprintf("AutoEvent Frequency %s cannot be parsed error, %v", ae.Frequency, err))
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent Frequency %s or options cannot be parsed error, %v", ae.Frequency, err))
 		return nil, err
 	}
 
 	return &executor{deviceName: deviceName, autoEvent: ae,
-		lastReadings: make(map[string]interface{}), duration: duration, stop: false}, nil
+		lastReadings: make(map[string]interface{}), lastQuality: make(map[string]dsModels.Quality),
+		schedule: opts.schedule, aggregation: opts.aggregation, jitter: opts.jitter, overrun: opts.overrun, event: opts.event,
+		tolerance: opts.tolerance, heartbeat: opts.heartbeat,
+		stop: make(chan struct{}), trigger: make(chan struct{}, 1),
+		metrics: common.AutoEventMetrics{Device: deviceName, Resource: ae.Resource, Frequency: ae.Frequency}}, nil
 }
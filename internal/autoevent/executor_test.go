@@ -7,11 +7,45 @@
 package autoevent
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
+// setOptions configures the options of the AutoEvent of the Device and
+// resource, nil options remove them.
+func setOptions(deviceName string, resource string, options common.AutoEventOptionsInfo) {
+	if common.CurrentConfig == nil {
+		common.CurrentConfig = &common.ConfigurationStruct{}
+	}
+	devices := common.CurrentConfig.Device.AutoEventOptions
+	if devices == nil {
+		devices = make(map[string]map[string]common.AutoEventOptionsInfo)
+		common.CurrentConfig.Device.AutoEventOptions = devices
+	}
+	if devices[deviceName] == nil {
+		devices[deviceName] = make(map[string]common.AutoEventOptionsInfo)
+	}
+	if options == nil {
+		delete(devices[deviceName], resource)
+		return
+	}
+	devices[deviceName][resource] = options
+}
+
+// resetOptions removes the options of all AutoEvents.
+func resetOptions() {
+	if common.CurrentConfig != nil {
+		common.CurrentConfig.Device.AutoEventOptions = nil
+	}
+}
+
 func TestCompareReadings(t *testing.T) {
 	readings := make([]contract.Reading, 4)
 	readings[0] = contract.Reading{Name: "Temperature", Value: "10"}
@@ -24,24 +58,24 @@ func TestCompareReadings(t *testing.T) {
 	if err != nil {
 		t.Errorf("Autoevent executor creation failed: %v", err)
 	}
-	resultFalse := compareReadings(e.(*executor), readings, true)
+	resultFalse := compareReadings(e.(*executor), readings, true, nil)
 	if resultFalse {
 		t.Error("compare readings with cache failed, the result should be false in the first place")
 	}
 
 	readings[1] = contract.Reading{Name: "Humidity", Value: "51"}
-	resultFalse = compareReadings(e.(*executor), readings, true)
+	resultFalse = compareReadings(e.(*executor), readings, true, nil)
 	if resultFalse {
 		t.Error("compare readings with cache failed, the result should be false")
 	}
 
 	readings[3] = contract.Reading{Name: "Image", BinaryValue: []byte("This is not a image")}
-	resultFalse = compareReadings(e.(*executor), readings, true)
+	resultFalse = compareReadings(e.(*executor), readings, true, nil)
 	if resultFalse {
 		t.Error("compare readings with cache failed, the result should be false")
 	}
 
-	resultTrue := compareReadings(e.(*executor), readings, true)
+	resultTrue := compareReadings(e.(*executor), readings, true, nil)
 	if !resultTrue {
 		t.Error("compare readings with cache failed, the result should be true with unchanged readings")
 	}
@@ -51,25 +85,220 @@ func TestCompareReadings(t *testing.T) {
 		t.Errorf("Autoevent executor creation failed: %v", err)
 	}
 	// This scenario should not happen in real case
-	resultFalse = compareReadings(e.(*executor), readings, false)
+	resultFalse = compareReadings(e.(*executor), readings, false, nil)
 	if resultFalse {
 		t.Error("compare readings with cache failed, the result should be false in the first place")
 	}
 
 	readings[0] = contract.Reading{Name: "Temperature", Value: "20"}
-	resultFalse = compareReadings(e.(*executor), readings, false)
+	resultFalse = compareReadings(e.(*executor), readings, false, nil)
 	if resultFalse {
 		t.Error("compare readings with cache failed, the result should be false")
 	}
 
 	readings[3] = contract.Reading{Name: "Image", BinaryValue: []byte("This is a image")}
-	resultTrue = compareReadings(e.(*executor), readings, false)
+	resultTrue = compareReadings(e.(*executor), readings, false, nil)
 	if !resultTrue {
 		t.Error("compare readings with cache failed, the result should always be true in such scenario")
 	}
 
-	resultTrue = compareReadings(e.(*executor), readings, false)
+	resultTrue = compareReadings(e.(*executor), readings, false, nil)
 	if !resultTrue {
 		t.Error("compare readings with cache failed, the result should be true with unchanged readings")
 	}
 }
+
+func TestCompareQuality(t *testing.T) {
+	e, _ := NewExecutor("quality", contract.AutoEvent{Frequency: "500ms", OnChange: true})
+	evt := &dsModels.Event{Event: contract.Event{Readings: []contract.Reading{{Name: "Temperature", Value: "10"}}}}
+
+	if compareQuality(e.(*executor), evt) {
+		t.Error("compare quality with cache failed, the result should be false in the first place")
+	}
+	if !compareQuality(e.(*executor), evt) {
+		t.Error("compare quality with cache failed, the result should be true with unchanged quality")
+	}
+	evt.AddReadingQuality(0, dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusStale))
+	if compareQuality(e.(*executor), evt) {
+		t.Error("compare quality with cache failed, the result should be false when the quality changes")
+	}
+}
+
+func TestNextTick(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	tick := mustTime(t, "2020-03-02T10:00:00Z")
+	tests := []struct {
+		name     string
+		overrun  string
+		end      string
+		next     string
+		missed   uint64
+		overruns uint64
+	}{
+		{"in time", "", "2020-03-02T10:00:00.5Z", "2020-03-02T10:00:01Z", 0, 0},
+		{"skip", "", "2020-03-02T10:00:02.5Z", "2020-03-02T10:00:03Z", 2, 1},
+		{"queue", OverrunQueue, "2020-03-02T10:00:02.5Z", "2020-03-02T10:00:02Z", 1, 1},
+	}
+	defer resetOptions()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOptions("device", "Temperature", common.AutoEventOptionsInfo{TimezoneOption: "UTC", OverrunOption: tt.overrun})
+			e, err := NewExecutor("device", contract.AutoEvent{Resource: "Temperature", Frequency: "1s"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := e.(*executor).nextTick(tick, tick, mustTime(t, tt.end))
+			if !next.Equal(mustTime(t, tt.next)) {
+				t.Fatalf("Unexpect next tick %v, should be %s", next, tt.next)
+			}
+			m := e.Metrics()
+			if m.Runs != 1 || m.MissedTicks != tt.missed || m.Overruns != tt.overruns || m.Device != "device" {
+				t.Fatalf("Unexpect metrics %+v", m)
+			}
+			if tt.overruns > 0 && m.OverrunTime != 1500*time.Millisecond {
+				t.Fatalf("Unexpect overrun time %v", m.OverrunTime)
+			}
+		})
+	}
+}
+
+func TestExecutorStop(t *testing.T) {
+	e, err := NewExecutor("device", contract.AutoEvent{Resource: "Temperature", Frequency: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Stop()
+	e.Stop()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	done := make(chan struct{})
+	go func() {
+		e.Run(context.Background(), wg)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stopped executor should return without waiting for the next tick")
+	}
+	wg.Wait()
+}
+
+func TestParseFrequency_runOptions(t *testing.T) {
+	opts, err := parseFrequency("1s", map[string]string{"jitter": "200ms", "overrun": "queue"})
+	if err != nil || opts.jitter != 200*time.Millisecond || opts.overrun != OverrunQueue {
+		t.Fatalf("Unexpect options %+v, error %v", opts, err)
+	}
+	if opts, _ = parseFrequency("1s", nil); opts.overrun != OverrunSkip {
+		t.Fatalf("Unexpect default overrun policy %s", opts.overrun)
+	}
+	if opts, _ = parseFrequency("1s", nil); opts.event != EventSplit {
+		t.Fatalf("Unexpect default event option %s", opts.event)
+	}
+	for _, options := range []map[string]string{{"jitter": "1s"}, {"jitter": "-1s"}, {"overrun": "catchup"}, {"event": "joined"}} {
+		if _, err := parseFrequency("1s", options); err == nil {
+			t.Fatalf("Options %v should be invalid", options)
+		}
+	}
+}
+
+func TestCompareReadings_tolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance string
+		values    []string
+		identical []bool
+	}{
+		{"absolute", "0.5", []string{"10", "10.4", "10.6", "10.2", "11.2"}, []bool{false, true, false, true, false}},
+		// The last published value is kept, so a slow drift is detected.
+		{"drift", "0.5", []string{"10", "10.3", "10.6"}, []bool{false, true, false}},
+		{"relative", "10%", []string{"100", "109", "111", "120"}, []bool{false, true, false, true}},
+		{"exact", "", []string{"10", "10.0", "10"}, []bool{false, false, false}},
+	}
+	defer resetOptions()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOptions("tolerance", "Temperature", common.AutoEventOptionsInfo{ToleranceOption: tt.tolerance})
+			e, err := NewExecutor("tolerance", contract.AutoEvent{Resource: "Temperature", Frequency: "1s", OnChange: true})
+			if err != nil {
+				t.Fatalf("Autoevent executor creation failed: %v", err)
+			}
+			for i, v := range tt.values {
+				r := contract.Reading{Name: "Temperature", Value: v, ValueType: contract.ValueTypeFloat64, FloatEncoding: contract.ENotation}
+				if identical := compareReadings(e.(*executor), []contract.Reading{r}, false, nil); identical != tt.identical[i] {
+					t.Fatalf("Unexpect result %v for value %s", identical, v)
+				}
+			}
+		})
+	}
+
+	setOptions("tolerance", "Temperature", common.AutoEventOptionsInfo{ToleranceOption: "1"})
+	e, _ := NewExecutor("tolerance", contract.AutoEvent{Resource: "Temperature", Frequency: "1s", OnChange: true})
+	for _, v := range []string{"on", "on", "off"} {
+		compareReadings(e.(*executor), []contract.Reading{{Name: "State", Value: v, ValueType: contract.ValueTypeString}}, false, nil)
+	}
+	if e.(*executor).lastReadings["State"] != "off" {
+		t.Fatalf("Non-numeric readings should be compared exactly, last reading %v", e.(*executor).lastReadings["State"])
+	}
+}
+
+func TestCompareReadings_storedBinary(t *testing.T) {
+	e, err := NewExecutor("storedBinary", contract.AutoEvent{Frequency: "1s", OnChange: true})
+	if err != nil {
+		t.Fatalf("Autoevent executor creation failed: %v", err)
+	}
+	// every stored value gets a new URL, only its checksum tells a change
+	steps := []struct {
+		url       string
+		checksum  uint64
+		identical bool
+	}{
+		{"http://localhost/blob/1", 1, false},
+		{"http://localhost/blob/2", 1, true},
+		{"http://localhost/blob/3", 2, false},
+	}
+	for _, s := range steps {
+		r := contract.Reading{Name: "Image", Value: s.url}
+		if identical := compareReadings(e.(*executor), []contract.Reading{r}, false, map[int]uint64{0: s.checksum}); identical != s.identical {
+			t.Fatalf("Unexpect result %v for checksum %d", identical, s.checksum)
+		}
+	}
+}
+
+func TestPublishOnChange_heartbeat(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	defer resetOptions()
+	setOptions("heartbeat", "Temperature", common.AutoEventOptionsInfo{HeartbeatOption: "1m"})
+	e, err := NewExecutor("heartbeat", contract.AutoEvent{Resource: "Temperature", Frequency: "1s", OnChange: true})
+	if err != nil {
+		t.Fatalf("Autoevent executor creation failed: %v", err)
+	}
+	evt := &dsModels.Event{Event: contract.Event{Readings: []contract.Reading{{Name: "Temperature", Value: "10"}}}}
+	start := time.Now()
+
+	if !e.(*executor).publishOnChange(evt, start) {
+		t.Fatal("The first event should be published")
+	}
+	if e.(*executor).publishOnChange(evt, start.Add(30*time.Second)) {
+		t.Fatal("An unchanged event should not be published before the heartbeat")
+	}
+	if !e.(*executor).publishOnChange(evt, start.Add(time.Minute)) {
+		t.Fatal("An unchanged event should be published as heartbeat")
+	}
+	if e.(*executor).publishOnChange(evt, start.Add(90*time.Second)) {
+		t.Fatal("The heartbeat should restart with the last published event")
+	}
+}
+
+func TestParseFrequency_onChangeOptions(t *testing.T) {
+	opts, err := parseFrequency("1s", map[string]string{"tolerance": "2.5%", "heartbeat": "5m"})
+	if err != nil || opts.tolerance != (tolerance{value: 2.5, relative: true}) || opts.heartbeat != 5*time.Minute {
+		t.Fatalf("Unexpect options %+v, error %v", opts, err)
+	}
+	for _, options := range []map[string]string{{"tolerance": "-1"}, {"tolerance": "abc%"}, {"heartbeat": "0s"}, {"heartbeat": "5"}} {
+		if _, err := parseFrequency("1s", options); err == nil {
+			t.Fatalf("Options %v should be invalid", options)
+		}
+	}
+}
@@ -9,7 +9,10 @@ package autoevent
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 
@@ -22,6 +25,19 @@ type Manager interface {
 	StopAutoEvents()
 	RestartForDevice(deviceName string)
 	StopForDevice(deviceName string)
+	Metrics() []common.AutoEventMetrics
+	// Status returns the state of the Executors of all Devices, or of the
+	// Device given by name.
+	Status(deviceName string) ([]Status, common.AppError)
+	// Pause and Resume suspend and resume the scheduled runs of all Devices,
+	// or of the Device given by name. The state is kept when the AutoEvents
+	// of a Device are restarted but not in metadata.
+	Pause(deviceName string) common.AppError
+	Resume(deviceName string) common.AppError
+	// Trigger runs the Executors of the Device given by name right away,
+	// limited to the Executor reading resource unless it's empty. It returns
+	// the number of triggered Executors.
+	Trigger(deviceName string, resource string) (int, common.AppError)
 }
 
 var (
@@ -31,7 +47,13 @@ var (
 )
 
 type manager struct {
-	execsMap  map[string][]Executor
+	execsMap map[string][]Executor
+	// paused suspends all Executors, pausedDevices those of single Devices.
+	paused        bool
+	pausedDevices map[string]bool
+	// state keeps the OnChange state across restarts, it's nil if no state
+	// file is configured.
+	state     *stateStore
 	startOnce sync.Once
 	ctx       context.Context
 	wg        *sync.WaitGroup
@@ -40,16 +62,21 @@ type manager struct {
 func (m *manager) StartAutoEvents() {
 	mutex.Lock()
 	m.startOnce.Do(func() {
+		states := m.loadState()
 		for _, d := range cache.Devices().All() {
-			execs := triggerExecutors(d.Name, d.AutoEvents, m.ctx, m.wg)
+			execs := m.triggerExecutors(d.Name, d.AutoEvents, states[d.Name])
 			m.execsMap[d.Name] = execs
 		}
+		m.startSavingState()
 	})
 	mutex.Unlock()
 }
 
 func (m *manager) StopAutoEvents() {
 	mutex.Lock()
+	m.saveState()
+	// the saved state must not be overwritten without the stopped Executors
+	m.state = nil
 	for k, v := range m.execsMap {
 		for _, e := range v {
 			e.Stop()
@@ -59,23 +86,125 @@ func (m *manager) StopAutoEvents() {
 	mutex.Unlock()
 }
 
-func triggerExecutors(deviceName string, autoEvents []contract.AutoEvent, ctx context.Context, wg *sync.WaitGroup) []Executor {
+// loadState creates the stateStore of the configuration and returns the
+// saved OnChange states by Device name. The caller must hold the mutex.
+func (m *manager) loadState() map[string]map[string]onChangeState {
+	if common.CurrentConfig == nil {
+		return nil
+	}
+	var err error
+	m.state, err = newStateStore(common.CurrentConfig.Device.AutoEventState)
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - the OnChange state is not kept, %v", err))
+		return nil
+	}
+	if m.state == nil {
+		return nil
+	}
+	states, err := m.state.load(time.Now())
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - the OnChange state cannot be restored from %s, %v", m.state.path, err))
+		return nil
+	}
+	return states
+}
+
+// saveState writes the OnChange states of all Executors to the state file.
+// The caller must hold the mutex.
+func (m *manager) saveState() {
+	if m.state == nil {
+		return
+	}
+	devices := make(map[string]map[string]onChangeState)
+	for name, execs := range m.execsMap {
+		if states := executorStates(execs); len(states) > 0 {
+			devices[name] = states
+		}
+	}
+	if err := m.state.save(devices, time.Now()); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - the OnChange state cannot be saved to %s, %v", m.state.path, err))
+	}
+}
+
+// startSavingState starts saving the OnChange states periodically if a state
+// file is configured. The caller must hold the mutex.
+func (m *manager) startSavingState() {
+	if m.state == nil {
+		return
+	}
+	m.wg.Add(1)
+	// the interval is passed as StopAutoEvents resets m.state
+	go m.saveStatePeriodically(m.state.interval)
+}
+
+// saveStatePeriodically saves the OnChange states at the given interval until
+// the context is done, the states are saved on shutdown by StopAutoEvents.
+func (m *manager) saveStatePeriodically(interval time.Duration) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			mutex.Lock()
+			m.saveState()
+			mutex.Unlock()
+		}
+	}
+}
+
+// triggerExecutors creates and runs the Executors of the AutoEvents of a
+// Device, restoring the given states of OnChange AutoEvents. The caller must
+// hold the mutex.
+func (m *manager) triggerExecutors(deviceName string, autoEvents []contract.AutoEvent, states map[string]onChangeState) []Executor {
 	var execs []Executor
-	for _, autoEvent := range autoEvents {
-		exec, err := NewExecutor(deviceName, autoEvent)
+	for _, group := range executorGroups(deviceName, autoEvents) {
+		var exec Executor
+		var err error
+		if len(group) > 1 {
+			exec, err = newBatchExecutor(deviceName, group)
+		} else {
+			exec, err = NewExecutor(deviceName, group[0])
+		}
 		if err != nil {
-			common.LoggingClient.Error(fmt.Sprintf("AutoEvent for resource %s cannot be created, %v", autoEvent.Resource, err))
+			common.LoggingClient.Error(fmt.Sprintf("AutoEvent for resource %s cannot be created, %v", group[0].Resource, err))
 			// skip this AutoEvent if it causes error during creation
 			continue
 		}
+		if m.isPaused(deviceName) {
+			exec.Pause()
+		}
+		restoreExecutorStates([]Executor{exec}, states)
 		execs = append(execs, exec)
-		go exec.Run(ctx, wg)
+		m.wg.Add(1)
+		go exec.Run(m.ctx, m.wg)
 	}
 	return execs
 }
 
+// executorGroups returns the AutoEvents of the Device run by the same
+// Executor, they are grouped by Frequency and options if
+// Device.BatchAutoEvents is set.
+func executorGroups(deviceName string, autoEvents []contract.AutoEvent) [][]contract.AutoEvent {
+	if common.CurrentConfig != nil && common.CurrentConfig.Device.BatchAutoEvents {
+		return groupAutoEvents(deviceName, autoEvents)
+	}
+	groups := make([][]contract.AutoEvent, len(autoEvents))
+	for i, ae := range autoEvents {
+		groups[i] = []contract.AutoEvent{ae}
+	}
+	return groups
+}
+
 // RestartForDevice stops all the AutoEvents of the specific Device
 func (m *manager) RestartForDevice(deviceName string) {
+	// the OnChange state is kept, so that an update of the Device doesn't
+	// publish unchanged readings
+	mutex.Lock()
+	states := executorStates(m.execsMap[deviceName])
+	mutex.Unlock()
 	m.StopForDevice(deviceName)
 	d, ok := cache.Devices().ForName(deviceName)
 	if !ok {
@@ -83,7 +212,7 @@ func (m *manager) RestartForDevice(deviceName string) {
 	}
 
 	mutex.Lock()
-	execs := triggerExecutors(deviceName, d.AutoEvents, m.ctx, m.wg)
+	execs := m.triggerExecutors(deviceName, d.AutoEvents, states)
 	m.execsMap[deviceName] = execs
 	mutex.Unlock()
 }
@@ -101,14 +230,156 @@ func (m *manager) StopForDevice(deviceName string) {
 	mutex.Unlock()
 }
 
+// Metrics returns the run statistics of all running AutoEvents
+func (m *manager) Metrics() []common.AutoEventMetrics {
+	mutex.Lock()
+	defer mutex.Unlock()
+	var metrics []common.AutoEventMetrics
+	for _, execs := range m.execsMap {
+		for _, e := range execs {
+			metrics = append(metrics, e.Metrics())
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Device != metrics[j].Device {
+			return metrics[i].Device < metrics[j].Device
+		}
+		return metrics[i].Resource < metrics[j].Resource
+	})
+	return metrics
+}
+
+func (m *manager) Status(deviceName string) ([]Status, common.AppError) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if deviceName != "" {
+		if appErr := checkDevice(deviceName); appErr != nil {
+			return nil, appErr
+		}
+	}
+	statuses := make([]Status, 0)
+	for name, execs := range m.execsMap {
+		if deviceName != "" && name != deviceName {
+			continue
+		}
+		for _, e := range execs {
+			statuses = append(statuses, e.Status())
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Device != statuses[j].Device {
+			return statuses[i].Device < statuses[j].Device
+		}
+		return statuses[i].Resource < statuses[j].Resource
+	})
+	return statuses, nil
+}
+
+func (m *manager) Pause(deviceName string) common.AppError {
+	return m.setPaused(deviceName, true)
+}
+
+func (m *manager) Resume(deviceName string) common.AppError {
+	return m.setPaused(deviceName, false)
+}
+
+// setPaused pauses or resumes all Executors, or those of the Device given by
+// name. Resuming all Executors also resumes the Devices paused singly, while
+// the Executors of a resumed Device stay paused if all are paused.
+func (m *manager) setPaused(deviceName string, paused bool) common.AppError {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if deviceName == "" {
+		m.paused = paused
+		if !paused {
+			m.pausedDevices = make(map[string]bool)
+		}
+	} else {
+		if appErr := checkDevice(deviceName); appErr != nil {
+			return appErr
+		}
+		if paused {
+			m.pausedDevices[deviceName] = true
+		} else {
+			delete(m.pausedDevices, deviceName)
+		}
+	}
+
+	for name, execs := range m.execsMap {
+		if deviceName != "" && name != deviceName {
+			continue
+		}
+		for _, e := range execs {
+			if m.isPaused(name) {
+				e.Pause()
+			} else {
+				e.Resume()
+			}
+		}
+	}
+	return nil
+}
+
+// isPaused reports whether the Executors of the Device given by name are
+// paused, the caller must hold the mutex.
+func (m *manager) isPaused(deviceName string) bool {
+	return m.paused || m.pausedDevices[deviceName]
+}
+
+func (m *manager) Trigger(deviceName string, resource string) (int, common.AppError) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if appErr := checkDevice(deviceName); appErr != nil {
+		return 0, appErr
+	}
+	count := 0
+	for _, e := range m.execsMap[deviceName] {
+		if resource != "" && !containsResource(e.Status().Resource, resource) {
+			continue
+		}
+		if e.Trigger() {
+			count++
+		}
+	}
+	if count == 0 && resource != "" {
+		msg := fmt.Sprintf("no AutoEvent of Device: %s reads resource %s", deviceName, resource)
+		common.LoggingClient.Error(msg)
+		return 0, common.NewNotFoundError(msg, nil)
+	}
+	return count, nil
+}
+
+// containsResource reports whether resource is one of the resources of an
+// Executor, which are separated by commas for batched AutoEvents.
+func containsResource(resources string, resource string) bool {
+	for _, r := range strings.Split(resources, ",") {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDevice(deviceName string) common.AppError {
+	if _, ok := cache.Devices().ForName(deviceName); !ok {
+		msg := fmt.Sprintf("Device: %s not found", deviceName)
+		common.LoggingClient.Error(msg)
+		return common.NewNotFoundError(msg, nil)
+	}
+	return nil
+}
+
 // NewManager initiates the AutoEvent manager once
 func NewManager(ctx context.Context, wg *sync.WaitGroup) {
 	createOnce.Do(func() {
-		m = &manager{execsMap: make(map[string][]Executor), ctx: ctx, wg: wg}
+		m = &manager{execsMap: make(map[string][]Executor), pausedDevices: make(map[string]bool), ctx: ctx, wg: wg}
 	})
 }
 
 // GetManager returns Manager instance
 func GetManager() Manager {
+	if m == nil {
+		return nil
+	}
 	return m
 }
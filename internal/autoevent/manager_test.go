@@ -0,0 +1,109 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestExecutorTrigger(t *testing.T) {
+	e, err := NewExecutor("device", contract.AutoEvent{Resource: "Temperature", Frequency: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Trigger() || !e.Trigger() {
+		t.Fatal("Trigger should succeed, pending triggers are merged")
+	}
+	if n := len(e.(*executor).trigger); n != 1 {
+		t.Fatalf("Unexpect %d pending triggers", n)
+	}
+	e.Stop()
+	if e.Trigger() {
+		t.Fatal("Trigger of a stopped executor should fail")
+	}
+}
+
+func TestManagerPause(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+
+	mgr := &manager{execsMap: make(map[string][]Executor), pausedDevices: make(map[string]bool), ctx: ctx, wg: wg}
+	autoEvents := []contract.AutoEvent{{Resource: "Temperature", Frequency: "1h"}, {Resource: "Humidity", Frequency: "1h"}}
+	mgr.execsMap["device"] = mgr.triggerExecutors("device", autoEvents, nil)
+
+	if appErr := mgr.Pause(""); appErr != nil {
+		t.Fatal(appErr)
+	}
+	statuses, _ := mgr.Status("")
+	if len(statuses) != 2 || statuses[0].Resource != "Humidity" {
+		t.Fatalf("Unexpect statuses %+v", statuses)
+	}
+	for _, s := range statuses {
+		if !s.Paused {
+			t.Fatalf("Executor of %s should be paused", s.Resource)
+		}
+	}
+	// Executors restarted while paused stay paused
+	if execs := mgr.triggerExecutors("device", autoEvents[:1], nil); !execs[0].Status().Paused {
+		t.Fatal("Restarted executor should be paused")
+	} else {
+		execs[0].Stop()
+	}
+
+	mgr.pausedDevices["device"] = true
+	if appErr := mgr.Resume(""); appErr != nil {
+		t.Fatal(appErr)
+	}
+	if mgr.isPaused("device") {
+		t.Fatal("Resuming all AutoEvents should resume single paused devices")
+	}
+	statuses, _ = mgr.Status("")
+	for _, s := range statuses {
+		if s.Paused {
+			t.Fatalf("Executor of %s should be resumed", s.Resource)
+		}
+	}
+	mgr.StopAutoEvents()
+}
+
+func TestManagerStopWhileSavingState(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	dir, err := ioutil.TempDir("", "manager-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := newStateStore(common.AutoEventStateInfo{File: filepath.Join(dir, "autoevents.json"), Interval: "1ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	mgr := &manager{execsMap: make(map[string][]Executor), pausedDevices: make(map[string]bool), ctx: ctx, wg: wg, state: store}
+	mutex.Lock()
+	mgr.startSavingState()
+	mutex.Unlock()
+	// the state saver keeps running with the state reset, run with -race
+	mgr.StopAutoEvents()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,233 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// Options of an AutoEvent are configured in the Device.AutoEventOptions
+// section by Device name and Resource of the AutoEvent, e.g.
+//
+//	[Device.AutoEventOptions.Vibration-Sensor01.Acceleration]
+//	  aggregate = 'min,max,mean'
+//	  interval = '10s'
+//
+// The Frequency of an AutoEvent holds only its interval duration or cron
+// expression.
+const (
+	// AggregateOption lists the aggregate functions published instead of the
+	// sampled readings, see the Aggregate* constants.
+	AggregateOption = "aggregate"
+	// IntervalOption is the period at which aggregate readings are published.
+	IntervalOption = "interval"
+	// WindowOption is the kind of aggregation window, tumbling by default.
+	WindowOption = "window"
+	// WindowSizeOption is the length of a sliding window, the interval by default.
+	WindowSizeOption = "size"
+
+	TumblingWindow = "tumbling"
+	SlidingWindow  = "sliding"
+
+	// JitterOption is the maximum random delay added to every run, e.g. to
+	// spread the reads of many devices on the same bus. It does not shift
+	// the schedule.
+	JitterOption = "jitter"
+	// OverrunOption is the policy applied when a run takes longer than its
+	// period, see the Overrun* constants.
+	OverrunOption = "overrun"
+
+	// OverrunSkip skips the ticks missed by an overrunning run and waits for
+	// the next tick, it is the default policy.
+	OverrunSkip = "skip"
+	// OverrunQueue runs the last missed tick right after an overrunning run,
+	// the ticks missed before are skipped.
+	OverrunQueue = "queue"
+
+	// ToleranceOption is the change of a numeric reading of an OnChange
+	// AutoEvent which is still considered unchanged. It is an absolute
+	// value, e.g. "0.5", or a percentage of the last published value, e.g.
+	// "2%".
+	ToleranceOption = "tolerance"
+	// HeartbeatOption is the period after which an OnChange AutoEvent
+	// publishes its readings even if nothing changed, so that a quiet
+	// device can be told from a dead one.
+	HeartbeatOption = "heartbeat"
+
+	// EventOption controls whether the events of AutoEvents batched into
+	// one driver call are sent separately or merged into one event, see the
+	// Event* constants. It's ignored unless Device.BatchAutoEvents is set.
+	EventOption = "event"
+
+	// EventSplit sends an event per AutoEvent, it is the default.
+	EventSplit = "split"
+	// EventMerged merges the readings of the batched AutoEvents into one
+	// event.
+	EventMerged = "merged"
+)
+
+type options struct {
+	frequency   time.Duration
+	schedule    schedule
+	aggregation *aggregation
+	jitter      time.Duration
+	overrun     string
+	event       string
+	tolerance   tolerance
+	heartbeat   time.Duration
+}
+
+// tolerance is the parsed ToleranceOption.
+type tolerance struct {
+	value    float64
+	relative bool
+}
+
+// exceeded reports whether v differs from the last published value by more
+// than the tolerance.
+func (t tolerance) exceeded(last float64, v float64) bool {
+	band := t.value
+	if t.relative {
+		band = math.Abs(last) * t.value / 100
+	}
+	return math.Abs(v-last) > band
+}
+
+// parseTolerance parses an absolute or percent tolerance option given by key.
+func parseTolerance(values map[string]string, key string) (tolerance, error) {
+	setting := strings.TrimSpace(values[key])
+	if setting == "" {
+		return tolerance{}, nil
+	}
+	t := tolerance{relative: strings.HasSuffix(setting, "%")}
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(setting, "%")), 64)
+	if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return t, fmt.Errorf("invalid %s option %s", key, setting)
+	}
+	t.value = v
+	return t, nil
+}
+
+// autoEventOptions returns the options configured for the AutoEvent of the
+// Device and resource by lower-case option name.
+func autoEventOptions(deviceName string, resource string) map[string]string {
+	values := make(map[string]string)
+	if common.CurrentConfig == nil {
+		return values
+	}
+	for name, v := range common.CurrentConfig.Device.AutoEventOptions[deviceName][resource] {
+		values[strings.ToLower(name)] = v
+	}
+	return values
+}
+
+// parseFrequency parses the sampling frequency, i.e. an interval duration or a
+// cron expression, and the options of an AutoEvent.
+func parseFrequency(frequency string, values map[string]string) (options, error) {
+	var opts options
+	location, err := parseLocation(values)
+	if err != nil {
+		return opts, err
+	}
+	window, err := parseWindow(values, location)
+	if err != nil {
+		return opts, err
+	}
+
+	if strings.HasPrefix(frequency, CronPrefix) {
+		if window != nil {
+			return opts, fmt.Errorf("time window options are not supported with cron expression %s", frequency)
+		}
+		opts.schedule, err = parseCron(strings.TrimPrefix(frequency, CronPrefix), location)
+		if err != nil {
+			return opts, err
+		}
+	} else {
+		duration, err := time.ParseDuration(frequency)
+		if err != nil {
+			return opts, err
+		}
+		if duration <= 0 {
+			return opts, fmt.Errorf("frequency %s must be positive", frequency)
+		}
+		opts.frequency = duration
+		opts.schedule = intervalSchedule{interval: duration, window: window, location: location}
+		if values[MinIntervalOption] != "" || values[MaxIntervalOption] != "" {
+			opts.schedule, err = newAdaptiveSchedule(values, duration, window)
+			if err != nil {
+				return opts, err
+			}
+		}
+	}
+
+	opts.jitter, err = parsePositiveDuration(values, JitterOption, 0)
+	if err != nil {
+		return opts, err
+	}
+	if opts.frequency > 0 && opts.jitter >= opts.frequency {
+		return opts, fmt.Errorf("%s option %v must be shorter than the frequency %v", JitterOption, opts.jitter, opts.frequency)
+	}
+	adaptive, isAdaptive := opts.schedule.(*adaptiveSchedule)
+	if isAdaptive && opts.jitter >= adaptive.min {
+		return opts, fmt.Errorf("%s option %v must be shorter than the %s option %v", JitterOption, opts.jitter, MinIntervalOption, adaptive.min)
+	}
+	switch opts.overrun = strings.ToLower(values[OverrunOption]); opts.overrun {
+	case "":
+		opts.overrun = OverrunSkip
+	case OverrunSkip, OverrunQueue:
+	default:
+		return opts, fmt.Errorf("unsupported %s option %s", OverrunOption, values[OverrunOption])
+	}
+	switch opts.event = strings.ToLower(values[EventOption]); opts.event {
+	case "":
+		opts.event = EventSplit
+	case EventSplit, EventMerged:
+	default:
+		return opts, fmt.Errorf("unsupported %s option %s", EventOption, values[EventOption])
+	}
+
+	opts.tolerance, err = parseTolerance(values, ToleranceOption)
+	if err != nil {
+		return opts, err
+	}
+	opts.heartbeat, err = parsePositiveDuration(values, HeartbeatOption, 0)
+	if err != nil {
+		return opts, err
+	}
+
+	if values[AggregateOption] != "" {
+		if isAdaptive {
+			return opts, fmt.Errorf("%s option is not supported with adaptive intervals", AggregateOption)
+		}
+		opts.aggregation, err = newAggregation(values, opts.frequency)
+		if err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func parsePositiveDuration(values map[string]string, key string, defaultValue time.Duration) (time.Duration, error) {
+	v := values[key]
+	if v == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s option %s: %v", key, v, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s option %s must be positive", key, v)
+	}
+	return d, nil
+}
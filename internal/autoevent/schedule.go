@@ -0,0 +1,460 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// Besides an interval duration the Frequency of an AutoEvent can be a cron
+// expression with the CronPrefix, e.g. "cron:*/15 * * * *" for every 15
+// minutes aligned to the hour or "cron:0 2 * * *" for 02:00 daily. Interval
+// frequencies can be restricted to a time window by the window options, e.g.
+// days "mon-fri", from "08:00", to "18:00" and tz "Europe/Berlin".
+const (
+	// CronPrefix marks a Frequency holding a cron expression of 5 fields,
+	// minute hour day-of-month month day-of-week, or 6 fields with leading
+	// seconds. The @yearly, @monthly, @weekly, @daily and @hourly
+	// descriptors are supported as well.
+	CronPrefix = "cron:"
+
+	// DaysOption lists the days of the week of the time window, e.g.
+	// "mon-fri", "sat,sun", "weekdays" or "weekends".
+	DaysOption = "days"
+	// FromOption is the time of day the window starts, e.g. "08:00".
+	FromOption = "from"
+	// ToOption is the time of day the window ends, e.g. "18:00". A window
+	// ending before it starts spans midnight.
+	ToOption = "to"
+	// TimezoneOption is the IANA timezone of the cron expression or the time
+	// window, e.g. "Europe/Berlin". The local timezone is used by default.
+	TimezoneOption = "tz"
+
+	// DefaultPreviewCount is the number of next runs of a schedule preview.
+	DefaultPreviewCount = 5
+	// MaxPreviewCount limits the number of next runs of a schedule preview.
+	MaxPreviewCount = 100
+)
+
+// cronSearchYears bounds the search for the next run of a cron expression
+// which never matches, e.g. "0 0 30 2 *".
+const cronSearchYears = 5
+
+// schedule computes the runs of an AutoEvent.
+type schedule interface {
+	// next returns the first run after the given time, or the zero time if
+	// there is none.
+	next(after time.Time) time.Time
+}
+
+// ScheduleInfo is the next-run preview of an AutoEvent.
+type ScheduleInfo struct {
+	Resource  string      `json:"resource,omitempty"`
+	Frequency string      `json:"frequency"`
+	NextRuns  []time.Time `json:"nextRuns"`
+}
+
+// intervalSchedule runs at the multiples of the interval on the wall clock
+// of its location, e.g. every 15m at :00, :15, :30 and :45, so that the
+// runs don't drift by the time the reads take.
+type intervalSchedule struct {
+	interval time.Duration
+	window   *timeWindow
+	location *time.Location
+}
+
+func (s intervalSchedule) next(after time.Time) time.Time {
+	_, offset := after.In(s.location).Zone()
+	shift := time.Duration(offset) * time.Second
+	t := after.Add(shift).Truncate(s.interval).Add(s.interval).Add(-shift)
+	if s.window == nil || s.window.contains(t) {
+		return t
+	}
+	return s.window.nextStart(t)
+}
+
+// timeWindow restricts an interval schedule to a time of day range on some
+// days of the week.
+type timeWindow struct {
+	days     [7]bool // indexed by time.Weekday
+	from     time.Duration
+	to       time.Duration
+	location *time.Location
+}
+
+func clockOf(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+func (w *timeWindow) contains(t time.Time) bool {
+	t = t.In(w.location)
+	clock := clockOf(t)
+	if w.from < w.to {
+		return w.days[t.Weekday()] && clock >= w.from && clock < w.to
+	}
+	// the window spans midnight and belongs to the day it starts
+	if clock >= w.from {
+		return w.days[t.Weekday()]
+	}
+	return clock < w.to && w.days[(t.Weekday()+6)%7]
+}
+
+// nextStart returns the first start of the window at or after the given time.
+func (w *timeWindow) nextStart(after time.Time) time.Time {
+	t := after.In(w.location)
+	for i := 0; i <= 7; i++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+i, 0, 0, 0, 0, w.location).Add(w.from)
+		if w.days[start.Weekday()] && !start.Before(after) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+// cronField is the bit set of the values matching a field of a cron expression.
+type cronField uint64
+
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow cronField
+	// domStar and dowStar tell whether the day fields are unrestricted, a
+	// day matches either day field if both are restricted.
+	domStar, dowStar bool
+	location         *time.Location
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// parseCron parses a cron expression of 5 or 6 fields or a descriptor.
+func parseCron(expr string, location *time.Location) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron expression %s must have 5 or 6 fields", expr)
+	}
+
+	c := &cronSchedule{location: location}
+	var err error
+	if c.second, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if c.minute, err = parseCronField(fields[1], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[2], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[3], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[4], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	// 7 is Sunday as well
+	if c.dow, err = parseCronField(fields[5], 0, 7, dayNames); err != nil {
+		return nil, err
+	}
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+	c.domStar = fields[3] == "*" || fields[3] == "?"
+	c.dowStar = fields[5] == "*" || fields[5] == "?"
+	return c, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps,
+// e.g. "1,5-10,*/15".
+func parseCronField(field string, min, max int, names map[string]int) (cronField, error) {
+	var result cronField
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in cron field %s", field)
+			}
+			part = part[:i]
+		}
+
+		low, high := min, max
+		if part != "*" && part != "?" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return 0, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseCronValue(bounds[1], min, max, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				high = max
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %s in cron field %s", part, field)
+			}
+		}
+		for v := low; v <= high; v += step {
+			result |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+func parseCronValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid cron value %s, must be between %d and %d", s, min, max)
+	}
+	return v, nil
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom.has(t.Day())
+	dow := c.dow.has(int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (c *cronSchedule) next(after time.Time) time.Time {
+	loc := c.location
+	// start at the next whole second
+	t := after.In(loc).Add(time.Second - time.Duration(after.Nanosecond()))
+	limit := t.Year() + cronSearchYears
+
+search:
+	for t.Year() <= limit {
+		for !c.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			if t.Month() == time.January {
+				continue search
+			}
+		}
+		for !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			if t.Day() == 1 {
+				continue search
+			}
+		}
+		for !c.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			if t.Hour() == 0 {
+				continue search
+			}
+		}
+		for !c.minute.has(t.Minute()) {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			if t.Minute() == 0 {
+				continue search
+			}
+		}
+		for !c.second.has(t.Second()) {
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue search
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func parseLocation(values map[string]string) (*time.Location, error) {
+	tz := values[TimezoneOption]
+	if tz == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s option %s: %v", TimezoneOption, tz, err)
+	}
+	return location, nil
+}
+
+// parseWindow parses the time window options, it returns nil if none is set.
+func parseWindow(values map[string]string, location *time.Location) (*timeWindow, error) {
+	days, from, to := values[DaysOption], values[FromOption], values[ToOption]
+	if days == "" && from == "" && to == "" {
+		return nil, nil
+	}
+
+	w := &timeWindow{to: 24 * time.Hour, location: location}
+	var err error
+	if err = parseDays(days, &w.days); err != nil {
+		return nil, err
+	}
+	if from != "" {
+		if w.from, err = parseTimeOfDay(from); err != nil || w.from == 24*time.Hour {
+			return nil, fmt.Errorf("invalid %s option %s", FromOption, from)
+		}
+	}
+	if to != "" {
+		if w.to, err = parseTimeOfDay(to); err != nil {
+			return nil, fmt.Errorf("invalid %s option %s", ToOption, to)
+		}
+	}
+	if w.from == w.to {
+		return nil, fmt.Errorf("time window %s-%s is empty", from, to)
+	}
+	return w, nil
+}
+
+func parseDays(s string, days *[7]bool) error {
+	switch strings.ToLower(s) {
+	case "", "*", "daily":
+		s = "sun-sat"
+	case "weekdays":
+		s = "mon-fri"
+	case "weekends":
+		s = "sat,sun"
+	}
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, ok := dayNames[bounds[0]]
+		if !ok {
+			return fmt.Errorf("invalid %s option %s", DaysOption, s)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = dayNames[bounds[1]]; !ok {
+				return fmt.Errorf("invalid %s option %s", DaysOption, s)
+			}
+		}
+		// ranges may wrap around the week, e.g. fri-mon
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses "HH:MM" or "HH:MM:SS", "24:00" ends a window at
+// midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time of day %s", s)
+	}
+	var clock [3]int
+	limits := [3]int{24, 59, 59}
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 || v > limits[i] {
+			return 0, fmt.Errorf("invalid time of day %s", s)
+		}
+		clock[i] = v
+	}
+	d := time.Duration(clock[0])*time.Hour + time.Duration(clock[1])*time.Minute + time.Duration(clock[2])*time.Second
+	if d > 24*time.Hour {
+		return 0, fmt.Errorf("invalid time of day %s", s)
+	}
+	return d, nil
+}
+
+// NextRuns returns the next count runs after the given time of an AutoEvent
+// with the frequency and options, without jitter.
+func NextRuns(frequency string, options map[string]string, after time.Time, count int) ([]time.Time, error) {
+	values := make(map[string]string, len(options))
+	for name, v := range options {
+		values[strings.ToLower(name)] = v
+	}
+	opts, err := parseFrequency(frequency, values)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]time.Time, 0, count)
+	t := after
+	for len(runs) < count {
+		t = opts.schedule.next(t)
+		if t.IsZero() {
+			break
+		}
+		runs = append(runs, t)
+	}
+	return runs, nil
+}
+
+// SchedulePreview returns the next count runs of the frequency with the
+// options.
+func SchedulePreview(frequency string, options map[string]string, count int) (ScheduleInfo, common.AppError) {
+	if frequency == "" {
+		msg := "frequency is required"
+		common.LoggingClient.Error(msg)
+		return ScheduleInfo{}, common.NewBadRequestError(msg, nil)
+	}
+	runs, err := NextRuns(frequency, options, time.Now(), count)
+	if err != nil {
+		msg := fmt.Sprintf("invalid frequency %s or options: %v", frequency, err)
+		common.LoggingClient.Error(msg)
+		return ScheduleInfo{}, common.NewBadRequestError(msg, err)
+	}
+	return ScheduleInfo{Frequency: frequency, NextRuns: runs}, nil
+}
+
+// DeviceSchedulePreview returns the next count runs of every AutoEvent of the
+// Device given by name.
+func DeviceSchedulePreview(name string, count int) ([]ScheduleInfo, common.AppError) {
+	device, ok := cache.Devices().ForName(name)
+	if !ok {
+		msg := fmt.Sprintf("Device: %s not found", name)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewNotFoundError(msg, nil)
+	}
+
+	now := time.Now()
+	infos := make([]ScheduleInfo, 0, len(device.AutoEvents))
+	for _, ae := range device.AutoEvents {
+		info := ScheduleInfo{Resource: ae.Resource, Frequency: ae.Frequency}
+		runs, err := NextRuns(ae.Frequency, autoEventOptions(name, ae.Resource), now, count)
+		if err != nil {
+			common.LoggingClient.Warn(fmt.Sprintf("AutoEvent Frequency %s or options of Device %s cannot be parsed, %v", ae.Frequency, name, err))
+		}
+		info.NextRuns = runs
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestNextRuns_cron(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		tz        string
+		after     string
+		expected  []string
+	}{
+		{"every 15 minutes aligned to the hour", "cron:*/15 * * * *", "UTC", "2020-03-02T10:07:30Z",
+			[]string{"2020-03-02T10:15:00Z", "2020-03-02T10:30:00Z", "2020-03-02T10:45:00Z", "2020-03-02T11:00:00Z"}},
+		{"daily at 02:00", "cron:0 2 * * *", "UTC", "2020-03-02T02:00:00Z",
+			[]string{"2020-03-03T02:00:00Z", "2020-03-04T02:00:00Z"}},
+		{"with seconds", "cron:*/20 0 12 * * *", "UTC", "2020-03-02T12:00:30Z",
+			[]string{"2020-03-02T12:00:40Z", "2020-03-03T12:00:00Z"}},
+		{"weekdays by name", "cron:30 8 * * mon-fri", "UTC", "2020-03-06T09:00:00Z",
+			[]string{"2020-03-09T08:30:00Z", "2020-03-10T08:30:00Z"}},
+		{"day of month or week", "cron:0 0 1 * sun", "UTC", "2020-02-27T00:00:00Z",
+			[]string{"2020-03-01T00:00:00Z", "2020-03-08T00:00:00Z"}},
+		{"leap day", "cron:0 0 29 feb *", "UTC", "2020-03-01T00:00:00Z",
+			[]string{"2024-02-29T00:00:00Z"}},
+		{"descriptor", "cron:@hourly", "UTC", "2020-12-31T23:59:59Z",
+			[]string{"2021-01-01T00:00:00Z"}},
+		{"timezone", "cron:0 2 * * *", "Europe/Berlin", "2020-03-02T00:00:00Z",
+			[]string{"2020-03-02T01:00:00Z", "2020-03-03T01:00:00Z"}},
+		{"question mark wildcard", "cron:0 0 ? * mon", "UTC", "2020-03-02T00:00:00Z",
+			[]string{"2020-03-09T00:00:00Z", "2020-03-16T00:00:00Z"}},
+		{"question mark wildcards with seconds", "cron:0 0 12 ? * ?", "UTC", "2020-03-02T12:00:00Z",
+			[]string{"2020-03-03T12:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := NextRuns(tt.frequency, map[string]string{TimezoneOption: tt.tz}, mustTime(t, tt.after), len(tt.expected))
+			if err != nil {
+				t.Fatalf("Fail to get next runs of %s: %v", tt.frequency, err)
+			}
+			if len(runs) != len(tt.expected) {
+				t.Fatalf("Unexpect runs %v, should be %v", runs, tt.expected)
+			}
+			for i, run := range runs {
+				if !run.Equal(mustTime(t, tt.expected[i])) {
+					t.Fatalf("Unexpect runs %v, should be %v", runs, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestNextRuns_neverMatches(t *testing.T) {
+	runs, err := NextRuns("cron:0 0 30 2 *", nil, time.Now(), 1)
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("Unexpect runs %v of a cron expression never matching, error %v", runs, err)
+	}
+}
+
+func TestNextRuns_window(t *testing.T) {
+	// option names are case-insensitive
+	options := map[string]string{"Days": "weekdays", "From": "08:00", "To": "18:00", "TZ": "UTC"}
+	// Friday 17:59:52
+	runs, err := NextRuns("5s", options, mustTime(t, "2020-03-06T17:59:52Z"), 3)
+	if err != nil {
+		t.Fatalf("Fail to get next runs with options %v: %v", options, err)
+	}
+	expected := []string{"2020-03-06T17:59:55Z", "2020-03-09T08:00:00Z", "2020-03-09T08:00:05Z"}
+	for i, run := range runs {
+		if !run.Equal(mustTime(t, expected[i])) {
+			t.Fatalf("Unexpect runs %v, should be %v", runs, expected)
+		}
+	}
+}
+
+func TestTimeWindow_overMidnight(t *testing.T) {
+	w, err := parseWindow(map[string]string{DaysOption: "fri", FromOption: "22:00", ToOption: "06:00"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		at       string
+		contains bool
+	}{
+		{"2020-03-06T23:00:00Z", true},  // Friday night
+		{"2020-03-07T05:59:59Z", true},  // Saturday morning
+		{"2020-03-07T06:00:00Z", false}, // window ended
+		{"2020-03-07T23:00:00Z", false}, // Saturday night
+		{"2020-03-06T05:00:00Z", false}, // Friday morning belongs to Thursday
+	}
+	for _, tt := range tests {
+		if w.contains(mustTime(t, tt.at)) != tt.contains {
+			t.Fatalf("Unexpect result for %s, contains should be %v", tt.at, tt.contains)
+		}
+	}
+	if start := w.nextStart(mustTime(t, "2020-03-07T06:00:00Z")); !start.Equal(mustTime(t, "2020-03-13T22:00:00Z")) {
+		t.Fatalf("Unexpect next start %v", start)
+	}
+}
+
+func TestParseFrequency_schedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		options   map[string]string
+		valid     bool
+	}{
+		{"cron", "cron:0 2 * * *", nil, true},
+		{"cron with aggregation", "cron:* * * * * *", map[string]string{"aggregate": "max", "interval": "1m"}, true},
+		{"cron with question mark", "cron:0 0 ? * mon", nil, true},
+		{"cron with options", "cron:0 0 ? * mon?tz=UTC", nil, false},
+		{"window", "5s", map[string]string{"days": "sat,sun", "from": "10:00", "to": "24:00"}, true},
+		{"wrapping days", "5s", map[string]string{"days": "fri-mon"}, true},
+		{"too few cron fields", "cron:0 2 * *", nil, false},
+		{"cron value out of range", "cron:0 24 * * *", nil, false},
+		{"invalid cron range", "cron:0 5-2 * * *", nil, false},
+		{"invalid step", "cron:*/0 * * * *", nil, false},
+		{"cron with window", "cron:0 2 * * *", map[string]string{"from": "08:00"}, false},
+		{"unknown day", "5s", map[string]string{"days": "someday"}, false},
+		{"invalid time of day", "5s", map[string]string{"from": "8am"}, false},
+		{"empty window", "5s", map[string]string{"from": "08:00", "to": "08:00"}, false},
+		{"unknown timezone", "5s", map[string]string{"tz": "Mars/Olympus"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseFrequency(tt.frequency, tt.options)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Unexpect test result, error '%v' for frequency %s and options %v", err, tt.frequency, tt.options)
+			}
+			if tt.valid && opts.schedule == nil {
+				t.Fatalf("No schedule for frequency %s", tt.frequency)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+const (
+	// DefaultStateInterval is how often the OnChange state is written if the
+	// Device.AutoEventState.Interval setting is empty.
+	DefaultStateInterval = time.Minute
+	// DefaultStateMaxAge is how old the OnChange state may be when it's
+	// restored if the Device.AutoEventState.MaxAge setting is empty.
+	DefaultStateMaxAge = time.Hour
+)
+
+// stateValue is a last published reading of an OnChange AutoEvent, exactly
+// one field is set according to how the reading is compared.
+type stateValue struct {
+	Value    *string  `json:"value,omitempty"`
+	Number   *float64 `json:"number,omitempty"`
+	Checksum *uint64  `json:"checksum,omitempty"`
+}
+
+// onChangeState is the state of an OnChange AutoEvent.
+type onChangeState struct {
+	Readings  map[string]stateValue       `json:"readings"`
+	Quality   map[string]dsModels.Quality `json:"quality,omitempty"`
+	Published time.Time                   `json:"published"`
+}
+
+// stateFile is the content of the state file, the states are keyed by
+// Device name and then by the resource of the AutoEvent.
+type stateFile struct {
+	Saved   time.Time                           `json:"saved"`
+	Devices map[string]map[string]onChangeState `json:"devices"`
+}
+
+// stateStore reads and writes the state file.
+type stateStore struct {
+	path     string
+	interval time.Duration
+	maxAge   time.Duration
+	mutex    sync.Mutex
+}
+
+// newStateStore creates the stateStore of the Device.AutoEventState
+// configuration, it returns nil if no state file is configured.
+func newStateStore(config common.AutoEventStateInfo) (*stateStore, error) {
+	if config.File == "" {
+		return nil, nil
+	}
+	s := &stateStore{path: config.File, interval: DefaultStateInterval, maxAge: DefaultStateMaxAge}
+	var err error
+	if config.Interval != "" {
+		s.interval, err = time.ParseDuration(config.Interval)
+		if err != nil || s.interval <= 0 {
+			return nil, fmt.Errorf("invalid Device.AutoEventState.Interval %s", config.Interval)
+		}
+	}
+	if config.MaxAge != "" {
+		s.maxAge, err = time.ParseDuration(config.MaxAge)
+		if err != nil || s.maxAge <= 0 {
+			return nil, fmt.Errorf("invalid Device.AutoEventState.MaxAge %s", config.MaxAge)
+		}
+	}
+	return s, nil
+}
+
+// load reads the states of the state file, it returns nil if the file doesn't
+// exist or is older than the max age.
+func (s *stateStore) load(now time.Time) (map[string]map[string]onChangeState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if now.Sub(f.Saved) > s.maxAge {
+		common.LoggingClient.Info(fmt.Sprintf("AutoEvent - discarding the state saved at %v, it is older than %v", f.Saved, s.maxAge))
+		return nil, nil
+	}
+	return f.Devices, nil
+}
+
+// save writes the states to a temporary file which replaces the state file,
+// so that the state file is complete if the service stops while saving.
+func (s *stateStore) save(devices map[string]map[string]onChangeState, now time.Time) error {
+	data, err := json.Marshal(stateFile{Saved: now, Devices: devices})
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// onChangeState returns the state of an OnChange AutoEvent, it returns false
+// for other AutoEvents.
+func (e *executor) onChangeState() (onChangeState, bool) {
+	if !e.autoEvent.OnChange {
+		return onChangeState{}, false
+	}
+	e.rwmutex.RLock()
+	defer e.rwmutex.RUnlock()
+	state := onChangeState{Readings: make(map[string]stateValue, len(e.lastReadings)), Published: e.lastPublished}
+	for name, last := range e.lastReadings {
+		switch v := last.(type) {
+		case string:
+			state.Readings[name] = stateValue{Value: &v}
+		case float64:
+			state.Readings[name] = stateValue{Number: &v}
+		case uint64:
+			state.Readings[name] = stateValue{Checksum: &v}
+		}
+	}
+	if len(e.lastQuality) > 0 {
+		state.Quality = make(map[string]dsModels.Quality, len(e.lastQuality))
+		for name, q := range e.lastQuality {
+			state.Quality[name] = q
+		}
+	}
+	return state, true
+}
+
+// restoreOnChangeState restores the state of an OnChange AutoEvent. Numbers
+// are compared numerically to the next readings even if the AutoEvent has no
+// tolerance anymore.
+func (e *executor) restoreOnChangeState(state onChangeState) {
+	if !e.autoEvent.OnChange {
+		return
+	}
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	for name, v := range state.Readings {
+		switch {
+		case v.Value != nil:
+			e.lastReadings[name] = *v.Value
+		case v.Number != nil:
+			e.lastReadings[name] = *v.Number
+		case v.Checksum != nil:
+			e.lastReadings[name] = *v.Checksum
+		}
+	}
+	for name, q := range state.Quality {
+		e.lastQuality[name] = q
+	}
+	e.lastPublished = state.Published
+}
+
+// members returns the Executor and the Executors batched into it.
+func (e *executor) members() []*executor {
+	return append([]*executor{e}, e.batch...)
+}
+
+// executorStates returns the states of the OnChange AutoEvents of the
+// Executors by resource.
+func executorStates(execs []Executor) map[string]onChangeState {
+	states := make(map[string]onChangeState)
+	for _, exec := range execs {
+		e, ok := exec.(*executor)
+		if !ok {
+			continue
+		}
+		for _, m := range e.members() {
+			if state, ok := m.onChangeState(); ok {
+				states[m.autoEvent.Resource] = state
+			}
+		}
+	}
+	return states
+}
+
+// restoreExecutorStates restores the states of the OnChange AutoEvents of the
+// Executors by resource.
+func restoreExecutorStates(execs []Executor, states map[string]onChangeState) {
+	if len(states) == 0 {
+		return
+	}
+	for _, exec := range execs {
+		e, ok := exec.(*executor)
+		if !ok {
+			continue
+		}
+		for _, m := range e.members() {
+			if state, ok := states[m.autoEvent.Resource]; ok {
+				m.restoreOnChangeState(state)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestOnChangeState(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	dir, err := ioutil.TempDir("", "state-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := newStateStore(common.AutoEventStateInfo{File: filepath.Join(dir, "state", "autoevents.json")})
+	if err != nil || store == nil {
+		t.Fatalf("Fail to create state store: %v", err)
+	}
+
+	defer resetOptions()
+	setOptions("device", "Sensor", common.AutoEventOptionsInfo{ToleranceOption: "0.5", HeartbeatOption: "1h"})
+	ae := contract.AutoEvent{Resource: "Sensor", Frequency: "1s", OnChange: true}
+	evt := &dsModels.Event{Event: contract.Event{Readings: []contract.Reading{
+		{Name: "State", Value: "on", ValueType: contract.ValueTypeString},
+		{Name: "Temperature", Value: "20.5", ValueType: contract.ValueTypeFloat64, FloatEncoding: contract.ENotation},
+		{Name: "Image", BinaryValue: []byte("frame"), ValueType: contract.ValueTypeBinary},
+	}}}
+	evt.AddReadingQuality(1, dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusStale))
+	e, _ := NewExecutor("device", ae)
+	published := time.Now()
+	if !e.(*executor).publishOnChange(evt, published) {
+		t.Fatal("The first event should be published")
+	}
+	other, _ := NewExecutor("device", contract.AutoEvent{Resource: "Other", Frequency: "1s"})
+
+	now := time.Now()
+	states := map[string]map[string]onChangeState{"device": executorStates([]Executor{e, other})}
+	if len(states["device"]) != 1 {
+		t.Fatalf("Only the state of OnChange AutoEvents should be saved, got %v", states)
+	}
+	if err := store.save(states, now); err != nil {
+		t.Fatalf("Fail to save state: %v", err)
+	}
+	loaded, err := store.load(now.Add(time.Minute))
+	if err != nil || len(loaded["device"]) != 1 {
+		t.Fatalf("Unexpect loaded state %v: %v", loaded, err)
+	}
+
+	restored, _ := NewExecutor("device", ae)
+	restoreExecutorStates([]Executor{restored}, loaded["device"])
+	if restored.(*executor).publishOnChange(evt, published.Add(time.Minute)) {
+		t.Fatal("Unchanged readings should not be published after the state is restored")
+	}
+	evt.Readings[1].Value = "21.5"
+	if !restored.(*executor).publishOnChange(evt, published.Add(time.Minute)) {
+		t.Fatal("Changed readings should be published after the state is restored")
+	}
+
+	if loaded, err := store.load(now.Add(2 * time.Hour)); err != nil || loaded != nil {
+		t.Fatalf("State older than the max age should be discarded, got %v: %v", loaded, err)
+	}
+}
+
+func TestRestoreOnChangeState_noTolerance(t *testing.T) {
+	number := 20.5
+	state := onChangeState{Readings: map[string]stateValue{"Temperature": {Number: &number}}}
+	e, _ := NewExecutor("device", contract.AutoEvent{Resource: "Sensor", Frequency: "1s", OnChange: true})
+	e.(*executor).restoreOnChangeState(state)
+	if e.(*executor).lastReadings["Temperature"] != number {
+		t.Fatalf("The number should be restored without tolerance, got %v", e.(*executor).lastReadings["Temperature"])
+	}
+
+	r := contract.Reading{Name: "Temperature", Value: "2.05e+01", ValueType: contract.ValueTypeFloat64, FloatEncoding: contract.ENotation}
+	if !compareReadings(e.(*executor), []contract.Reading{r}, false, nil) {
+		t.Fatal("An unchanged number should not be published after the state is restored")
+	}
+	r.Value = "20.6"
+	if compareReadings(e.(*executor), []contract.Reading{r}, false, nil) {
+		t.Fatal("A changed number should be published after the state is restored")
+	}
+	if e.(*executor).lastReadings["Temperature"] != "20.6" {
+		t.Fatalf("The changed reading should be kept as value without tolerance, got %v", e.(*executor).lastReadings["Temperature"])
+	}
+}
+
+func TestNewStateStore(t *testing.T) {
+	if s, err := newStateStore(common.AutoEventStateInfo{}); s != nil || err != nil {
+		t.Fatal("No state store should be created without file")
+	}
+	s, err := newStateStore(common.AutoEventStateInfo{File: "state.json", Interval: "10s", MaxAge: "5m"})
+	if err != nil || s.interval != 10*time.Second || s.maxAge != 5*time.Minute {
+		t.Fatalf("Unexpect state store %+v: %v", s, err)
+	}
+	for _, config := range []common.AutoEventStateInfo{{File: "state.json", Interval: "often"}, {File: "state.json", MaxAge: "-1h"}} {
+		if _, err := newStateStore(config); err == nil {
+			t.Fatalf("Configuration %+v should be invalid", config)
+		}
+	}
+	if s, err := newStateStore(common.AutoEventStateInfo{File: filepath.Join(os.TempDir(), "missing-state.json")}); err != nil {
+		t.Fatal(err)
+	} else if states, err := s.load(time.Now()); states != nil || err != nil {
+		t.Fatalf("Missing state file should be ignored, got %v: %v", states, err)
+	}
+}
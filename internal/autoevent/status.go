@@ -0,0 +1,32 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"time"
+)
+
+// Status is the state of a running AutoEvent Executor, the Resource of an
+// Executor of batched AutoEvents lists their resources separated by commas.
+type Status struct {
+	Device    string `json:"device"`
+	Resource  string `json:"resource"`
+	Frequency string `json:"frequency"`
+	// Period is the current interval between runs, see
+	// common.AutoEventMetrics.
+	Period       time.Duration `json:"period,omitempty"`
+	Paused       bool          `json:"paused"`
+	Runs         uint64        `json:"runs"`
+	LastRun      time.Time     `json:"lastRun"`
+	LastDuration time.Duration `json:"lastDuration"`
+	// LastError is the message of the last failed read, LastErrorTime is
+	// when it happened.
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorTime time.Time `json:"lastErrorTime"`
+	// NextRun is the next scheduled tick, not including the jitter.
+	NextRun time.Time `json:"nextRun"`
+}
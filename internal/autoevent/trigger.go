@@ -0,0 +1,268 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	"github.com/edgexfoundry/device-sdk-go/internal/pipeline"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// DeviceResource attributes configuring triggered reads. When a reading of the
+// DeviceResource from an AutoEvent, an async reading or a REST read matches
+// the condition, the commands are read and published.
+const (
+	// TriggerReadAttribute lists the commands or DeviceResources of the
+	// Device read when the condition matches, separated by commas.
+	TriggerReadAttribute = "triggerRead"
+	// TriggerWhenAttribute is the condition, TriggerOnChange by default.
+	// Other conditions compare the value to an operand with one of the
+	// operators ==, !=, >, >=, < and <=, e.g. "==true" or ">100", an operand
+	// without operator means ==. They match when the comparison becomes true,
+	// i.e. not again for following readings as long as it stays true.
+	// Numeric values are compared numerically, others only by == and !=.
+	TriggerWhenAttribute = "triggerWhen"
+
+	// TriggerOnChange matches when the value differs from the previous
+	// reading, the first reading after start doesn't match.
+	TriggerOnChange = "change"
+)
+
+var (
+	tg     *triggers
+	tgOnce sync.Once
+)
+
+// triggerState is the last reading of a DeviceResource with a trigger rule.
+type triggerState struct {
+	value   string
+	matched bool
+}
+
+type triggers struct {
+	states  map[string]map[string]triggerState // key is Device name, then DeviceResource name
+	pending map[string]bool                    // key is Device name and command of pending reads
+	mutex   sync.Mutex
+}
+
+func getTriggers() *triggers {
+	tgOnce.Do(func() {
+		tg = &triggers{states: make(map[string]map[string]triggerState), pending: make(map[string]bool)}
+	})
+	return tg
+}
+
+// condition is a parsed TriggerWhenAttribute.
+type condition struct {
+	operator string
+	operand  string
+}
+
+func parseCondition(when string) (condition, error) {
+	when = strings.TrimSpace(when)
+	if when == "" || strings.EqualFold(when, TriggerOnChange) {
+		return condition{operator: TriggerOnChange}, nil
+	}
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+		if strings.HasPrefix(when, op) {
+			c := condition{operator: op, operand: strings.TrimSpace(strings.TrimPrefix(when, op))}
+			if c.operand == "" {
+				return c, fmt.Errorf("missing operand in condition %s", when)
+			}
+			if op != "==" && op != "!=" {
+				if _, err := strconv.ParseFloat(c.operand, 64); err != nil {
+					return c, fmt.Errorf("operand of condition %s is not numeric", when)
+				}
+			}
+			return c, nil
+		}
+	}
+	return condition{operator: "==", operand: when}, nil
+}
+
+// matches reports whether the reading satisfies the comparison.
+func (c condition) matches(r contract.Reading) bool {
+	operand, err := strconv.ParseFloat(c.operand, 64)
+	if err == nil {
+		if v, err := common.ReadingValueToFloat64(r); err == nil {
+			switch c.operator {
+			case "==":
+				return v == operand
+			case "!=":
+				return v != operand
+			case ">":
+				return v > operand
+			case ">=":
+				return v >= operand
+			case "<":
+				return v < operand
+			case "<=":
+				return v <= operand
+			}
+		}
+	}
+	switch c.operator {
+	case "==":
+		return r.Value == c.operand
+	case "!=":
+		return r.Value != c.operand
+	default:
+		return false
+	}
+}
+
+// fire updates the state of the DeviceResource of the reading and reports
+// whether the condition fires.
+func (t *triggers) fire(deviceName string, r contract.Reading, c condition) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	resources, ok := t.states[deviceName]
+	if !ok {
+		resources = make(map[string]triggerState)
+		t.states[deviceName] = resources
+	}
+	last, seen := resources[r.Name]
+	state := triggerState{value: r.Value}
+	var fired bool
+	if c.operator == TriggerOnChange {
+		fired = seen && last.value != r.Value
+	} else {
+		state.matched = c.matches(r)
+		fired = state.matched && !last.matched
+	}
+	resources[r.Name] = state
+	return fired
+}
+
+// RemoveTriggerState removes the state of the trigger rules of the Device
+// given by name, e.g. when the Device is removed.
+func RemoveTriggerState(deviceName string) {
+	t := getTriggers()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.states, deviceName)
+}
+
+// EvaluateTriggers evaluates the trigger rules of the DeviceResources of the
+// readings of the event and reads the commands of the matching rules. The
+// reads are run asynchronously, a read of a command still pending is not
+// requested again. The events of triggered reads don't trigger further reads.
+func EvaluateTriggers(event *dsModels.Event) {
+	if event == nil || len(event.Readings) == 0 {
+		return
+	}
+	device, ok := cache.Devices().ForName(event.Device)
+	if !ok {
+		return
+	}
+	var cmds []string
+	for _, r := range event.Readings {
+		dr, ok := cache.Profiles().DeviceResource(device.Profile.Name, r.Name)
+		if !ok || dr.Attributes[TriggerReadAttribute] == "" {
+			continue
+		}
+		c, err := parseCondition(dr.Attributes[TriggerWhenAttribute])
+		if err != nil {
+			common.LoggingClient.Warn(fmt.Sprintf("Trigger - invalid %s attribute of DeviceResource %s is ignored: %v", TriggerWhenAttribute, dr.Name, err))
+			continue
+		}
+		if !getTriggers().fire(device.Name, r, c) {
+			continue
+		}
+		for _, cmd := range strings.Split(dr.Attributes[TriggerReadAttribute], ",") {
+			if cmd = strings.TrimSpace(cmd); cmd != "" && !containsString(cmds, cmd) {
+				common.LoggingClient.Debug(fmt.Sprintf("Trigger - reading %s of Device %s with value %s triggers a read of %s", r.Name, device.Name, r.Value, cmd))
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+	if len(cmds) == 0 {
+		return
+	}
+
+	mgr := GetManager()
+	if mgr == nil {
+		common.LoggingClient.Warn(fmt.Sprintf("Trigger - reads %v of Device %s are skipped, AutoEvents are not started", cmds, device.Name))
+		return
+	}
+	for _, cmd := range cmds {
+		mgr.(*manager).triggerRead(device.Name, cmd)
+	}
+}
+
+// triggerRead reads the command of the Device in the background and publishes
+// the event. Reads are not started once the context of the manager is done.
+func (m *manager) triggerRead(deviceName string, cmd string) {
+	if m.ctx.Err() != nil {
+		common.LoggingClient.Debug(fmt.Sprintf("Trigger - read of %s of Device %s is skipped, the service is stopping", cmd, deviceName))
+		return
+	}
+	t := getTriggers()
+	key := deviceName + "/" + cmd
+	t.mutex.Lock()
+	if t.pending[key] {
+		t.mutex.Unlock()
+		common.LoggingClient.Debug(fmt.Sprintf("Trigger - read of %s of Device %s is pending", cmd, deviceName))
+		return
+	}
+	t.pending[key] = true
+	t.mutex.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			t.mutex.Lock()
+			delete(t.pending, key)
+			t.mutex.Unlock()
+		}()
+		readTriggered(m.ctx, deviceName, cmd)
+	}()
+}
+
+// readTriggered reads the command of the Device and publishes the event unless
+// ctx is done before the read starts or before the event is sent.
+func readTriggered(ctx context.Context, deviceName string, cmd string) {
+	if ctx.Err() != nil {
+		return
+	}
+	vars := map[string]string{common.NameVar: deviceName, common.CommandVar: cmd}
+	evt, appErr := handler.CommandHandler(vars, "", common.GetCmdMethod, "")
+	if appErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Trigger - read of %s of Device %s failed: %s", cmd, deviceName, appErr.Message()))
+		return
+	}
+	if evt == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		common.LoggingClient.Debug(fmt.Sprintf("Trigger - event of %s of Device %s is dropped, the service is stopping", cmd, deviceName))
+		return
+	}
+	if evt.Origin == 0 {
+		evt.Origin = common.GetUniqueOrigin()
+	}
+	pipeline.Send(evt)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		when     string
+		expected condition
+		valid    bool
+	}{
+		{"", condition{operator: TriggerOnChange}, true},
+		{"Change", condition{operator: TriggerOnChange}, true},
+		{"==true", condition{operator: "==", operand: "true"}, true},
+		{"true", condition{operator: "==", operand: "true"}, true},
+		{">= 100", condition{operator: ">=", operand: "100"}, true},
+		{"!=0", condition{operator: "!=", operand: "0"}, true},
+		{">", condition{}, false},
+		{"<high", condition{}, false},
+	}
+	for _, tt := range tests {
+		c, err := parseCondition(tt.when)
+		if (err == nil) != tt.valid {
+			t.Fatalf("Unexpect result for condition %s, error %v", tt.when, err)
+		}
+		if tt.valid && c != tt.expected {
+			t.Fatalf("Unexpect condition %+v for %s", c, tt.when)
+		}
+	}
+}
+
+func TestTriggersFire(t *testing.T) {
+	tr := &triggers{states: make(map[string]map[string]triggerState), pending: make(map[string]bool)}
+	reading := func(name string, value string, valueType string) contract.Reading {
+		return contract.Reading{Name: name, Value: value, ValueType: valueType}
+	}
+
+	change, _ := parseCondition("change")
+	for i, tt := range []struct {
+		value string
+		fired bool
+	}{{"0", false}, {"0", false}, {"8", true}, {"8", false}, {"0", true}} {
+		if fired := tr.fire("device", reading("AlarmWord", tt.value, contract.ValueTypeUint16), change); fired != tt.fired {
+			t.Fatalf("Unexpect result %v for change condition at reading %d", fired, i)
+		}
+	}
+
+	rising, _ := parseCondition("==true")
+	for i, tt := range []struct {
+		value string
+		fired bool
+	}{{"true", true}, {"true", false}, {"false", false}, {"true", true}} {
+		if fired := tr.fire("device", reading("Trigger", tt.value, contract.ValueTypeBool), rising); fired != tt.fired {
+			t.Fatalf("Unexpect result %v for ==true condition at reading %d", fired, i)
+		}
+	}
+
+	above, _ := parseCondition(">100")
+	for i, tt := range []struct {
+		value string
+		fired bool
+	}{{"90", false}, {"100.5", true}, {"120", false}, {"80", false}, {"101", true}} {
+		if fired := tr.fire("device", reading("Level", tt.value, contract.ValueTypeFloat64), above); fired != tt.fired {
+			t.Fatalf("Unexpect result %v for >100 condition at reading %d", fired, i)
+		}
+	}
+
+	if above.matches(reading("State", "high", contract.ValueTypeString)) {
+		t.Fatal("Non-numeric values should not match numeric comparisons")
+	}
+}
+
+func TestTriggerReadStopped(t *testing.T) {
+	common.LoggingClient = logger.MockLogger{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wg := &sync.WaitGroup{}
+	mgr := &manager{execsMap: make(map[string][]Executor), pausedDevices: make(map[string]bool), ctx: ctx, wg: wg}
+
+	mgr.triggerRead("device", "Temperature")
+	wg.Wait()
+	tr := getTriggers()
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	if tr.pending["device/Temperature"] {
+		t.Fatal("Read should not be started once the service is stopping")
+	}
+}
@@ -0,0 +1,330 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package blob stores large binary readings locally, the events only carry
+// the URL the values are served under by the blob REST endpoint.
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMaxAge is how long stored binary values are kept if the
+	// Device.Blob.MaxAge setting is empty.
+	DefaultMaxAge = 10 * time.Minute
+	// fileSuffix is the suffix of the files of stored binary values.
+	fileSuffix = ".blob"
+)
+
+// ErrNotFound is returned for unknown or expired binary values.
+var ErrNotFound = errors.New("binary value not found")
+
+var (
+	bs      *store
+	bsMutex sync.RWMutex
+)
+
+// Info describes a stored binary value.
+type Info struct {
+	ID        string
+	MediaType string
+	Size      int64
+	Created   time.Time
+}
+
+type Store interface {
+	// Put stores the binary value read from r.
+	Put(r io.Reader, mediaType string) (Info, error)
+	// Open opens the stored binary value given by id, the caller must
+	// close the file.
+	Open(id string) (*os.File, Info, error)
+	// Remove removes the stored binary value given by id, it returns false
+	// if it is not found.
+	Remove(id string) bool
+	// Expire removes the binary values stored before now minus the max age
+	// and returns how many were removed.
+	Expire(now time.Time) int
+	// Threshold is the size above which Binary readings are stored.
+	Threshold() int
+}
+
+type store struct {
+	dir       string
+	maxAge    time.Duration
+	threshold int
+	blobs     map[string]Info
+	latest    map[string]latestBlob // key is Device name and DeviceResource name
+	mutex     sync.Mutex
+}
+
+// latestBlob is the last value stored for a DeviceResource of a Device.
+type latestBlob struct {
+	id       string
+	checksum uint64
+}
+
+// NewStore creates a Store in dir, files of previously stored binary values
+// left in dir are removed.
+func NewStore(dir string, maxAge time.Duration, threshold int) (Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	leftovers, err := filepath.Glob(filepath.Join(dir, "*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range leftovers {
+		_ = os.Remove(f)
+	}
+	return &store{dir: dir, maxAge: maxAge, threshold: threshold, blobs: make(map[string]Info), latest: make(map[string]latestBlob)}, nil
+}
+
+// Init creates the Store shared by the read paths according to the
+// Device.Blob configuration and removes expired binary values until ctx is
+// done.
+func Init(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.Device.Blob
+	maxAge := DefaultMaxAge
+	if config.MaxAge != "" {
+		var err error
+		maxAge, err = time.ParseDuration(config.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return fmt.Errorf("invalid Device.Blob.MaxAge %s", config.MaxAge)
+		}
+	}
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), common.ServiceName+"-blobs")
+	}
+
+	s, err := NewStore(dir, maxAge, config.Threshold)
+	if err != nil {
+		return err
+	}
+	bsMutex.Lock()
+	bs = s.(*store)
+	bsMutex.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(maxAge / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if n := s.Expire(now); n > 0 {
+					common.LoggingClient.Debug(fmt.Sprintf("Removed %d expired binary values", n))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Blobs returns the Store created by Init, or nil if it's not initialized.
+func Blobs() Store {
+	bsMutex.RLock()
+	defer bsMutex.RUnlock()
+	if bs == nil {
+		return nil
+	}
+	return bs
+}
+
+// URL returns the URL the binary value given by id is served under.
+func URL(id string) string {
+	return common.BuildAddr(common.CurrentConfig.Service.Host, strconv.Itoa(common.CurrentConfig.Service.Port)) +
+		strings.Replace(common.APIBlobRoute, "{"+common.IdVar+"}", id, 1)
+}
+
+// StoreReading stores the value of a Binary CommandValue backed by a stream,
+// or larger than the threshold of the Store, and replaces the BinaryValue of
+// the reading by the URL of the stored value. It returns true and the
+// checksum of the value if the value is stored, the reading should be marked
+// by FlagBlobReference then and the checksum recorded for comparing reads. A
+// value equal to the last one stored for the DeviceResource of the Device
+// refers to the stored one instead of being stored again. Without Store the
+// stream is read into the BinaryValue up to MaxBinaryBytes.
+func StoreReading(cv *dsModels.CommandValue, reading *contract.Reading) (bool, uint64, error) {
+	if cv.Type != dsModels.Binary {
+		return false, 0, nil
+	}
+	s := Blobs()
+	r := cv.BinaryStream()
+	if r == nil {
+		if s == nil || s.Threshold() <= 0 || len(cv.BinValue) <= s.Threshold() {
+			return false, 0, nil
+		}
+		r = bytes.NewReader(cv.BinValue)
+	}
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if s == nil {
+		data, err := ioutil.ReadAll(io.LimitReader(r, dsModels.MaxBinaryBytes+1))
+		if err != nil {
+			return false, 0, err
+		}
+		if len(data) > dsModels.MaxBinaryBytes {
+			return false, 0, fmt.Errorf("binary stream exceeds limit for binary readings (%v bytes)", dsModels.MaxBinaryBytes)
+		}
+		reading.BinaryValue = data
+		return false, 0, nil
+	}
+
+	key := reading.Device + "/" + reading.Name
+	st, dedupe := s.(*store)
+	var id string
+	var checksum uint64
+	if cv.BinaryStream() == nil {
+		// the value is at hand, so an unchanged one isn't written at all
+		checksum = xxhash.Checksum64(cv.BinValue)
+		if dedupe {
+			id = st.reuse(key, checksum)
+		}
+	}
+	if id == "" {
+		h := xxhash.New64()
+		info, err := s.Put(io.TeeReader(r, h), reading.MediaType)
+		if err != nil {
+			return false, 0, err
+		}
+		id, checksum = info.ID, h.Sum64()
+		if dedupe {
+			id = st.keep(key, id, checksum)
+		}
+	}
+	reading.BinaryValue = nil
+	reading.Value = URL(id)
+	return true, checksum, nil
+}
+
+// reuse returns the id of the last value stored for the key if it has the
+// checksum and is not expired, its expiry starts over. It returns an empty id
+// otherwise.
+func (s *store) reuse(key string, checksum uint64) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	last, ok := s.latest[key]
+	if !ok || last.checksum != checksum {
+		return ""
+	}
+	info, ok := s.blobs[last.id]
+	if !ok {
+		return ""
+	}
+	info.Created = time.Now()
+	s.blobs[last.id] = info
+	return last.id
+}
+
+// keep records the value given by id as the last one stored for the key,
+// unless the last one has the same checksum. Then the value given by id is
+// removed and the id of the last one is returned.
+func (s *store) keep(key string, id string, checksum uint64) string {
+	if lastID := s.reuse(key, checksum); lastID != "" && lastID != id {
+		s.Remove(id)
+		return lastID
+	}
+	s.mutex.Lock()
+	s.latest[key] = latestBlob{id: id, checksum: checksum}
+	s.mutex.Unlock()
+	return id
+}
+
+func (s *store) Put(r io.Reader, mediaType string) (Info, error) {
+	info := Info{ID: uuid.New().String(), MediaType: mediaType, Created: time.Now()}
+	path := s.path(info.ID)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return Info{}, err
+	}
+	info.Size, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return Info{}, err
+	}
+
+	s.mutex.Lock()
+	s.blobs[info.ID] = info
+	s.mutex.Unlock()
+	return info, nil
+}
+
+func (s *store) Open(id string) (*os.File, Info, error) {
+	s.mutex.Lock()
+	info, ok := s.blobs[id]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, Info{}, ErrNotFound
+	}
+	f, err := os.Open(s.path(id))
+	if os.IsNotExist(err) {
+		err = ErrNotFound
+	}
+	return f, info, err
+}
+
+func (s *store) Remove(id string) bool {
+	s.mutex.Lock()
+	_, ok := s.blobs[id]
+	delete(s.blobs, id)
+	s.mutex.Unlock()
+	if ok {
+		_ = os.Remove(s.path(id))
+	}
+	return ok
+}
+
+func (s *store) Expire(now time.Time) int {
+	var expired []string
+	s.mutex.Lock()
+	for id, info := range s.blobs {
+		if now.Sub(info.Created) >= s.maxAge {
+			expired = append(expired, id)
+			delete(s.blobs, id)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, id := range expired {
+		_ = os.Remove(s.path(id))
+	}
+	return len(expired)
+}
+
+func (s *store) Threshold() int {
+	return s.threshold
+}
+
+func (s *store) path(id string) string {
+	return filepath.Join(s.dir, id+fileSuffix)
+}
@@ -0,0 +1,158 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package blob
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "blob-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStore(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	leftover := filepath.Join(dir, "old"+fileSuffix)
+	if err := ioutil.WriteFile(leftover, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStore(dir, time.Minute, 0)
+	if err != nil {
+		t.Fatalf("Fail to create store: %v", err)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatal("Leftover binary values should be removed")
+	}
+
+	info, err := s.Put(strings.NewReader("waveform"), "application/octet-stream")
+	if err != nil || info.Size != 8 || info.ID == "" {
+		t.Fatalf("Unexpect stored value %v: %v", info, err)
+	}
+	f, opened, err := s.Open(info.ID)
+	if err != nil || opened != info {
+		t.Fatalf("Unexpect opened value %v: %v", opened, err)
+	}
+	data, _ := ioutil.ReadAll(f)
+	f.Close()
+	if string(data) != "waveform" {
+		t.Fatalf("Unexpect stored data %s", data)
+	}
+
+	if n := s.Expire(info.Created.Add(30 * time.Second)); n != 0 {
+		t.Fatalf("%d values should not be expired yet", n)
+	}
+	if n := s.Expire(info.Created.Add(time.Minute)); n != 1 {
+		t.Fatalf("Unexpect %d expired values", n)
+	}
+	if _, _, err := s.Open(info.ID); err != ErrNotFound {
+		t.Fatalf("Expired value should not be found: %v", err)
+	}
+
+	info, _ = s.Put(strings.NewReader("image"), "image/jpeg")
+	if !s.Remove(info.ID) || s.Remove(info.ID) {
+		t.Fatal("Value should be removed once")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*"+fileSuffix)); len(files) != 0 {
+		t.Fatalf("Unexpect files %v left", files)
+	}
+}
+
+func TestStoreReading(t *testing.T) {
+	common.CurrentConfig = &common.ConfigurationStruct{Service: common.ServiceInfo{Host: "localhost", Port: 49990}}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	defer func() { bs = nil }()
+
+	bs = nil
+	cv := dsModels.NewBinaryStreamValue("Image", 0, strings.NewReader("frame"))
+	reading := &contract.Reading{Name: "Image"}
+	if stored, _, err := StoreReading(cv, reading); stored || err != nil || string(reading.BinaryValue) != "frame" {
+		t.Fatalf("Stream should be read into the reading without store, reading %v, error %v", reading, err)
+	}
+
+	s, _ := NewStore(dir, time.Minute, 4)
+	bs = s.(*store)
+
+	small, _ := dsModels.NewBinaryValue("Image", 0, []byte("abc"))
+	reading = &contract.Reading{Name: "Image", BinaryValue: small.BinValue}
+	if stored, _, err := StoreReading(small, reading); stored || err != nil {
+		t.Fatalf("Value below threshold should not be stored: %v", err)
+	}
+
+	large, _ := dsModels.NewBinaryValue("Image", 0, []byte("abcdef"))
+	reading = &contract.Reading{Name: "Image", BinaryValue: large.BinValue, MediaType: "image/jpeg"}
+	stored, checksum, err := StoreReading(large, reading)
+	if !stored || err != nil || reading.BinaryValue != nil || checksum != xxhash.Checksum64([]byte("abcdef")) {
+		t.Fatalf("Value above threshold should be stored, reading %v, error %v", reading, err)
+	}
+	prefix := "http://localhost:49990" + common.APIBlobPrefix + "/"
+	if !strings.HasPrefix(reading.Value, prefix) {
+		t.Fatalf("Unexpect reference %s", reading.Value)
+	}
+	f, info, err := s.Open(strings.TrimPrefix(reading.Value, prefix))
+	if err != nil || info.MediaType != "image/jpeg" {
+		t.Fatalf("Unexpect stored value %v: %v", info, err)
+	}
+	data, _ := ioutil.ReadAll(f)
+	f.Close()
+	if !bytes.Equal(data, []byte("abcdef")) {
+		t.Fatalf("Unexpect stored data %s", data)
+	}
+
+	cv = dsModels.NewBinaryStreamValue("Image", 0, strings.NewReader("ab"))
+	if stored, _, err := StoreReading(cv, &contract.Reading{Name: "Image"}); !stored || err != nil {
+		t.Fatalf("Stream should be stored regardless of threshold: %v", err)
+	}
+}
+
+func TestStoreReadingUnchanged(t *testing.T) {
+	common.CurrentConfig = &common.ConfigurationStruct{Service: common.ServiceInfo{Host: "localhost", Port: 49990}}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	defer func() { bs = nil }()
+	s, _ := NewStore(dir, time.Minute, 4)
+	bs = s.(*store)
+
+	value, _ := dsModels.NewBinaryValue("Image", 0, []byte("abcdef"))
+	first := &contract.Reading{Device: "Camera", Name: "Image", BinaryValue: value.BinValue}
+	_, checksum, _ := StoreReading(value, first)
+	second := &contract.Reading{Device: "Camera", Name: "Image", BinaryValue: value.BinValue}
+	if stored, c, err := StoreReading(value, second); !stored || err != nil || c != checksum || second.Value != first.Value {
+		t.Fatalf("Unchanged value should refer to the stored one, reading %v, error %v", second, err)
+	}
+
+	stream := dsModels.NewBinaryStreamValue("Image", 0, strings.NewReader("abcdef"))
+	third := &contract.Reading{Device: "Camera", Name: "Image"}
+	if stored, c, err := StoreReading(stream, third); !stored || err != nil || c != checksum || third.Value != first.Value {
+		t.Fatalf("Unchanged stream should refer to the stored value, reading %v, error %v", third, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*"+fileSuffix)); len(files) != 1 {
+		t.Fatalf("Expected a single stored value, found %v", files)
+	}
+
+	other := &contract.Reading{Device: "Camera2", Name: "Image", BinaryValue: value.BinValue}
+	if _, _, err := StoreReading(value, other); err != nil || other.Value == first.Value {
+		t.Fatalf("Value of another Device should be stored separately, reading %v, error %v", other, err)
+	}
+}
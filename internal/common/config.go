@@ -29,6 +29,9 @@ type ConfigurationStruct struct {
 	DeviceList []DeviceConfig `consul:"-"`
 	// Driver is a string map contains customized configuration for the protocol driver implemented based on Device SDK
 	Driver map[string]string
+	// MessageQueue contains the configuration of the message bus events are
+	// published to instead of being posted to Core Data.
+	MessageQueue MessageQueueInfo
 }
 
 // UpdateFromRaw converts configuration received from the registry to a service-specific configuration struct which is
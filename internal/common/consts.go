@@ -39,10 +39,26 @@ const (
 	APINameCommandRoute     = clients.ApiDeviceRoute + "/name/{name}/{command}"
 	APIDiscoveryRoute       = clients.ApiBase + "/discovery"
 	APITransformRoute       = clients.ApiBase + "/debug/transformData/{transformData}"
+	APIDryRunRoute          = clients.ApiBase + "/debug/transformData/name/{name}/{command}"
+	APIDeadbandRoute        = clients.ApiBase + "/debug/deadband"
+	APINameDeadbandRoute    = clients.ApiBase + "/debug/deadband/name/{name}"
+	APIScheduleRoute        = clients.ApiBase + "/debug/autoevent/schedule"
+	APINameScheduleRoute    = clients.ApiBase + "/debug/autoevent/schedule/name/{name}"
+	APIBlobPrefix           = clients.ApiBase + "/blob"
+	APIBlobRoute            = APIBlobPrefix + "/{id}"
+
+	APIAutoEventRoute            = clients.ApiBase + "/autoevent"
+	APIAutoEventPauseRoute       = APIAutoEventRoute + "/pause"
+	APIAutoEventResumeRoute      = APIAutoEventRoute + "/resume"
+	APINameAutoEventRoute        = APIAutoEventRoute + "/name/{name}"
+	APINameAutoEventPauseRoute   = APINameAutoEventRoute + "/pause"
+	APINameAutoEventResumeRoute  = APINameAutoEventRoute + "/resume"
+	APINameAutoEventTriggerRoute = APINameAutoEventRoute + "/trigger"
 
 	IdVar        string = "id"
 	NameVar      string = "name"
 	CommandVar   string = "command"
+	ValueVar     string = "value"
 	GetCmdMethod string = "get"
 	SetCmdMethod string = "set"
 
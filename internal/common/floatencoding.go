@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"fmt"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// ValidateFloatEncoding checks the Device.FloatEncoding setting, which is
+// either empty, Base64 or eNotation.
+func ValidateFloatEncoding(encoding string) error {
+	switch encoding {
+	case "", contract.Base64Encoding, contract.ENotation:
+		return nil
+	default:
+		return fmt.Errorf("unsupported float encoding %s, must be %s or %s", encoding, contract.Base64Encoding, contract.ENotation)
+	}
+}
+
+// FloatEncoding returns the encoding of the float values of a device resource,
+// i.e. the floatEncoding of the resource if set, otherwise the Device.FloatEncoding
+// setting or the DefaultFloatEncoding.
+func FloatEncoding(resourceEncoding string) string {
+	if encoding := configuredFloatEncoding(resourceEncoding); encoding != "" {
+		return encoding
+	}
+	return dsModels.DefaultFloatEncoding
+}
+
+// configuredFloatEncoding returns the floatEncoding of the resource or the
+// Device.FloatEncoding setting, or an empty string if neither is set. Float
+// arrays keep their plain JSON format in that case.
+func configuredFloatEncoding(resourceEncoding string) string {
+	if resourceEncoding != "" {
+		return resourceEncoding
+	}
+	if CurrentConfig != nil {
+		return CurrentConfig.Device.FloatEncoding
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestValidateFloatEncoding(t *testing.T) {
+	for _, encoding := range []string{"", contract.Base64Encoding, contract.ENotation} {
+		if err := ValidateFloatEncoding(encoding); err != nil {
+			t.Fatalf("Float encoding %s should be valid: %v", encoding, err)
+		}
+	}
+	if err := ValidateFloatEncoding("decimal"); err == nil {
+		t.Fatal("Float encoding decimal should be invalid")
+	}
+}
+
+func TestFloatEncoding(t *testing.T) {
+	CurrentConfig = &ConfigurationStruct{}
+	if encoding := FloatEncoding(""); encoding != dsModels.DefaultFloatEncoding {
+		t.Fatalf("Unexpect float encoding %s, should be the default", encoding)
+	}
+
+	CurrentConfig.Device.FloatEncoding = contract.ENotation
+	if encoding := FloatEncoding(""); encoding != contract.ENotation {
+		t.Fatalf("Unexpect float encoding %s, should be the configured one", encoding)
+	}
+	if encoding := FloatEncoding(contract.Base64Encoding); encoding != contract.Base64Encoding {
+		t.Fatalf("Unexpect float encoding %s, should be the one of the resource", encoding)
+	}
+}
+
+func TestCommandValueToReading_floatEncoding(t *testing.T) {
+	f32, _ := dsModels.NewFloat32Value("Temperature", 1, 1.5)
+	arr, _ := dsModels.NewFloat32ArrayValue("Temperatures", 1, []float32{1.5})
+
+	CurrentConfig = &ConfigurationStruct{}
+	reading := CommandValueToReading(f32, "Device", "", "")
+	if reading.Value != "P8AAAA==" || reading.FloatEncoding != contract.Base64Encoding {
+		t.Fatalf("Unexpect reading %s encoded in %s", reading.Value, reading.FloatEncoding)
+	}
+	reading = CommandValueToReading(arr, "Device", "", "")
+	if reading.Value != "[1.5]" || reading.FloatEncoding != "" {
+		t.Fatalf("Unexpect reading %s encoded in %s", reading.Value, reading.FloatEncoding)
+	}
+
+	CurrentConfig.Device.FloatEncoding = contract.ENotation
+	reading = CommandValueToReading(f32, "Device", "", "")
+	if reading.Value != "1.500000e+00" || reading.FloatEncoding != contract.ENotation {
+		t.Fatalf("Unexpect reading %s encoded in %s", reading.Value, reading.FloatEncoding)
+	}
+	reading = CommandValueToReading(arr, "Device", "", "")
+	if reading.Value != "[1.500000e+00]" || reading.FloatEncoding != contract.ENotation {
+		t.Fatalf("Unexpect reading %s encoded in %s", reading.Value, reading.FloatEncoding)
+	}
+	reading = CommandValueToReading(arr, "Device", "", contract.Base64Encoding)
+	if reading.Value != `["P8AAAA=="]` || reading.FloatEncoding != contract.Base64Encoding {
+		t.Fatalf("Unexpect reading %s encoded in %s", reading.Value, reading.FloatEncoding)
+	}
+}
@@ -26,6 +26,8 @@ var (
 	RegistryClient         registry.Client
 	Discovery              dsModels.ProtocolDiscovery
 	EventClient            coredata.EventClient
+	EventPublisher         Publisher
+	EventForwarder         Forwarder
 	AddressableClient      metadata.AddressableClient
 	DeviceClient           metadata.DeviceClient
 	DeviceServiceClient    metadata.DeviceServiceClient
@@ -0,0 +1,56 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"strings"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// LabelTagSeparator separates the key and the value of a device label used as
+// tag, e.g. "site=plant-1".
+const LabelTagSeparator = "="
+
+// DeviceTags returns the tags of the events of the Device according to the
+// Device.Tags configuration.
+func DeviceTags(device contract.Device) map[string]string {
+	if CurrentConfig == nil || !CurrentConfig.Device.Tags.FromLabels || len(device.Labels) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(device.Labels))
+	for _, label := range device.Labels {
+		kv := strings.SplitN(label, LabelTagSeparator, 2)
+		if len(kv) == 2 {
+			tags[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		} else {
+			tags[strings.TrimSpace(label)] = ""
+		}
+	}
+	return tags
+}
+
+// ReadingTags returns the tags of the reading created from the CommandValue,
+// i.e. the DeviceResource attributes configured in Device.Tags merged with
+// the tags of the CommandValue, which take precedence.
+func ReadingTags(cv *dsModels.CommandValue, dr contract.DeviceResource) map[string]string {
+	var names []string
+	if CurrentConfig != nil {
+		names = CurrentConfig.Device.Tags.FromAttributes
+	}
+	tags := make(map[string]string, len(names)+len(cv.Tags))
+	for _, name := range names {
+		if v, ok := dr.Attributes[name]; ok {
+			tags[name] = v
+		}
+	}
+	for k, v := range cv.Tags {
+		tags[k] = v
+	}
+	return tags
+}
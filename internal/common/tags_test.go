@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestDeviceTags(t *testing.T) {
+	device := contract.Device{Labels: []string{"site=plant-1", "outdoor"}}
+
+	CurrentConfig = &ConfigurationStruct{}
+	if tags := DeviceTags(device); tags != nil {
+		t.Fatalf("labels should not be used as tags unless configured, tags %v", tags)
+	}
+
+	CurrentConfig.Device.Tags.FromLabels = true
+	expected := map[string]string{"site": "plant-1", "outdoor": ""}
+	if tags := DeviceTags(device); !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("Unexpect tags %v, should be %v", tags, expected)
+	}
+}
+
+func TestReadingTags(t *testing.T) {
+	CurrentConfig = &ConfigurationStruct{Device: DeviceInfo{Tags: TagsInfo{FromAttributes: []string{"channel", "serial"}}}}
+	dr := contract.DeviceResource{Attributes: map[string]string{"channel": "1", "register": "40001"}}
+	cv, _ := dsModels.NewInt16Value("Temperature", 0, 21)
+	cv.Tags = map[string]string{"channel": "2", "sensor": "pt100"}
+
+	tags := ReadingTags(cv, dr)
+
+	expected := map[string]string{"channel": "2", "sensor": "pt100"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("Unexpect tags %v, should be %v", tags, expected)
+	}
+	cv.Tags = nil
+	if tags = ReadingTags(cv, dr); !reflect.DeepEqual(tags, map[string]string{"channel": "1"}) {
+		t.Fatalf("Unexpect tags %v from attributes", tags)
+	}
+}
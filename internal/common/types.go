@@ -9,6 +9,8 @@
 package common
 
 import (
+	"time"
+
 	bootstrapConfig "github.com/edgexfoundry/go-mod-bootstrap/config"
 	dsModels "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -84,8 +86,156 @@ type DeviceInfo struct {
 	// UpdateLastConnected specifies whether to update device's LastConnected
 	// timestamp in metadata.
 	UpdateLastConnected bool
+	// FloatEncoding is the default encoding, Base64 or eNotation, of the
+	// float values of readings and write parameters, unless overridden by
+	// the floatEncoding of the device resource. It defaults to Base64, float
+	// arrays are plain JSON arrays if neither is set.
+	FloatEncoding string
+	// BatchAutoEvents groups the AutoEvents of a Device with the same
+	// Frequency into single driver calls, limited by MaxCmdOps. The driver
+	// must support reading several resources in one HandleReadCommands call.
+	BatchAutoEvents bool
+
+	Discovery      DiscoveryInfo
+	Tags           TagsInfo
+	Blob           BlobInfo
+	AutoEventState AutoEventStateInfo
+	// AutoEventOptions holds the options of AutoEvents, e.g. aggregation,
+	// time window or OnChange tolerance, by Device name and then by the
+	// Resource of the AutoEvent.
+	AutoEventOptions map[string]map[string]AutoEventOptionsInfo
+	// StoreAndForward contains the configuration of the queue of events
+	// which failed to be delivered.
+	StoreAndForward StoreAndForwardInfo
+	// EventPipeline contains the configuration of the workers sending the
+	// events.
+	EventPipeline EventPipelineInfo
+}
 
-	Discovery DiscoveryInfo
+// EventPipelineInfo is a struct which contains configuration of the pipeline
+// sending the events of AutoEvents, commands and asynchronous readings.
+type EventPipelineInfo struct {
+	// Workers is the number of events sent concurrently. Concurrent events
+	// may arrive out of order, so 1 worker is used if StoreAndForward is
+	// enabled.
+	Workers int
+	// QueueSize is the number of events waiting for a worker.
+	QueueSize int
+	// Backpressure is the policy if the queue is full, "block" waits until
+	// an event is taken by a worker, "dropOldest" drops the event waiting
+	// longest and "dropNewest" drops the new event.
+	Backpressure string
+}
+
+// StoreAndForwardInfo is a struct which contains configuration of the local
+// queue of events which failed to be delivered, e.g. while Core Data is
+// unreachable. The queued events are forwarded in order once the delivery
+// succeeds again.
+type StoreAndForwardInfo struct {
+	// Enabled controls whether failed events are queued or dropped.
+	Enabled bool
+	// Dir is the directory of the queued events, a directory in the
+	// temporary directory of the system is used if it's empty.
+	Dir string
+	// MaxEvents is the maximum number of queued events.
+	MaxEvents int
+	// MaxSize is the maximum size in bytes of the queued events, 0 means
+	// no limit besides MaxEvents.
+	MaxSize int64
+	// MaxAge indicates how long an event is kept in the queue before it's
+	// dropped, there is no limit if it's empty. It represents as a duration
+	// string.
+	MaxAge string
+	// Eviction is the policy if the queue is full, "oldest" drops the
+	// oldest queued events, "newest" drops the new event.
+	Eviction string
+	// RetryInterval indicates how long the forwarding waits after a failed
+	// delivery, the interval doubles with every further failure up to
+	// MaxRetryInterval. Both represent as a duration string.
+	RetryInterval    string
+	MaxRetryInterval string
+}
+
+// AutoEventStateInfo is a struct which contains configuration of the local
+// file keeping the last published readings of OnChange AutoEvents across
+// restarts, so that unchanged readings are not published again.
+type AutoEventStateInfo struct {
+	// File is the path of the state file, the state is not kept if it's
+	// empty.
+	File string
+	// Interval indicates how often the state is written besides on
+	// shutdown. It represents as a duration string.
+	Interval string
+	// MaxAge indicates how old the state may be when it's restored, older
+	// state is discarded. It represents as a duration string.
+	MaxAge string
+}
+
+// AutoEventOptionsInfo contains the options of an AutoEvent by option name,
+// option names are case-insensitive.
+type AutoEventOptionsInfo map[string]string
+
+// MessageQueueInfo is a struct which contains configuration of the message
+// bus events are published to.
+type MessageQueueInfo struct {
+	// Type is the kind of the message bus, "mqtt" or a type registered
+	// with publisher.RegisterClient, or "rest" to post the events to Core
+	// Data, which is the default if it's empty.
+	Type string
+	// Protocol is "tcp", or "tls" for a TLS connection to the broker.
+	Protocol string
+	// CaFile is the PEM file of the certificate authorities verifying the
+	// broker of a TLS connection, the system roots are used if it's empty.
+	CaFile string
+	// CertFile and KeyFile are the PEM files of the client certificate and
+	// its key presented to the broker of a TLS connection if they are set.
+	CertFile string
+	KeyFile  string
+	// Host is the hostname or IP address of the broker.
+	Host string
+	// Port is the port of the broker.
+	Port int
+	// Topic is the template of the topic of an event. The placeholders
+	// {service}, {profile}, {device} and {command} are replaced by the names
+	// of the Device Service, the Device Profile, the Device and the command
+	// or DeviceResource read.
+	Topic string
+	// ClientId identifies the service to the broker, the service name is
+	// used if it's empty.
+	ClientId string
+	// Username and Password authenticate the service to the broker if the
+	// Username is set.
+	Username string
+	Password string
+	// Qos is the MQTT quality of service, 0 or 1, of the published events.
+	Qos int
+	// Retained asks the broker to retain the last event of every topic.
+	Retained bool
+	// KeepAlive indicates how often the connection is checked while no
+	// events are published, it's 0 or at least 2s. It represents as a
+	// duration string.
+	KeepAlive string
+	// ConnectTimeout indicates how long connecting to the broker and
+	// waiting for its acknowledgements may take. It represents as a
+	// duration string.
+	ConnectTimeout string
+	// RestFallback posts an event to Core Data if publishing it fails.
+	RestFallback bool
+}
+
+// BlobInfo is a struct which contains configuration of the local store of
+// large binary readings, which are served by the blob REST endpoint while
+// the events only carry their URL.
+type BlobInfo struct {
+	// Dir is the directory of the stored binary values, a directory in the
+	// temporary directory of the system is used if it's empty.
+	Dir string
+	// MaxAge indicates how long a stored binary value is kept before it's
+	// removed. It represents as a duration string.
+	MaxAge string
+	// Threshold is the size in bytes above which Binary readings are
+	// stored as well, 0 stores only the values of binary streams.
+	Threshold int
 }
 
 // DiscoveryInfo is a struct which contains configuration of device auto discovery.
@@ -97,6 +247,18 @@ type DiscoveryInfo struct {
 	Interval string
 }
 
+// TagsInfo is a struct which contains configuration of the tags merged into
+// events and readings besides the tags set by the ProtocolDriver.
+type TagsInfo struct {
+	// FromLabels adds the labels of the Device as tags of its events. A label
+	// "key=value" becomes the tag key with the value, other labels become a
+	// tag with an empty value.
+	FromLabels bool
+	// FromAttributes lists the DeviceResource attributes added as tags of
+	// the readings of the DeviceResource.
+	FromAttributes []string
+}
+
 // DeviceConfig is the definition of Devices which will be auto created when the Device Service starts up
 type DeviceConfig struct {
 	// Name is the Device name
@@ -135,4 +297,72 @@ type Telemetry struct {
 	Mallocs,
 	Frees,
 	LiveObjects uint64
+	AutoEvents []AutoEventMetrics
+	// EventQueue is nil unless store-and-forward is enabled.
+	EventQueue *EventQueueMetrics
+	// EventPipeline is nil until the pipeline is started.
+	EventPipeline *EventPipelineMetrics
+}
+
+// EventPipelineMetrics provides the statistics of the pipeline sending the
+// events.
+type EventPipelineMetrics struct {
+	// Queued is the number of events waiting for a worker.
+	Queued int
+	// Sent is the number of events sent, or queued for forwarding.
+	Sent uint64
+	// Failed is the number of events which failed to be sent.
+	Failed uint64
+	// Dropped is the number of events dropped because the queue was full.
+	Dropped uint64
+	// Latency is the mean time in nanoseconds from queuing an event until
+	// it's sent.
+	Latency time.Duration
+	// MaxLatency is the longest time in nanoseconds from queuing an event
+	// until it's sent.
+	MaxLatency time.Duration
+}
+
+// EventQueueMetrics provides the statistics of the store-and-forward queue of
+// events which failed to be delivered.
+type EventQueueMetrics struct {
+	// Depth is the number of queued events.
+	Depth int
+	// Size is the size in bytes of the queued events.
+	Size int64
+	// OldestAge is the time in nanoseconds the oldest event has been queued.
+	OldestAge time.Duration
+	// Queued is the number of events queued since the start.
+	Queued uint64
+	// Forwarded is the number of queued events delivered since the start.
+	Forwarded uint64
+	// Evicted is the number of events dropped because the queue was full.
+	Evicted uint64
+	// Expired is the number of events dropped because they exceeded the
+	// max age.
+	Expired uint64
+}
+
+// AutoEventMetrics provides the run statistics of an AutoEvent executor.
+type AutoEventMetrics struct {
+	Device    string
+	Resource  string
+	Frequency string
+	// Runs is the number of completed runs.
+	Runs uint64
+	// Overruns is the number of runs which ended after the next tick.
+	Overruns uint64
+	// MissedTicks is the number of ticks skipped because of overruns.
+	MissedTicks uint64
+	// OverrunTime is the total time in nanoseconds the runs exceeded the
+	// next tick.
+	OverrunTime time.Duration
+	// LastRun is the start time of the last run.
+	LastRun time.Time
+	// LastDuration is the time in nanoseconds the last run took.
+	LastDuration time.Duration
+	// Period is the current interval in nanoseconds between runs, it is
+	// adapted to the readings by adaptive AutoEvents and 0 for cron
+	// schedules.
+	Period time.Duration
 }
@@ -10,9 +10,14 @@ package common
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +32,7 @@ import (
 var (
 	previousOrigin int64
 	originMutex    sync.Mutex
+	readingPool    = sync.Pool{New: func() interface{} { return new(contract.Reading) }}
 )
 
 func BuildAddr(host string, port string) string {
@@ -40,20 +46,24 @@ func BuildAddr(host string, port string) string {
 	return buffer.String()
 }
 
+// CommandValueToReading creates the reading of the CommandValue. The reading
+// is taken from a pool, callers which only copy it, e.g. into the readings of
+// an event, should hand it back with ReleaseReading.
 func CommandValueToReading(cv *dsModels.CommandValue, devName string, mediaType string, encoding string) *contract.Reading {
-	if encoding == "" {
-		encoding = dsModels.DefaultFloatEncoding
-	}
-
-	reading := &contract.Reading{Name: cv.DeviceResourceName, Device: devName, ValueType: cv.ValueTypeToString()}
+	reading := readingPool.Get().(*contract.Reading)
+	*reading = contract.Reading{Name: cv.DeviceResourceName, Device: devName, ValueType: cv.ValueTypeToString()}
 	if cv.Type == dsModels.Binary {
 		reading.BinaryValue = cv.BinValue
 		reading.MediaType = mediaType
 	} else if cv.Type == dsModels.Float32 || cv.Type == dsModels.Float64 {
+		encoding = FloatEncoding(encoding)
 		reading.Value = cv.ValueToString(encoding)
 		reading.FloatEncoding = encoding
-	} else {
+	} else if encoding = configuredFloatEncoding(encoding); encoding != "" && (cv.Type == dsModels.Float32Array || cv.Type == dsModels.Float64Array) {
 		reading.Value = cv.ValueToString(encoding)
+		reading.FloatEncoding = encoding
+	} else {
+		reading.Value = cv.ValueToString()
 	}
 
 	// if value has a non-zero Origin, use it
@@ -66,7 +76,38 @@ func CommandValueToReading(cv *dsModels.CommandValue, devName string, mediaType
 	return reading
 }
 
-func SendEvent(event *dsModels.Event) {
+// ReleaseReading returns a reading created by CommandValueToReading to the
+// pool, it must not be used afterwards.
+func ReleaseReading(reading *contract.Reading) {
+	*reading = contract.Reading{}
+	readingPool.Put(reading)
+}
+
+// Publisher publishes events to a message bus instead of posting them to Core
+// Data. The correlation ID and content type are carried by the context like
+// for the EventClient.
+type Publisher interface {
+	Publish(ctx context.Context, event *dsModels.Event) error
+}
+
+// Forwarder queues the events which failed to be delivered and forwards them
+// in order once the delivery succeeds again.
+type Forwarder interface {
+	// Store queues the encoded event, the correlation ID and content type
+	// are carried by the context.
+	Store(ctx context.Context, event *dsModels.Event) error
+	// Pending reports whether events are queued, new events are queued
+	// behind them to keep the order.
+	Pending() bool
+	// Metrics returns the statistics of the queue.
+	Metrics() EventQueueMetrics
+}
+
+// SendEvent delivers the event with DeliverEvent. If the delivery fails, or
+// events which failed before are still queued, the event is queued by the
+// EventForwarder if there is one and is lost otherwise. It returns an error
+// if the event is lost.
+func SendEvent(event *dsModels.Event) error {
 	correlation := uuid.New().String()
 	ctx := context.WithValue(context.Background(), CorrelationHeader, correlation)
 	if event.HasBinaryValue() {
@@ -77,7 +118,7 @@ func SendEvent(event *dsModels.Event) {
 	// Call MarshalEvent to encode as byte array whether event contains binary or JSON readings
 	var err error
 	if len(event.EncodedEvent) <= 0 {
-		event.EncodedEvent, err = EventClient.MarshalEvent(event.Event)
+		event.EncodedEvent, err = MarshalEvent(event)
 		if err != nil {
 			LoggingClient.Error("SendEvent: Error encoding event", "device", event.Device, clients.CorrelationHeader, correlation, "error", err)
 		} else {
@@ -86,14 +127,68 @@ func SendEvent(event *dsModels.Event) {
 	} else {
 		LoggingClient.Debug("SendEvent: EventClient.MarshalEvent passed through encoded event", clients.CorrelationHeader, correlation)
 	}
+	forwarder := EventForwarder
+	if forwarder != nil && forwarder.Pending() {
+		return storeEvent(ctx, forwarder, event)
+	}
+	err = DeliverEvent(ctx, event)
+	if err != nil && forwarder != nil {
+		return storeEvent(ctx, forwarder, event)
+	}
+	return err
+}
+
+// DeliverEvent publishes the encoded event with the EventPublisher, or posts
+// it to Core Data if there is none or publishing fails and
+// MessageQueue.RestFallback is set. The correlation ID and content type are
+// carried by the context.
+func DeliverEvent(ctx context.Context, event *dsModels.Event) error {
+	correlation := clients.FromContext(ctx, clients.CorrelationHeader)
+	if EventPublisher != nil {
+		errPub := EventPublisher.Publish(ctx, event)
+		if errPub == nil {
+			LoggingClient.Info("SendEvent: Published event", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation)
+			LoggingClient.Trace("SendEvent: Published this event", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation, "event", event)
+			return nil
+		}
+		LoggingClient.Error("SendEvent Failed to publish event", "device", event.Device, clients.CorrelationHeader, correlation, "error", errPub)
+		if !CurrentConfig.MessageQueue.RestFallback {
+			return errPub
+		}
+	}
 	// Call AddBytes to post event to core data
 	responseBody, errPost := EventClient.AddBytes(ctx, event.EncodedEvent)
 	if errPost != nil {
 		LoggingClient.Error("SendEvent Failed to push event", "device", event.Device, "response", responseBody, "error", errPost)
-	} else {
-		LoggingClient.Info("SendEvent: Pushed event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation)
-		LoggingClient.Trace("SendEvent: Pushed this event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation, "event", event)
+		return errPost
+	}
+	LoggingClient.Info("SendEvent: Pushed event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation)
+	LoggingClient.Trace("SendEvent: Pushed this event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation, "event", event)
+	return nil
+}
+
+func storeEvent(ctx context.Context, forwarder Forwarder, event *dsModels.Event) error {
+	correlation := clients.FromContext(ctx, clients.CorrelationHeader)
+	if err := forwarder.Store(ctx, event); err != nil {
+		LoggingClient.Error("SendEvent Failed to queue event", "device", event.Device, clients.CorrelationHeader, correlation, "error", err)
+		return err
+	}
+	LoggingClient.Debug("SendEvent: Queued event for forwarding", "device", event.Device, clients.CorrelationHeader, correlation)
+	return nil
+}
+
+// MarshalEvent encodes the event with the EventClient. Events with metadata
+// the EventClient would drop, e.g. the flags and quality of the readings, are
+// encoded along with it, in CBOR if they have binary values like the
+// EventClient does and in JSON otherwise.
+func MarshalEvent(event *dsModels.Event) ([]byte, error) {
+	if !event.HasMetadata() {
+		return EventClient.MarshalEvent(event.Event)
 	}
+	if event.HasBinaryValue() {
+		return event.MarshalCBOR()
+	}
+	return json.Marshal(event)
 }
 
 func CompareCoreCommands(a []contract.Command, b []contract.Command) bool {
@@ -300,3 +395,34 @@ func UpdateLastConnected(name string) {
 		LoggingClient.Error("Failed to update last connected value for device: " + name)
 	}
 }
+
+// ReadingValueToFloat64 parses the value of a numeric Reading, float values are
+// decoded according to the FloatEncoding of the Reading.
+func ReadingValueToFloat64(r contract.Reading) (float64, error) {
+	switch dsModels.ParseValueType(r.ValueType) {
+	case dsModels.Uint8, dsModels.Uint16, dsModels.Uint32, dsModels.Uint64:
+		v, err := strconv.ParseUint(r.Value, 10, 64)
+		return float64(v), err
+	case dsModels.Int8, dsModels.Int16, dsModels.Int32, dsModels.Int64:
+		v, err := strconv.ParseInt(r.Value, 10, 64)
+		return float64(v), err
+	case dsModels.Float32, dsModels.Float64:
+		if r.FloatEncoding != contract.Base64Encoding {
+			return strconv.ParseFloat(r.Value, 64)
+		}
+		data, err := base64.StdEncoding.DecodeString(r.Value)
+		if err != nil {
+			return 0, err
+		}
+		switch len(data) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+		default:
+			return 0, fmt.Errorf("invalid length %d of base64 encoded float value", len(data))
+		}
+	default:
+		return 0, fmt.Errorf("reading of value type %s is not numeric", r.ValueType)
+	}
+}
@@ -8,8 +8,13 @@
 package common
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/fxamacker/cbor/v2"
 )
 
 func TestBuildAddr(t *testing.T) {
@@ -20,6 +25,48 @@ func TestBuildAddr(t *testing.T) {
 	}
 }
 
+func TestReleaseReading(t *testing.T) {
+	binary, _ := dsModels.NewBinaryValue("image", 1, []byte{0x01})
+	reading := CommandValueToReading(binary, "Device", "image/png", "")
+	reading.Id = "id"
+	ReleaseReading(reading)
+	if reading.Id != "" || reading.BinaryValue != nil || reading.MediaType != "" {
+		t.Fatalf("Unexpect released reading %v, should be reset", reading)
+	}
+
+	cv, _ := dsModels.NewInt32Value("temperature", 2, 42)
+	reading = CommandValueToReading(cv, "Device", "", "")
+	if reading.Id != "" || reading.BinaryValue != nil || reading.MediaType != "" || reading.Value != "42" || reading.Origin != 2 {
+		t.Fatalf("Unexpect reading %v taken from the pool", reading)
+	}
+}
+
+func TestMarshalEvent_metadata(t *testing.T) {
+	var decoded struct {
+		Readings []struct {
+			Quality *dsModels.Quality `json:"quality"`
+			Flags   []string          `json:"flags"`
+		} `json:"readings"`
+	}
+
+	event := &dsModels.Event{Event: contract.Event{Device: "Device", Readings: []contract.Reading{
+		{Name: "Image", BinaryValue: []byte{0x01}, ValueType: contract.ValueTypeBinary},
+	}}}
+	event.AddReadingQuality(0, dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusStale))
+	data, err := MarshalEvent(event)
+	if err != nil || cbor.Unmarshal(data, &decoded) != nil || decoded.Readings[0].Quality == nil {
+		t.Fatalf("Unexpect CBOR encoding %x of the binary event, error: %v", data, err)
+	}
+
+	decoded.Readings = nil
+	event = &dsModels.Event{Event: contract.Event{Device: "Device", Readings: []contract.Reading{{Name: "Temperature", Value: "300"}}}}
+	event.AddReadingFlags(0, []string{dsModels.FlagClamped})
+	data, err = MarshalEvent(event)
+	if err != nil || json.Unmarshal(data, &decoded) != nil || len(decoded.Readings[0].Flags) != 1 {
+		t.Fatalf("Unexpect JSON encoding %s of the event, error: %v", data, err)
+	}
+}
+
 // TODO:
 //   TestCompareCommands
 //   TestCompareDevices
@@ -14,10 +14,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"runtime"
+	"strconv"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler/callback"
+	"github.com/edgexfoundry/device-sdk-go/internal/pipeline"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
@@ -28,6 +31,11 @@ const (
 	statusNotImplemented string = "Discovery not implemented"
 	statusUnavailable    string = "Discovery disabled by configuration"
 	statusLocked         string = "OperatingState disabled"
+	statusNoAutoEvents   string = "AutoEvents not started"
+
+	frequencyParam string = "frequency"
+	countParam     string = "count"
+	resourceParam  string = "resource"
 )
 
 type ConfigRespMap struct {
@@ -68,13 +76,13 @@ func discoveryFunc(w http.ResponseWriter, req *http.Request) {
 	handler.DiscoveryHandler(w)
 }
 
-func transformFunc(w http.ResponseWriter, req *http.Request) {
+func transformDataFunc(w http.ResponseWriter, req *http.Request) {
 	if checkServiceLocked(w, req) {
 		return
 	}
 
 	vars := mux.Vars(req)
-	_, appErr := handler.TransformHandler(vars)
+	_, appErr := handler.TransformDataHandler(vars)
 	if appErr != nil {
 		w.WriteHeader(appErr.Code())
 	} else {
@@ -82,6 +90,154 @@ func transformFunc(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func transformFunc(w http.ResponseWriter, req *http.Request) {
+	if checkServiceLocked(w, req) {
+		return
+	}
+
+	vars := mux.Vars(req)
+
+	body, ok := readBodyAsString(w, req)
+	if !ok {
+		return
+	}
+
+	result, appErr := handler.TransformHandler(vars, body, req.Method, req.URL.RawQuery)
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		encode(result, w)
+	}
+}
+
+func deadbandFunc(w http.ResponseWriter, req *http.Request) {
+	states, appErr := handler.DeadbandHandler(mux.Vars(req))
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		encode(states, w)
+	}
+}
+
+func scheduleFunc(w http.ResponseWriter, req *http.Request) {
+	count := autoevent.DefaultPreviewCount
+	if c := req.URL.Query().Get(countParam); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 || n > autoevent.MaxPreviewCount {
+			http.Error(w, fmt.Sprintf("invalid %s %s, must be between 1 and %d", countParam, c, autoevent.MaxPreviewCount), http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+
+	var preview interface{}
+	var appErr common.AppError
+	if name, ok := mux.Vars(req)[common.NameVar]; ok {
+		preview, appErr = autoevent.DeviceSchedulePreview(name, count)
+	} else {
+		// the query parameters besides frequency and count are the options
+		query := req.URL.Query()
+		options := make(map[string]string)
+		for name := range query {
+			if name != frequencyParam && name != countParam {
+				options[name] = query.Get(name)
+			}
+		}
+		preview, appErr = autoevent.SchedulePreview(query.Get(frequencyParam), options, count)
+	}
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		encode(preview, w)
+	}
+}
+
+func autoEventFunc(w http.ResponseWriter, req *http.Request) {
+	m := autoevent.GetManager()
+	if m == nil {
+		http.Error(w, statusNoAutoEvents, http.StatusServiceUnavailable)
+		return
+	}
+	statuses, appErr := m.Status(mux.Vars(req)[common.NameVar])
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		encode(statuses, w)
+	}
+}
+
+func autoEventPauseFunc(w http.ResponseWriter, req *http.Request) {
+	setAutoEventsPaused(w, req, true)
+}
+
+func autoEventResumeFunc(w http.ResponseWriter, req *http.Request) {
+	setAutoEventsPaused(w, req, false)
+}
+
+// setAutoEventsPaused pauses or resumes the AutoEvents of the Device given by
+// name, or of all Devices if the route has no name.
+func setAutoEventsPaused(w http.ResponseWriter, req *http.Request, paused bool) {
+	m := autoevent.GetManager()
+	if m == nil {
+		http.Error(w, statusNoAutoEvents, http.StatusServiceUnavailable)
+		return
+	}
+	name := mux.Vars(req)[common.NameVar]
+	var appErr common.AppError
+	if paused {
+		appErr = m.Pause(name)
+	} else {
+		appErr = m.Resume(name)
+	}
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		io.WriteString(w, statusOK)
+	}
+}
+
+func autoEventTriggerFunc(w http.ResponseWriter, req *http.Request) {
+	m := autoevent.GetManager()
+	if m == nil {
+		http.Error(w, statusNoAutoEvents, http.StatusServiceUnavailable)
+		return
+	}
+	count, appErr := m.Trigger(mux.Vars(req)[common.NameVar], req.URL.Query().Get(resourceParam))
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else {
+		encode(map[string]int{"triggered": count}, w)
+	}
+}
+
+func blobFunc(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	if req.Method == http.MethodDelete {
+		if appErr := handler.RemoveBlobHandler(vars); appErr != nil {
+			http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+		} else {
+			io.WriteString(w, statusOK)
+		}
+		return
+	}
+
+	f, info, appErr := handler.BlobHandler(vars)
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+		return
+	}
+	defer f.Close()
+
+	if info.MediaType != "" {
+		w.Header().Set(clients.ContentType, info.MediaType)
+	} else {
+		w.Header().Set(clients.ContentType, "application/octet-stream")
+	}
+	// ServeContent streams the file and supports range requests, so large
+	// values can be fetched in chunks.
+	http.ServeContent(w, req, "", info.Created, f)
+}
+
 func callbackFunc(w http.ResponseWriter, req *http.Request) {
 	if checkServiceLocked(w, req) {
 		return
@@ -127,7 +283,7 @@ func commandFunc(w http.ResponseWriter, req *http.Request) {
 			// Encode response as application/CBOR.
 			if len(event.EncodedEvent) <= 0 {
 				var err error
-				event.EncodedEvent, err = common.EventClient.MarshalEvent(event.Event)
+				event.EncodedEvent, err = common.MarshalEvent(event)
 				if err != nil {
 					common.LoggingClient.Error("DeviceCommand: Error encoding event", "device", event.Device, "error", err)
 				} else {
@@ -144,7 +300,8 @@ func commandFunc(w http.ResponseWriter, req *http.Request) {
 			json.NewEncoder(w).Encode(event)
 		}
 		// push to Core Data
-		go common.SendEvent(event)
+		pipeline.Send(event)
+		autoevent.EvaluateTriggers(event)
 	}
 }
 
@@ -168,7 +325,8 @@ func commandAllFunc(w http.ResponseWriter, req *http.Request) {
 		// push to Core Data
 		for _, event := range events {
 			if event != nil {
-				go common.SendEvent(event)
+				pipeline.Send(event)
+				autoevent.EvaluateTriggers(event)
 			}
 		}
 		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
@@ -225,6 +383,17 @@ func metricsHandler(w http.ResponseWriter, _ *http.Request) {
 	// Live objects = Mallocs - Frees
 	t.LiveObjects = t.Mallocs - t.Frees
 
+	if m := autoevent.GetManager(); m != nil {
+		t.AutoEvents = m.Metrics()
+	}
+	if f := common.EventForwarder; f != nil {
+		m := f.Metrics()
+		t.EventQueue = &m
+	}
+	if m, ok := pipeline.Metrics(); ok {
+		t.EventPipeline = &m
+	}
+
 	encode(t, w)
 
 	return
@@ -9,12 +9,21 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	"github.com/edgexfoundry/device-sdk-go/internal/blob"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/mock"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
@@ -126,3 +135,137 @@ func TestCommandNoDevice(t *testing.T) {
 		t.Errorf("No Device: handler returned wrong body:\nexpected: %s\ngot:      %s", expected, body)
 	}
 }
+
+// TestTransformData tests that the legacy transformData route is kept beside
+// the dry-run route.
+func TestTransformData(t *testing.T) {
+	common.LoggingClient = logger.NewClient("command_test", false, "./command_test.log", "DEBUG")
+	common.ServiceLocked = false
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+
+	rr := httptest.NewRecorder()
+	controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, clients.ApiBase+"/debug/transformData/data", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != statusOK {
+		t.Errorf("TransformData: handler returned status code %v and body %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, clients.ApiBase+"/debug/transformData/name/"+badDeviceId+"/"+testCmd+"?value=1", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("DryRun: handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+// TestBlob tests fetching a stored binary value in chunks and removing it.
+func TestBlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blob-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+
+	common.LoggingClient = logger.NewClient("blob_test", false, "./command_test.log", "DEBUG")
+	common.CurrentConfig = &common.ConfigurationStruct{Device: common.DeviceInfo{Blob: common.BlobInfo{Dir: dir}}}
+	if err := blob.Init(ctx, wg); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := blob.Blobs().Put(strings.NewReader("0123456789"), "image/jpeg")
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+	route := common.APIBlobPrefix + "/" + info.ID
+
+	req := httptest.NewRequest(http.MethodGet, route, nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rr := httptest.NewRecorder()
+	controller.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusPartialContent || rr.Body.String() != "2345" || rr.Header().Get(clients.ContentType) != "image/jpeg" {
+		t.Errorf("Blob: unexpected response %v %s %v", rr.Code, rr.Body.String(), rr.Header())
+	}
+
+	rr = httptest.NewRecorder()
+	controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, route, nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Blob: DELETE returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	rr = httptest.NewRecorder()
+	controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, route, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Blob: GET of removed value returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+// TestSchedule tests the next-run preview of an AutoEvent frequency.
+func TestSchedule(t *testing.T) {
+	common.LoggingClient = logger.NewClient("schedule_test", false, "./command_test.log", "DEBUG")
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{"cron", "frequency=" + url.QueryEscape("cron:*/15 * * * *") + "&count=2", http.StatusOK},
+		{"missing frequency", "", http.StatusBadRequest},
+		{"invalid frequency", "frequency=often", http.StatusBadRequest},
+		{"invalid count", "frequency=1s&count=0", http.StatusBadRequest},
+		{"window", "frequency=15m&count=2&tz=UTC&from=00:00&to=23:59", http.StatusOK},
+		{"invalid option", "frequency=1s&tz=Mars/Olympus", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, common.APIScheduleRoute+"?"+tt.query, nil))
+			if rr.Code != tt.code {
+				t.Fatalf("Schedule: handler returned wrong status code: got %v want %v", rr.Code, tt.code)
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			var info autoevent.ScheduleInfo
+			if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil || len(info.NextRuns) != 2 {
+				t.Fatalf("Schedule: unexpected preview %s, error %v", rr.Body.String(), err)
+			}
+			if info.NextRuns[0].Minute()%15 != 0 || info.NextRuns[1].Sub(info.NextRuns[0]) != 15*time.Minute {
+				t.Fatalf("Schedule: unexpected runs %v", info.NextRuns)
+			}
+		})
+	}
+}
+
+func TestAutoEventNotStarted(t *testing.T) {
+	common.LoggingClient = logger.NewClient("autoevent_test", false, "./command_test.log", "DEBUG")
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+
+	tests := []struct {
+		method string
+		route  string
+	}{
+		{http.MethodGet, common.APIAutoEventRoute},
+		{http.MethodGet, common.APIAutoEventRoute + "/name/device"},
+		{http.MethodPut, common.APIAutoEventPauseRoute},
+		{http.MethodPut, common.APIAutoEventRoute + "/name/device/resume"},
+		{http.MethodPost, common.APIAutoEventRoute + "/name/device/trigger"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		controller.router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.route, nil))
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("AutoEvent: %s %s returned wrong status code: got %v want %v", tt.method, tt.route, rr.Code, http.StatusServiceUnavailable)
+		}
+	}
+}
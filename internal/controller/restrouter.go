@@ -54,7 +54,21 @@ func (c RestController) InitRestRoutes() {
 	c.addReservedRoute(common.APICallbackRoute, callbackFunc)
 	// Discovery and Transform
 	c.addReservedRoute(common.APIDiscoveryRoute, discoveryFunc).Methods(http.MethodPost)
-	c.addReservedRoute(common.APITransformRoute, transformFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APITransformRoute, transformDataFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIDryRunRoute, transformFunc).Methods(http.MethodGet, http.MethodPut)
+	c.addReservedRoute(common.APIDeadbandRoute, deadbandFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APINameDeadbandRoute, deadbandFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIScheduleRoute, scheduleFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APINameScheduleRoute, scheduleFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIAutoEventRoute, autoEventFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APINameAutoEventRoute, autoEventFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIAutoEventPauseRoute, autoEventPauseFunc).Methods(http.MethodPut)
+	c.addReservedRoute(common.APINameAutoEventPauseRoute, autoEventPauseFunc).Methods(http.MethodPut)
+	c.addReservedRoute(common.APIAutoEventResumeRoute, autoEventResumeFunc).Methods(http.MethodPut)
+	c.addReservedRoute(common.APINameAutoEventResumeRoute, autoEventResumeFunc).Methods(http.MethodPut)
+	c.addReservedRoute(common.APINameAutoEventTriggerRoute, autoEventTriggerFunc).Methods(http.MethodPost)
+	// Blob
+	c.addReservedRoute(common.APIBlobRoute, blobFunc).Methods(http.MethodGet, http.MethodDelete)
 	// Metric and Config
 	c.addReservedRoute(common.APIMetricsRoute, metricsHandler).Methods(http.MethodGet)
 	c.addReservedRoute(common.APIConfigRoute, configHandler).Methods(http.MethodGet)
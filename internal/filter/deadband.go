@@ -0,0 +1,205 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package filter suppresses readings before they are sent to Core Data.
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// DeviceResource attributes configuring the deadband filter. Both accept an
+// absolute value, e.g. "0.5", or a percentage of the last published value,
+// e.g. "2%".
+const (
+	// DeadbandAttribute is the band around the last published value in which
+	// new values are suppressed.
+	DeadbandAttribute = "deadband"
+	// HysteresisAttribute widens the deadband when the value changes direction
+	// compared to the last published change.
+	HysteresisAttribute = "hysteresis"
+)
+
+var (
+	db     *deadbandFilter
+	dbOnce sync.Once
+)
+
+// DeadbandState is the filter state of a single DeviceResource of a Device.
+type DeadbandState struct {
+	Device         string  `json:"device"`
+	DeviceResource string  `json:"deviceResource"`
+	Deadband       string  `json:"deadband,omitempty"`
+	Hysteresis     string  `json:"hysteresis,omitempty"`
+	LastValue      float64 `json:"lastValue"`
+	LastOrigin     int64   `json:"lastOrigin"`
+	Direction      int     `json:"direction"`
+	Published      uint64  `json:"published"`
+	Suppressed     uint64  `json:"suppressed"`
+}
+
+type DeadbandFilter interface {
+	// Filter removes the readings of the event whose value is within the
+	// deadband of the last published value of the same DeviceResource and
+	// their metadata, it returns false if no reading is left.
+	Filter(event *dsModels.Event, profileName string) bool
+	ForDevice(deviceName string) []DeadbandState
+	All() []DeadbandState
+	RemoveDevice(deviceName string)
+}
+
+type deadbandFilter struct {
+	states map[string]map[string]*DeadbandState // key is Device name, then DeviceResource name
+	mutex  sync.Mutex
+}
+
+// Deadbands returns the deadband filter shared by the AutoEvent and async
+// reading paths.
+func Deadbands() DeadbandFilter {
+	dbOnce.Do(func() {
+		db = &deadbandFilter{states: make(map[string]map[string]*DeadbandState)}
+	})
+	return db
+}
+
+func (f *deadbandFilter) Filter(event *dsModels.Event, profileName string) bool {
+	left := event.KeepReadings(func(r contract.Reading) bool {
+		dr, ok := cache.Profiles().DeviceResource(profileName, r.Name)
+		if ok && !f.pass(event.Device, r, dr.Attributes) {
+			common.LoggingClient.Debug(fmt.Sprintf("Deadband - suppressed reading %s of Device %s with value %s", r.Name, event.Device, r.Value))
+			return false
+		}
+		return true
+	})
+	return left > 0
+}
+
+// pass updates the state of the DeviceResource and reports whether the
+// reading should be published.
+func (f *deadbandFilter) pass(deviceName string, r contract.Reading, attributes map[string]string) bool {
+	deadband, hysteresis := attributes[DeadbandAttribute], attributes[HysteresisAttribute]
+	if deadband == "" && hysteresis == "" {
+		return true
+	}
+	value, err := common.ReadingValueToFloat64(r)
+	if err != nil {
+		common.LoggingClient.Debug(fmt.Sprintf("Deadband - reading %s of Device %s is not filtered: %v", r.Name, deviceName, err))
+		return true
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	resources, ok := f.states[deviceName]
+	if !ok {
+		resources = make(map[string]*DeadbandState)
+		f.states[deviceName] = resources
+	}
+	state, ok := resources[r.Name]
+	if !ok {
+		state = &DeadbandState{Device: deviceName, DeviceResource: r.Name}
+		resources[r.Name] = state
+	}
+	state.Deadband, state.Hysteresis = deadband, hysteresis
+
+	if state.Published > 0 {
+		delta := value - state.LastValue
+		direction := sign(delta)
+		band := bandWidth(deadband, state.LastValue)
+		if state.Direction != 0 && direction != 0 && direction != state.Direction {
+			band += bandWidth(hysteresis, state.LastValue)
+		}
+		if math.Abs(delta) <= band {
+			state.Suppressed++
+			return false
+		}
+		state.Direction = direction
+	}
+
+	state.LastValue = value
+	state.LastOrigin = r.Origin
+	state.Published++
+	return true
+}
+
+func (f *deadbandFilter) ForDevice(deviceName string) []DeadbandState {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return sortedStates(f.states[deviceName])
+}
+
+func (f *deadbandFilter) All() []DeadbandState {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	states := make([]DeadbandState, 0)
+	names := make([]string, 0, len(f.states))
+	for name := range f.states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		states = append(states, sortedStates(f.states[name])...)
+	}
+	return states
+}
+
+func (f *deadbandFilter) RemoveDevice(deviceName string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.states, deviceName)
+}
+
+func sortedStates(resources map[string]*DeadbandState) []DeadbandState {
+	states := make([]DeadbandState, 0, len(resources))
+	for _, s := range resources {
+		states = append(states, *s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].DeviceResource < states[j].DeviceResource })
+	return states
+}
+
+// bandWidth parses an absolute or percent band setting, a percentage is
+// relative to the given reference value. Invalid settings are treated as 0.
+func bandWidth(setting string, reference float64) float64 {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return 0
+	}
+	percent := strings.HasSuffix(setting, "%")
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(setting, "%")), 64)
+	if err != nil || v < 0 {
+		common.LoggingClient.Warn(fmt.Sprintf("Deadband - invalid band setting %s is ignored", setting))
+		return 0
+	}
+	if percent {
+		return math.Abs(reference) * v / 100
+	}
+	return v
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
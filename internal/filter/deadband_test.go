@@ -0,0 +1,128 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func init() {
+	common.LoggingClient = logger.MockLogger{}
+}
+
+func newTestFilter() *deadbandFilter {
+	return &deadbandFilter{states: make(map[string]map[string]*DeadbandState)}
+}
+
+func int32Reading(v int32) contract.Reading {
+	cv, _ := dsModels.NewInt32Value("temperature", 0, v)
+	return *common.CommandValueToReading(cv, "device", "", "")
+}
+
+func TestDeadband_absolute(t *testing.T) {
+	f := newTestFilter()
+	attributes := map[string]string{DeadbandAttribute: "2"}
+	tests := []struct {
+		value    int32
+		expected bool
+	}{
+		{20, true},
+		{21, false},
+		{22, false},
+		{23, true},
+		{21, false},
+		{20, true},
+	}
+	for _, tt := range tests {
+		if result := f.pass("device", int32Reading(tt.value), attributes); result != tt.expected {
+			t.Fatalf("Unexpect test result for value %d, published '%v' should be '%v'", tt.value, result, tt.expected)
+		}
+	}
+	state := f.ForDevice("device")[0]
+	if state.Published != 3 || state.Suppressed != 3 || state.LastValue != 20 {
+		t.Fatalf("Unexpect deadband state %+v", state)
+	}
+}
+
+func TestDeadband_percent(t *testing.T) {
+	f := newTestFilter()
+	attributes := map[string]string{DeadbandAttribute: "10%"}
+	cv, _ := dsModels.NewFloat64Value("temperature", 0, 50)
+	first := *common.CommandValueToReading(cv, "device", "", contract.Base64Encoding)
+	cv, _ = dsModels.NewFloat64Value("temperature", 0, 54.5)
+	second := *common.CommandValueToReading(cv, "device", "", contract.Base64Encoding)
+	cv, _ = dsModels.NewFloat64Value("temperature", 0, 55.5)
+	third := *common.CommandValueToReading(cv, "device", "", contract.ENotation)
+
+	if !f.pass("device", first, attributes) {
+		t.Fatal("first reading should be published")
+	}
+	if f.pass("device", second, attributes) {
+		t.Fatal("54.5 is within 10% of 50 and should be suppressed")
+	}
+	if !f.pass("device", third, attributes) {
+		t.Fatal("55.5 is outside 10% of 50 and should be published")
+	}
+}
+
+func TestDeadband_hysteresis(t *testing.T) {
+	f := newTestFilter()
+	attributes := map[string]string{DeadbandAttribute: "1", HysteresisAttribute: "2"}
+	tests := []struct {
+		value    int32
+		expected bool
+	}{
+		{10, true},
+		{12, true},
+		{14, true},
+		// the direction is reversed, the band is 1+2
+		{11, false},
+		{10, true},
+		// same direction as the last change
+		{8, true},
+	}
+	for _, tt := range tests {
+		if result := f.pass("device", int32Reading(tt.value), attributes); result != tt.expected {
+			t.Fatalf("Unexpect test result for value %d, published '%v' should be '%v'", tt.value, result, tt.expected)
+		}
+	}
+}
+
+func TestDeadband_notConfigured(t *testing.T) {
+	f := newTestFilter()
+	str := contract.Reading{Name: "status", ValueType: "String", Value: "OK"}
+
+	if !f.pass("device", int32Reading(1), nil) || !f.pass("device", int32Reading(1), nil) {
+		t.Fatal("readings without deadband settings should be published")
+	}
+	if !f.pass("device", str, map[string]string{DeadbandAttribute: "1"}) {
+		t.Fatal("non-numeric readings should be published")
+	}
+	if len(f.All()) != 0 {
+		t.Fatalf("no state should be kept, but got %v", f.All())
+	}
+}
+
+func TestDeadband_removeDevice(t *testing.T) {
+	f := newTestFilter()
+	attributes := map[string]string{DeadbandAttribute: "1"}
+	f.pass("device", int32Reading(1), attributes)
+
+	f.RemoveDevice("device")
+
+	if len(f.ForDevice("device")) != 0 {
+		t.Fatal("state of the removed device should be cleared")
+	}
+	if !f.pass("device", int32Reading(1), attributes) {
+		t.Fatal("first reading after removal should be published")
+	}
+}
@@ -0,0 +1,412 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package forward queues the events which failed to be delivered on disk and
+// forwards them in order once the delivery succeeds again.
+package forward
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+const (
+	// EvictOldest drops the oldest queued events if the queue is full.
+	EvictOldest = "oldest"
+	// EvictNewest drops the new event if the queue is full.
+	EvictNewest = "newest"
+
+	// DefaultMaxEvents is used if the Device.StoreAndForward.MaxEvents
+	// setting is 0.
+	DefaultMaxEvents = 10000
+	// DefaultRetryInterval and DefaultMaxRetryInterval are used if the
+	// Device.StoreAndForward settings are empty.
+	DefaultRetryInterval    = time.Second
+	DefaultMaxRetryInterval = time.Minute
+
+	// fileSuffix is the suffix of the files of queued events.
+	fileSuffix = ".event"
+)
+
+// ErrQueueFull is returned for new events dropped by the EvictNewest policy.
+var ErrQueueFull = errors.New("event queue is full")
+
+// record is the content of the file of a queued event.
+type record struct {
+	CorrelationID string    `json:"correlationID"`
+	ContentType   string    `json:"contentType"`
+	Device        string    `json:"device"`
+	Command       string    `json:"command,omitempty"`
+	Queued        time.Time `json:"queued"`
+	Payload       []byte    `json:"payload"`
+}
+
+// entry is a queued event, its file is named by the sequence number.
+type entry struct {
+	seq    uint64
+	size   int64
+	queued time.Time
+}
+
+// Queue is the on-disk store-and-forward queue.
+type Queue struct {
+	dir         string
+	maxEvents   int
+	maxSize     int64
+	maxAge      time.Duration
+	evictNewest bool
+	retry       time.Duration
+	maxRetry    time.Duration
+	// deliver delivers a forwarded event, common.DeliverEvent by default.
+	deliver func(ctx context.Context, event *dsModels.Event) error
+
+	mutex     sync.Mutex
+	entries   []entry
+	size      int64
+	next      uint64
+	queued    uint64
+	forwarded uint64
+	evicted   uint64
+	expired   uint64
+	wake      chan struct{}
+}
+
+// Init creates the Queue of the Device.StoreAndForward configuration, sets it
+// as common.EventForwarder and forwards the queued events until ctx is done.
+// Nothing is queued unless it is enabled.
+func Init(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.Device.StoreAndForward
+	if !config.Enabled {
+		return nil
+	}
+	q, err := NewQueue(config, common.ServiceName)
+	if err != nil {
+		return err
+	}
+	if n := len(q.entries); n > 0 {
+		common.LoggingClient.Info(fmt.Sprintf("Forwarding %d events queued in %s", n, q.dir))
+	}
+	common.EventForwarder = q
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.Run(ctx)
+	}()
+	return nil
+}
+
+// NewQueue creates the Queue of the configuration and loads the events queued
+// in its directory, the serviceName names the default directory.
+func NewQueue(config common.StoreAndForwardInfo, serviceName string) (*Queue, error) {
+	q := &Queue{
+		dir:       config.Dir,
+		maxEvents: config.MaxEvents,
+		maxSize:   config.MaxSize,
+		retry:     DefaultRetryInterval,
+		maxRetry:  DefaultMaxRetryInterval,
+		deliver:   common.DeliverEvent,
+		wake:      make(chan struct{}, 1),
+	}
+	if q.dir == "" {
+		q.dir = filepath.Join(os.TempDir(), serviceName+"-events")
+	}
+	if q.maxEvents == 0 {
+		q.maxEvents = DefaultMaxEvents
+	}
+	if q.maxEvents < 0 || q.maxSize < 0 {
+		return nil, fmt.Errorf("invalid Device.StoreAndForward.MaxEvents %d or MaxSize %d", config.MaxEvents, config.MaxSize)
+	}
+	switch strings.ToLower(config.Eviction) {
+	case "", EvictOldest:
+	case EvictNewest:
+		q.evictNewest = true
+	default:
+		return nil, fmt.Errorf("invalid Device.StoreAndForward.Eviction %s", config.Eviction)
+	}
+	var err error
+	if config.MaxAge != "" {
+		q.maxAge, err = time.ParseDuration(config.MaxAge)
+		if err != nil || q.maxAge <= 0 {
+			return nil, fmt.Errorf("invalid Device.StoreAndForward.MaxAge %s", config.MaxAge)
+		}
+	}
+	if config.RetryInterval != "" {
+		q.retry, err = time.ParseDuration(config.RetryInterval)
+		if err != nil || q.retry <= 0 {
+			return nil, fmt.Errorf("invalid Device.StoreAndForward.RetryInterval %s", config.RetryInterval)
+		}
+	}
+	if config.MaxRetryInterval != "" {
+		q.maxRetry, err = time.ParseDuration(config.MaxRetryInterval)
+		if err != nil || q.maxRetry <= 0 {
+			return nil, fmt.Errorf("invalid Device.StoreAndForward.MaxRetryInterval %s", config.MaxRetryInterval)
+		}
+	}
+	if q.maxRetry < q.retry {
+		q.maxRetry = q.retry
+	}
+
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load indexes the files of the queued events in the directory by their
+// sequence number, the time they were written is the time they were queued.
+func (q *Queue) load() error {
+	infos, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasSuffix(name, fileSuffix+".tmp") {
+			// left by a stop while queuing
+			_ = os.Remove(filepath.Join(q.dir, name))
+			continue
+		}
+		if info.IsDir() || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, fileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.entries = append(q.entries, entry{seq: seq, size: info.Size(), queued: info.ModTime()})
+		q.size += info.Size()
+		if seq >= q.next {
+			q.next = seq + 1
+		}
+	}
+	sort.Slice(q.entries, func(i, j int) bool { return q.entries[i].seq < q.entries[j].seq })
+	if len(q.entries) > 0 {
+		q.signal()
+	}
+	return nil
+}
+
+// Store queues the encoded event. If the queue is full the oldest events are
+// dropped, or the event with the EvictNewest policy.
+func (q *Queue) Store(ctx context.Context, event *dsModels.Event) error {
+	r := record{
+		CorrelationID: clients.FromContext(ctx, clients.CorrelationHeader),
+		ContentType:   clients.FromContext(ctx, clients.ContentType),
+		Device:        event.Device,
+		Command:       event.Command,
+		Queued:        time.Now(),
+		Payload:       event.EncodedEvent,
+	}
+	if r.Command == "" && len(event.Readings) > 0 {
+		r.Command = event.Readings[0].Name
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	size := int64(len(data))
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.maxSize > 0 && size > q.maxSize {
+		q.evicted++
+		return fmt.Errorf("event of %d bytes exceeds the queue size", size)
+	}
+	for len(q.entries) >= q.maxEvents || (q.maxSize > 0 && q.size+size > q.maxSize) {
+		if q.evictNewest {
+			q.evicted++
+			return ErrQueueFull
+		}
+		oldest := q.entries[0]
+		common.LoggingClient.Warn(fmt.Sprintf("Event queue is full, dropping the event queued at %v", oldest.queued))
+		q.removeLocked(oldest.seq)
+		q.evicted++
+	}
+
+	e := entry{seq: q.next, size: size, queued: r.Queued}
+	path := q.path(e.seq)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	q.next++
+	q.entries = append(q.entries, e)
+	q.size += size
+	q.queued++
+	q.signal()
+	return nil
+}
+
+// Pending reports whether events are queued.
+func (q *Queue) Pending() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.entries) > 0
+}
+
+// Metrics returns the statistics of the queue.
+func (q *Queue) Metrics() common.EventQueueMetrics {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	m := common.EventQueueMetrics{
+		Depth:     len(q.entries),
+		Size:      q.size,
+		Queued:    q.queued,
+		Forwarded: q.forwarded,
+		Evicted:   q.evicted,
+		Expired:   q.expired,
+	}
+	if len(q.entries) > 0 {
+		m.OldestAge = time.Since(q.entries[0].queued)
+	}
+	return m
+}
+
+// Run forwards the queued events until ctx is done. After a failed delivery
+// it waits for the retry interval, which doubles with every further failure
+// up to the max retry interval, new events don't interrupt the wait.
+func (q *Queue) Run(ctx context.Context) {
+	backoff := q.retry
+	var retry <-chan time.Time
+	for {
+		if retry == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case <-retry:
+				retry = nil
+			}
+		}
+
+		if err := q.forward(ctx); err != nil {
+			common.LoggingClient.Warn(fmt.Sprintf("Forwarding queued events failed, retrying in %v: %v", backoff, err))
+			retry = time.After(backoff)
+			backoff *= 2
+			if backoff > q.maxRetry {
+				backoff = q.maxRetry
+			}
+		} else {
+			backoff = q.retry
+		}
+	}
+}
+
+// forward delivers the queued events in order until the queue is empty or a
+// delivery fails. Expired and unreadable events are dropped.
+func (q *Queue) forward(ctx context.Context) error {
+	for ctx.Err() == nil {
+		q.mutex.Lock()
+		if len(q.entries) == 0 {
+			q.mutex.Unlock()
+			return nil
+		}
+		e := q.entries[0]
+		q.mutex.Unlock()
+
+		r, err := q.read(e.seq)
+		if os.IsNotExist(err) {
+			// evicted meanwhile
+			q.remove(e.seq)
+			continue
+		} else if err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Dropping the unreadable queued event %s: %v", q.path(e.seq), err))
+			q.remove(e.seq)
+			continue
+		}
+		if q.maxAge > 0 && time.Since(r.Queued) > q.maxAge {
+			common.LoggingClient.Warn(fmt.Sprintf("Dropping the event of Device %s queued at %v, it is older than %v", r.Device, r.Queued, q.maxAge))
+			q.remove(e.seq)
+			q.mutex.Lock()
+			q.expired++
+			q.mutex.Unlock()
+			continue
+		}
+
+		evtCtx := context.WithValue(context.Background(), common.CorrelationHeader, r.CorrelationID)
+		evtCtx = context.WithValue(evtCtx, clients.ContentType, r.ContentType)
+		event := &dsModels.Event{Event: contract.Event{Device: r.Device}, EncodedEvent: r.Payload, Command: r.Command}
+		if err := q.deliver(evtCtx, event); err != nil {
+			return err
+		}
+		q.remove(e.seq)
+		q.mutex.Lock()
+		q.forwarded++
+		q.mutex.Unlock()
+	}
+	return ctx.Err()
+}
+
+func (q *Queue) read(seq uint64) (record, error) {
+	var r record
+	data, err := ioutil.ReadFile(q.path(seq))
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+func (q *Queue) remove(seq uint64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.removeLocked(seq)
+}
+
+// removeLocked removes the entry and the file of the event, the mutex must be
+// held.
+func (q *Queue) removeLocked(seq uint64) {
+	for i, e := range q.entries {
+		if e.seq == seq {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+			q.size -= e.size
+			break
+		}
+	}
+	if err := os.Remove(q.path(seq)); err != nil && !os.IsNotExist(err) {
+		common.LoggingClient.Warn(fmt.Sprintf("Failed to remove the queued event %s: %v", q.path(seq), err))
+	}
+}
+
+// signal wakes Run up without blocking.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, fileSuffix))
+}
@@ -0,0 +1,246 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package forward
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func init() {
+	common.LoggingClient = logger.MockLogger{}
+}
+
+// endpoint is a delivery stand-in which fails while it is down and records
+// the delivered events.
+type endpoint struct {
+	mutex     sync.Mutex
+	down      bool
+	delivered []string // correlation IDs
+	devices   []string
+	commands  []string
+}
+
+func (e *endpoint) deliver(ctx context.Context, event *dsModels.Event) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.down {
+		return errors.New("unreachable")
+	}
+	e.delivered = append(e.delivered, clients.FromContext(ctx, clients.CorrelationHeader))
+	e.devices = append(e.devices, event.Device)
+	e.commands = append(e.commands, event.Command)
+	return nil
+}
+
+func (e *endpoint) setDown(down bool) {
+	e.mutex.Lock()
+	e.down = down
+	e.mutex.Unlock()
+}
+
+func (e *endpoint) count() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return len(e.delivered)
+}
+
+func newTestQueue(t *testing.T, dir string, config common.StoreAndForwardInfo) (*Queue, *endpoint) {
+	config.Dir = dir
+	q, err := NewQueue(config, "test")
+	if err != nil {
+		t.Fatalf("Failed to create the queue: %v", err)
+	}
+	ep := &endpoint{}
+	q.deliver = ep.deliver
+	return q, ep
+}
+
+func store(t *testing.T, q *Queue, correlation string) {
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, correlation)
+	ctx = context.WithValue(ctx, clients.ContentType, clients.ContentTypeJSON)
+	event := &dsModels.Event{Event: contract.Event{Device: "device", Readings: []contract.Reading{{Name: "Temperature"}}}}
+	event.EncodedEvent = []byte(`{"device":"device","readings":[{"name":"Temperature","value":"21"}]}`)
+	if err := q.Store(ctx, event); err != nil {
+		t.Fatalf("Failed to store event %s: %v", correlation, err)
+	}
+}
+
+func TestNewQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forward")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	invalid := []common.StoreAndForwardInfo{
+		{MaxEvents: -1},
+		{MaxSize: -1},
+		{Eviction: "random"},
+		{MaxAge: "0s"},
+		{RetryInterval: "soon"},
+		{MaxRetryInterval: "-1m"},
+	}
+	for _, config := range invalid {
+		config.Dir = dir
+		if _, err := NewQueue(config, "test"); err == nil {
+			t.Fatalf("Expected an error for configuration %+v", config)
+		}
+	}
+
+	q, _ := newTestQueue(t, dir, common.StoreAndForwardInfo{Eviction: "Newest", RetryInterval: "2m"})
+	if q.maxEvents != DefaultMaxEvents || !q.evictNewest || q.retry != 2*time.Minute || q.maxRetry != 2*time.Minute {
+		t.Fatalf("Unexpected queue %+v", q)
+	}
+}
+
+func TestQueueForwardsInOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forward")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, ep := newTestQueue(t, dir, common.StoreAndForwardInfo{RetryInterval: "10ms", MaxRetryInterval: "20ms"})
+	ep.setDown(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for _, c := range []string{"a", "b", "c"} {
+		store(t, q, c)
+	}
+	if !q.Pending() {
+		t.Fatalf("Expected pending events")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if m := q.Metrics(); m.Depth != 3 || m.Queued != 3 || m.Forwarded != 0 || m.Size == 0 || m.OldestAge <= 0 {
+		t.Fatalf("Unexpected metrics %+v while the endpoint is down", m)
+	}
+
+	ep.setDown(false)
+	deadline := time.Now().Add(time.Second)
+	for q.Pending() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if ep.count() != 3 || ep.delivered[0] != "a" || ep.delivered[1] != "b" || ep.delivered[2] != "c" {
+		t.Fatalf("Unexpected delivered events %v", ep.delivered)
+	}
+	if ep.devices[0] != "device" || ep.commands[0] != "Temperature" {
+		t.Fatalf("Unexpected device %s or command %s", ep.devices[0], ep.commands[0])
+	}
+	if m := q.Metrics(); m.Depth != 0 || m.Size != 0 || m.Forwarded != 3 || m.OldestAge != 0 {
+		t.Fatalf("Unexpected metrics %+v after forwarding", m)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Fatalf("Expected the files of the forwarded events to be removed, found %d", len(files))
+	}
+}
+
+func TestQueueEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forward")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, ep := newTestQueue(t, dir, common.StoreAndForwardInfo{MaxEvents: 2})
+	for _, c := range []string{"a", "b", "c"} {
+		store(t, q, c)
+	}
+	if err := q.forward(context.Background()); err != nil {
+		t.Fatalf("Failed to forward: %v", err)
+	}
+	if len(ep.delivered) != 2 || ep.delivered[0] != "b" || q.Metrics().Evicted != 1 {
+		t.Fatalf("Expected the oldest event to be evicted, delivered %v", ep.delivered)
+	}
+
+	q, ep = newTestQueue(t, dir, common.StoreAndForwardInfo{MaxEvents: 2, Eviction: EvictNewest})
+	store(t, q, "a")
+	store(t, q, "b")
+	if err := q.Store(context.Background(), &dsModels.Event{}); err != ErrQueueFull {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	if err := q.forward(context.Background()); err != nil {
+		t.Fatalf("Failed to forward: %v", err)
+	}
+	if len(ep.delivered) != 2 || ep.delivered[1] != "b" || q.Metrics().Evicted != 1 {
+		t.Fatalf("Expected the newest event to be evicted, delivered %v", ep.delivered)
+	}
+
+	q, _ = newTestQueue(t, dir, common.StoreAndForwardInfo{MaxSize: 10})
+	if err := q.Store(context.Background(), &dsModels.Event{}); err == nil {
+		t.Fatalf("Expected an error for an event exceeding the queue size")
+	}
+}
+
+func TestQueueReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forward")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, _ := newTestQueue(t, dir, common.StoreAndForwardInfo{})
+	store(t, q, "a")
+	store(t, q, "b")
+	_ = ioutil.WriteFile(filepath.Join(dir, "00000000000000000002.event.tmp"), []byte("{"), 0600)
+
+	q, ep := newTestQueue(t, dir, common.StoreAndForwardInfo{})
+	if m := q.Metrics(); m.Depth != 2 {
+		t.Fatalf("Expected 2 reloaded events, got %d", m.Depth)
+	}
+	store(t, q, "c")
+	if err := q.forward(context.Background()); err != nil {
+		t.Fatalf("Failed to forward: %v", err)
+	}
+	if len(ep.delivered) != 3 || ep.delivered[0] != "a" || ep.delivered[2] != "c" {
+		t.Fatalf("Unexpected delivered events %v", ep.delivered)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00000000000000000002.event.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the temporary file to be removed")
+	}
+}
+
+func TestQueueExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forward")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, ep := newTestQueue(t, dir, common.StoreAndForwardInfo{MaxAge: "20ms"})
+	store(t, q, "a")
+	time.Sleep(30 * time.Millisecond)
+	store(t, q, "b")
+	if err := q.forward(context.Background()); err != nil {
+		t.Fatalf("Failed to forward: %v", err)
+	}
+	if len(ep.delivered) != 1 || ep.delivered[0] != "b" || q.Metrics().Expired != 1 {
+		t.Fatalf("Expected the expired event to be dropped, delivered %v", ep.delivered)
+	}
+}
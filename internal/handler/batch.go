@@ -0,0 +1,135 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// BatchReadHandler reads the given commands or DeviceResources of the Device
+// given by name with as few HandleReadCommands calls as MaxCmdOps allows, so
+// that drivers can optimize the reads, e.g. into block reads of a bus. The
+// CommandRequests of a command are never split across calls. It returns the
+// event or the error of every command in the order of the commands.
+func BatchReadHandler(deviceName string, cmds []string) ([]*dsModels.Event, []common.AppError) {
+	events := make([]*dsModels.Event, len(cmds))
+	appErrs := make([]common.AppError, len(cmds))
+	failAll := func(appErr common.AppError) ([]*dsModels.Event, []common.AppError) {
+		for i := range appErrs {
+			if appErrs[i] == nil {
+				appErrs[i] = appErr
+			}
+		}
+		return events, appErrs
+	}
+
+	d, ok := cache.Devices().ForName(deviceName)
+	if !ok {
+		msg := fmt.Sprintf("Device: %s not found; %s", deviceName, common.GetCmdMethod)
+		common.LoggingClient.Error(msg)
+		return failAll(common.NewNotFoundError(msg, nil))
+	}
+	if appErr := checkDeviceState(&d, common.GetCmdMethod); appErr != nil {
+		return failAll(appErr)
+	}
+
+	reqs := make([][]dsModels.CommandRequest, len(cmds))
+	for i, cmd := range cmds {
+		cmdExists, err := cache.Profiles().CommandExists(d.Profile.Name, cmd, common.GetCmdMethod)
+		if err != nil {
+			msg := fmt.Sprintf("internal error; Device: %s searching %s in cache failed; %s", d.Name, cmd, common.GetCmdMethod)
+			common.LoggingClient.Error(msg)
+			appErrs[i] = common.NewServerError(msg, err)
+		} else if cmdExists {
+			reqs[i], appErrs[i] = readCmdRequests(&d, cmd, "")
+		} else if dr, ok := cache.Profiles().DeviceResource(d.Profile.Name, cmd); ok {
+			reqs[i] = []dsModels.CommandRequest{{DeviceResourceName: dr.Name, Attributes: dr.Attributes, Type: dsModels.ParseValueType(dr.Properties.Value.Type)}}
+		} else {
+			msg := fmt.Sprintf("%s for Device: %s not found; %s", cmd, d.Name, common.GetCmdMethod)
+			common.LoggingClient.Error(msg)
+			appErrs[i] = common.NewNotFoundError(msg, nil)
+		}
+	}
+
+	for _, chunk := range chunkCommands(reqs, appErrs, common.CurrentConfig.Device.MaxCmdOps) {
+		var chunkReqs []dsModels.CommandRequest
+		for _, i := range chunk {
+			chunkReqs = append(chunkReqs, reqs[i]...)
+		}
+
+		results, err := common.Driver.HandleReadCommands(d.Name, d.Protocols, chunkReqs)
+		if err == nil {
+			results, err = matchResults(chunkReqs, results)
+		}
+		for _, i := range chunk {
+			if err != nil {
+				msg := fmt.Sprintf("Handler - BatchReadHandler: error for Device: %s cmd: %s, %v", d.Name, cmds[i], err)
+				common.LoggingClient.Error(msg)
+				appErrs[i] = common.NewServerError(msg, err)
+				continue
+			}
+			events[i], appErrs[i] = cvsToEvent(&d, results[:len(reqs[i])], cmds[i])
+			results = results[len(reqs[i]):]
+		}
+	}
+
+	go common.UpdateLastConnected(d.Name)
+	return events, appErrs
+}
+
+// matchResults orders the results of HandleReadCommands like the requests by
+// their DeviceResourceName, the k-th request of a DeviceResource gets its k-th
+// result. It fails if the result of a request is missing, which is also the
+// case if another one is duplicated.
+func matchResults(reqs []dsModels.CommandRequest, results []*dsModels.CommandValue) ([]*dsModels.CommandValue, error) {
+	if len(results) != len(reqs) {
+		return nil, fmt.Errorf("%d results returned for %d requests", len(results), len(reqs))
+	}
+	byName := make(map[string][]*dsModels.CommandValue, len(results))
+	for _, cv := range results {
+		if cv == nil {
+			return nil, fmt.Errorf("nil result returned")
+		}
+		byName[cv.DeviceResourceName] = append(byName[cv.DeviceResourceName], cv)
+	}
+	matched := make([]*dsModels.CommandValue, len(reqs))
+	for i, req := range reqs {
+		cvs := byName[req.DeviceResourceName]
+		if len(cvs) == 0 {
+			return nil, fmt.Errorf("no result returned for DeviceResource %s, the results are %v", req.DeviceResourceName, results)
+		}
+		matched[i], byName[req.DeviceResourceName] = cvs[0], cvs[1:]
+	}
+	return matched, nil
+}
+
+// chunkCommands groups the indexes of the commands without error so that the
+// CommandRequests of every group don't exceed maxCmdOps.
+func chunkCommands(reqs [][]dsModels.CommandRequest, appErrs []common.AppError, maxCmdOps int) [][]int {
+	var chunks [][]int
+	var chunk []int
+	size := 0
+	for i := range reqs {
+		if appErrs[i] != nil || len(reqs[i]) == 0 {
+			continue
+		}
+		if len(chunk) > 0 && size+len(reqs[i]) > maxCmdOps {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, i)
+		size += len(reqs[i])
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
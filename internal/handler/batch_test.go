@@ -0,0 +1,80 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestChunkCommands(t *testing.T) {
+	reqs := [][]dsModels.CommandRequest{
+		make([]dsModels.CommandRequest, 2),
+		make([]dsModels.CommandRequest, 1),
+		make([]dsModels.CommandRequest, 3),
+		make([]dsModels.CommandRequest, 1),
+		make([]dsModels.CommandRequest, 5),
+	}
+	appErrs := make([]common.AppError, len(reqs))
+	appErrs[3] = common.NewNotFoundError("not found", nil)
+
+	chunks := chunkCommands(reqs, appErrs, 3)
+	// A command with more requests than MaxCmdOps is read alone, its
+	// requests are never split.
+	assert.Equal(t, [][]int{{0, 1}, {2}, {4}}, chunks)
+}
+
+func TestMatchResults(t *testing.T) {
+	reqs := []dsModels.CommandRequest{{DeviceResourceName: "a"}, {DeviceResourceName: "b"}, {DeviceResourceName: "a"}}
+	a1 := dsModels.NewStringValue("a", 0, "1")
+	a2 := dsModels.NewStringValue("a", 0, "2")
+	b := dsModels.NewStringValue("b", 0, "3")
+
+	matched, err := matchResults(reqs, []*dsModels.CommandValue{b, a1, a2})
+	require.NoError(t, err)
+	assert.Equal(t, []*dsModels.CommandValue{a1, b, a2}, matched)
+
+	_, err = matchResults(reqs, []*dsModels.CommandValue{a1, b})
+	assert.Error(t, err, "a missing result should be rejected")
+	_, err = matchResults(reqs, []*dsModels.CommandValue{a1, b, b})
+	assert.Error(t, err, "a duplicated result should be rejected")
+	_, err = matchResults(reqs, []*dsModels.CommandValue{a1, b, dsModels.NewStringValue("c", 0, "4")})
+	assert.Error(t, err, "a result of another DeviceResource should be rejected")
+}
+
+func TestBatchReadHandler(t *testing.T) {
+	common.CurrentConfig.Device.MaxCmdOps = 1
+	defer func() {
+		common.CurrentConfig.Device.MaxCmdOps = 128
+	}()
+
+	cmds := []string{mock.ResourceObjectInt8, "InexistentCmd", "Error", mock.ResourceObjectInt16}
+	events, appErrs := BatchReadHandler(deviceIntegerGenerator.Name, cmds)
+	require.Len(t, events, len(cmds))
+	require.Len(t, appErrs, len(cmds))
+
+	for _, i := range []int{0, 3} {
+		require.Nil(t, appErrs[i], cmds[i])
+		require.Len(t, events[i].Readings, 1)
+		assert.Equal(t, cmds[i], events[i].Readings[0].Name)
+		assert.Equal(t, deviceIntegerGenerator.Name, events[i].Device)
+	}
+	assert.Equal(t, 404, appErrs[1].Code())
+	assert.Equal(t, 500, appErrs[2].Code())
+	assert.Nil(t, events[2])
+
+	_, appErrs = BatchReadHandler("InexistentDevice", cmds)
+	for _, appErr := range appErrs {
+		assert.Equal(t, 404, appErr.Code())
+	}
+}
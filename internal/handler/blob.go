@@ -0,0 +1,52 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/blob"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// BlobHandler opens the stored binary value given by id, the caller must
+// close the file.
+func BlobHandler(vars map[string]string) (*os.File, blob.Info, common.AppError) {
+	id := vars[common.IdVar]
+	s := blob.Blobs()
+	if s == nil {
+		msg := "Binary value store is not initialized"
+		common.LoggingClient.Error(msg)
+		return nil, blob.Info{}, common.NewServerError(msg, nil)
+	}
+
+	f, info, err := s.Open(id)
+	if err == blob.ErrNotFound {
+		msg := fmt.Sprintf("Binary value: %s not found", id)
+		common.LoggingClient.Error(msg)
+		return nil, blob.Info{}, common.NewNotFoundError(msg, err)
+	} else if err != nil {
+		msg := fmt.Sprintf("Opening binary value: %s failed: %v", id, err)
+		common.LoggingClient.Error(msg)
+		return nil, blob.Info{}, common.NewServerError(msg, err)
+	}
+	return f, info, nil
+}
+
+// RemoveBlobHandler removes the stored binary value given by id, e.g. once
+// the consumer of the event has fetched it.
+func RemoveBlobHandler(vars map[string]string) common.AppError {
+	id := vars[common.IdVar]
+	s := blob.Blobs()
+	if s == nil || !s.Remove(id) {
+		msg := fmt.Sprintf("Binary value: %s not found", id)
+		common.LoggingClient.Error(msg)
+		return common.NewNotFoundError(msg, nil)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/filter"
 	"github.com/edgexfoundry/device-sdk-go/internal/provision"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
@@ -121,6 +122,8 @@ func handleDeleteDevice(id string) common.AppError {
 	if ok {
 		common.LoggingClient.Debug(fmt.Sprintf("Handler - stopping AutoEvents for updated device %s", device.Name))
 		autoevent.GetManager().StopForDevice(device.Name)
+		filter.Deadbands().RemoveDevice(device.Name)
+		autoevent.RemoveTriggerState(device.Name)
 	}
 
 	err := cache.Devices().Remove(id)
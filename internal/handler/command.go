@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/blob"
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
@@ -46,16 +47,8 @@ func CommandHandler(vars map[string]string, body string, method string, queryPar
 		return nil, common.NewNotFoundError(msg, nil)
 	}
 
-	if d.AdminState == contract.Locked {
-		msg := fmt.Sprintf("%s is locked; %s", d.Name, method)
-		common.LoggingClient.Error(msg)
-		return nil, common.NewLockedError(msg, nil)
-	}
-
-	if d.OperatingState == contract.Disabled {
-		msg := fmt.Sprintf("%s is disabled; %s", d.Name, method)
-		common.LoggingClient.Error(msg)
-		return nil, common.NewLockedError(msg, nil)
+	if appErr := checkDeviceState(&d, method); appErr != nil {
+		return nil, appErr
 	}
 
 	// TODO: need to mark device when operation in progress, so it can't be removed till completed
@@ -96,6 +89,22 @@ func CommandHandler(vars map[string]string, body string, method string, queryPar
 	return evt, appErr
 }
 
+// checkDeviceState returns a locked error if the Device is locked or disabled.
+func checkDeviceState(d *contract.Device, method string) common.AppError {
+	if d.AdminState == contract.Locked {
+		msg := fmt.Sprintf("%s is locked; %s", d.Name, method)
+		common.LoggingClient.Error(msg)
+		return common.NewLockedError(msg, nil)
+	}
+
+	if d.OperatingState == contract.Disabled {
+		msg := fmt.Sprintf("%s is disabled; %s", d.Name, method)
+		common.LoggingClient.Error(msg)
+		return common.NewLockedError(msg, nil)
+	}
+	return nil
+}
+
 func execReadDeviceResource(device *contract.Device, dr *contract.DeviceResource, queryParams string) (*dsModels.Event, common.AppError) {
 	var reqs []dsModels.CommandRequest
 	var req dsModels.CommandRequest
@@ -124,8 +133,8 @@ func execReadDeviceResource(device *contract.Device, dr *contract.DeviceResource
 
 func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd string) (*dsModels.Event, common.AppError) {
 	readings := make([]contract.Reading, 0, common.CurrentConfig.Device.MaxCmdOps)
+	event := &dsModels.Event{}
 	var transformsOK = true
-	var err error
 
 	for _, cv := range cvs {
 		// get the device resource associated with the rsp.RO
@@ -136,31 +145,8 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 			return nil, common.NewServerError(msg, nil)
 		}
 
-		if common.CurrentConfig.Device.DataTransform {
-			err = transformer.TransformReadResult(cv, dr.Properties.Value)
-			if err != nil {
-				common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: CommandValue (%s) transformed failed: %v", cv.String(), err))
-				transformsOK = false
-			}
-		}
-
-		err = transformer.CheckAssertion(cv, dr.Properties.Value.Assertion, device)
-		if err != nil {
-			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: Assertion failed for device resource: %s, with value: %v", cv.String(), err))
-			cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
-		}
-
-		ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, cv.DeviceResourceName, common.GetCmdMethod)
-		if err != nil {
-			common.LoggingClient.Debug(fmt.Sprintf("getting resource operation failed: %s", err.Error()))
-		} else if len(ro.Mappings) > 0 {
-			newCV, ok := transformer.MapCommandValue(cv, ro.Mappings)
-			if ok {
-				cv = newCV
-			} else {
-				common.LoggingClient.Warn(fmt.Sprintf("Handler - execReadCmd: Resource Operation (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceCommand, cv.String(), ro.Mappings))
-				//transformsOK = false  // issue #89 will discuss how to handle there is no mapping matched
-			}
+		if cv, ok = readValue(device, dr, cv, false, nil); !ok {
+			transformsOK = false
 		}
 
 		// TODO: the Java SDK supports a RO secondary device resource.
@@ -171,13 +157,29 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 		// be killed completely.
 
 		reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
+		stored, checksum, err := blob.StoreReading(cv, reading)
+		if err != nil {
+			common.ReleaseReading(reading)
+			msg := fmt.Sprintf("Handler - execReadCmd: storing binary value of device: %s DeviceResource: %s failed: %v", device.Name, cv.DeviceResourceName, err)
+			common.LoggingClient.Error(msg)
+			return nil, common.NewServerError(msg, err)
+		}
+		index := len(readings)
+		if stored {
+			event.AddReadingFlags(index, []string{dsModels.FlagBlobReference})
+			event.AddReadingChecksum(index, checksum)
+		}
 		readings = append(readings, *reading)
+		event.AddReadingFlags(index, cv.Flags)
+		event.AddReadingQuality(index, cv.Quality)
+		event.AddReadingTags(index, common.ReadingTags(cv, dr))
 
 		if cv.Type == dsModels.Binary {
 			common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: device: %s DeviceResource: %v reading: binary value", device.Name, cv.DeviceResourceName))
 		} else {
 			common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: device: %s DeviceResource: %v reading: %v", device.Name, cv.DeviceResourceName, reading))
 		}
+		common.ReleaseReading(reading)
 	}
 
 	if !transformsOK {
@@ -188,8 +190,9 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 	}
 
 	// push to Core Data
-	cevent := contract.Event{Device: device.Name, Readings: readings}
-	event := &dsModels.Event{Event: cevent}
+	event.Event = contract.Event{Device: device.Name, Readings: readings}
+	event.Command = cmd
+	event.AddTags(common.DeviceTags(*device))
 	event.Origin = common.GetUniqueOrigin()
 
 	// TODO: enforce config.MaxCmdValueLen; need to include overhead for
@@ -200,6 +203,23 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 }
 
 func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsModels.Event, common.AppError) {
+	reqs, appErr := readCmdRequests(device, cmd, queryParams)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	results, err := common.Driver.HandleReadCommands(device.Name, device.Protocols, reqs)
+	if err != nil {
+		msg := fmt.Sprintf("Handler - execReadCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
+		return nil, common.NewServerError(msg, err)
+	}
+
+	return cvsToEvent(device, results, cmd)
+}
+
+// readCmdRequests makes the CommandRequests of the ResourceOperations of the
+// command.
+func readCmdRequests(device *contract.Device, cmd string, queryParams string) ([]dsModels.CommandRequest, common.AppError) {
 	// make ResourceOperations
 	ros, err := cache.Profiles().ResourceOperations(device.Profile.Name, cmd, common.GetCmdMethod)
 	if err != nil {
@@ -243,14 +263,7 @@ func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsMo
 		}
 		reqs[i].Type = dsModels.ParseValueType(dr.Properties.Value.Type)
 	}
-
-	results, err := common.Driver.HandleReadCommands(device.Name, device.Protocols, reqs)
-	if err != nil {
-		msg := fmt.Sprintf("Handler - execReadCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
-		return nil, common.NewServerError(msg, err)
-	}
-
-	return cvsToEvent(device, results, cmd)
+	return reqs, nil
 }
 
 func execWriteDeviceResource(device *contract.Device, dr *contract.DeviceResource, params string) common.AppError {
@@ -281,16 +294,17 @@ func execWriteDeviceResource(device *contract.Device, dr *contract.DeviceResourc
 	common.LoggingClient.Debug(fmt.Sprintf("Handler - execWriteDeviceResource: putting deviceResource: %s", dr.Name))
 	reqs[0].DeviceResourceName = cv.DeviceResourceName
 	reqs[0].Attributes = dr.Attributes
-	reqs[0].Type = cv.Type
 
 	if common.CurrentConfig.Device.DataTransform {
-		err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+		err = transformer.TransformWrite(cv, *dr)
 		if err != nil {
 			msg := fmt.Sprintf("Handler - execWriteDeviceResource: CommandValue (%s) transformed failed: %v", cv.String(), err)
 			common.LoggingClient.Error(msg)
 			return common.NewServerError(msg, err)
 		}
 	}
+	// a driver transform may change the ValueType
+	reqs[0].Type = cv.Type
 
 	err = common.Driver.HandleWriteCommands(device.Name, device.Protocols, reqs, []*dsModels.CommandValue{cv})
 	if err != nil {
@@ -342,16 +356,17 @@ func execWriteCmd(device *contract.Device, cmd string, params string) common.App
 
 		reqs[i].DeviceResourceName = cv.DeviceResourceName
 		reqs[i].Attributes = dr.Attributes
-		reqs[i].Type = cv.Type
 
 		if common.CurrentConfig.Device.DataTransform {
-			err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+			err = transformer.TransformWrite(cv, dr)
 			if err != nil {
 				msg := fmt.Sprintf("Handler - execWriteCmd: CommandValue (%s) transformed failed: %v", cv.String(), err)
 				common.LoggingClient.Error(msg)
 				return common.NewServerError(msg, err)
 			}
 		}
+		// a driver transform may change the ValueType
+		reqs[i].Type = cv.Type
 	}
 
 	err = common.Driver.HandleWriteCommands(device.Name, device.Protocols, reqs, cvs)
@@ -392,15 +407,9 @@ func parseWriteParams(profileName string, ros []contract.ResourceOperation, para
 			}
 		}
 
-		if len(ro.Mappings) > 0 {
-			newP, ok := ro.Mappings[p]
-			if ok {
-				p = newP
-			} else {
-				msg := fmt.Sprintf("parseWriteParams: Resource (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceResource, p, ro.Mappings)
-				common.LoggingClient.Warn(msg)
-				//return result, fmt.Errorf(msg) // issue #89 will discuss how to handle there is no mapping matched
-			}
+		p, err = mapWriteParam(profileName, ro, p)
+		if err != nil {
+			return result, err
 		}
 
 		cv, err := createCommandValueFromRO(profileName, &ro, p)
@@ -414,6 +423,39 @@ func parseWriteParams(profileName string, ros []contract.ResourceOperation, para
 	return result, nil
 }
 
+// mapWriteParam translates the parameter with the mapping table of the set
+// ResourceOperation. If the set operation has no mapping table, the mapping
+// table of the get operation of the same DeviceResource is applied in reverse.
+func mapWriteParam(profileName string, ro contract.ResourceOperation, p string) (string, error) {
+	if len(ro.Mappings) > 0 {
+		newP, ok := transformer.MapValue(p, ro.Mappings)
+		if ok {
+			return newP, nil
+		}
+		msg := fmt.Sprintf("parseWriteParams: Resource (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceResource, p, ro.Mappings)
+		if transformer.StrictMapping(ro.Mappings) {
+			return p, fmt.Errorf(msg)
+		}
+		common.LoggingClient.Warn(msg)
+		return p, nil
+	}
+
+	getRO, err := cache.Profiles().ResourceOperation(profileName, ro.DeviceResource, common.GetCmdMethod)
+	if err != nil || len(getRO.Mappings) == 0 {
+		return p, nil
+	}
+	newP, ok := transformer.ReverseMapValue(p, getRO.Mappings)
+	if ok {
+		return newP, nil
+	}
+	msg := fmt.Sprintf("parseWriteParams: Resource (%s) reverse mapping value (%s) failed with the mapping table: %v", ro.DeviceResource, p, getRO.Mappings)
+	if transformer.StrictMapping(getRO.Mappings) {
+		return p, fmt.Errorf(msg)
+	}
+	common.LoggingClient.Debug(msg)
+	return p, nil
+}
+
 func parseParams(params string) (paramMap map[string]string, err error) {
 	err = json.Unmarshal([]byte(params), &paramMap)
 	if err != nil {
@@ -439,6 +481,86 @@ func createCommandValueFromRO(profileName string, ro *contract.ResourceOperation
 	return createCommandValueFromDR(&dr, v)
 }
 
+// readValue runs the read pipeline, i.e. raw value decoding, transform,
+// assertion and mapping, on a CommandValue the Driver returned for the
+// DeviceResource of the Device. It returns the resulting CommandValue and
+// false if a stage failed, then no reading should be sent. A failed assertion
+// disables the Device unless dryRun is set. The step func, if not nil, is
+// called after every stage run.
+func readValue(device *contract.Device, dr contract.DeviceResource, cv *dsModels.CommandValue, dryRun bool,
+	step func(name string, cv *dsModels.CommandValue, err error)) (*dsModels.CommandValue, bool) {
+	if step == nil {
+		step = func(string, *dsModels.CommandValue, error) {}
+	}
+	ok := true
+
+	if cv.Type == dsModels.Binary && dsModels.ParseValueType(dr.Properties.Value.Type) != dsModels.Binary {
+		err := transformer.DecodeRawValue(cv, dr)
+		if err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: raw value of CommandValue (%s) decoding failed: %v", cv.String(), err))
+			ok = false
+		}
+		step(stepDecode, cv, err)
+	}
+
+	if common.CurrentConfig.Device.DataTransform {
+		err := transformer.TransformRead(cv, dr)
+		if err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: CommandValue (%s) transformed failed: %v", cv.String(), err))
+			ok = false
+		}
+		step(stepTransform, cv, err)
+	}
+
+	if dr.Properties.Value.Assertion != "" {
+		var err error
+		if dryRun {
+			// the Device isn't disabled by a dry run
+			if err = transformer.VerifyAssertion(cv, dr.Properties.Value.Assertion); err != nil {
+				if marked, qErr := transformer.MarkAssertionQuality(cv, dr); marked {
+					err = nil
+				} else if qErr != nil {
+					err = qErr
+				}
+			}
+		} else {
+			err = transformer.CheckResourceAssertion(cv, dr, device)
+		}
+		if err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: Assertion failed for device resource: %s, with value: %v", cv.String(), err))
+			failed := dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
+			failed.CopyMetadata(cv)
+			cv = failed
+		}
+		step(stepAssertion, cv, err)
+	}
+
+	ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, cv.DeviceResourceName, common.GetCmdMethod)
+	if err != nil {
+		common.LoggingClient.Debug(fmt.Sprintf("getting resource operation failed: %s", err.Error()))
+	} else if len(ro.Mappings) > 0 {
+		newCV, mapped := transformer.MapCommandValue(cv, ro.Mappings)
+		var err error
+		if mapped {
+			cv = newCV
+		} else if transformer.StrictMapping(ro.Mappings) {
+			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: Resource Operation (%s) mapping value (%s) failed with the strict mapping table: %v", ro.DeviceCommand, cv.String(), ro.Mappings))
+			err = fmt.Errorf("no mapping matched the value with the strict mapping table: %v", ro.Mappings)
+			ok = false
+		} else {
+			common.LoggingClient.Warn(fmt.Sprintf("Handler - execReadCmd: Resource Operation (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceCommand, cv.String(), ro.Mappings))
+		}
+		step(stepMapping, cv, err)
+	}
+	return cv, ok
+}
+
+// ReadValue runs the read pipeline of a read command on a CommandValue the
+// Driver pushed asynchronously, it returns false if no reading should be sent.
+func ReadValue(device *contract.Device, dr contract.DeviceResource, cv *dsModels.CommandValue) (*dsModels.CommandValue, bool) {
+	return readValue(device, dr, cv, false, nil)
+}
+
 func createCommandValueFromDR(dr *contract.DeviceResource, v string) (*dsModels.CommandValue, error) {
 	var result *dsModels.CommandValue
 	var err error
@@ -581,68 +703,47 @@ func createCommandValueFromDR(dr *contract.DeviceResource, v string) (*dsModels.
 		}
 		result, err = dsModels.NewInt64ArrayValue(dr.Name, origin, arr)
 	case "float32":
-		n, e := strconv.ParseFloat(v, 32)
-		if e == nil {
-			result, err = dsModels.NewFloat32Value(dr.Name, origin, float32(n))
-			break
-		}
-		if numError, ok := e.(*strconv.NumError); ok {
-			if numError.Err == strconv.ErrRange {
-				err = e
-				break
-			}
-		}
-		var decodedToBytes []byte
-		decodedToBytes, err = base64.StdEncoding.DecodeString(v)
+		var val float64
+		val, err = parseFloatParameter(v, 32, common.FloatEncoding(dr.Properties.Value.FloatEncoding))
 		if err == nil {
-			var val float32
-			val, err = float32FromBytes(decodedToBytes)
-			if err != nil {
-				break
-			} else if math.IsNaN(float64(val)) {
-				err = fmt.Errorf("fail to parse %v to float32, unexpected result %v", v, val)
-			} else {
-				result, err = dsModels.NewFloat32Value(dr.Name, origin, val)
-			}
+			result, err = dsModels.NewFloat32Value(dr.Name, origin, float32(val))
 		}
 	case "float32array":
 		var arr []float32
-		err = json.Unmarshal([]byte(v), &arr)
-		if err != nil {
-			return result, err
+		if err = json.Unmarshal([]byte(v), &arr); err != nil {
+			var values []float64
+			values, err = parseFloatArrayParameter(v, 32, common.FloatEncoding(dr.Properties.Value.FloatEncoding))
+			if err != nil {
+				return result, err
+			}
+			arr = make([]float32, len(values))
+			for i, val := range values {
+				arr[i] = float32(val)
+			}
 		}
 		result, err = dsModels.NewFloat32ArrayValue(dr.Name, origin, arr)
 	case "float64":
 		var val float64
-		val, err = strconv.ParseFloat(v, 64)
+		val, err = parseFloatParameter(v, 64, common.FloatEncoding(dr.Properties.Value.FloatEncoding))
 		if err == nil {
 			result, err = dsModels.NewFloat64Value(dr.Name, origin, val)
-			break
-		}
-		if numError, ok := err.(*strconv.NumError); ok {
-			if numError.Err == strconv.ErrRange {
-				break
-			}
 		}
-		var decodedToBytes []byte
-		decodedToBytes, err = base64.StdEncoding.DecodeString(v)
-		if err == nil {
-			val, err = float64FromBytes(decodedToBytes)
+	case "float64array":
+		var arr []float64
+		if err = json.Unmarshal([]byte(v), &arr); err != nil {
+			arr, err = parseFloatArrayParameter(v, 64, common.FloatEncoding(dr.Properties.Value.FloatEncoding))
 			if err != nil {
-				break
-			} else if math.IsNaN(val) {
-				err = fmt.Errorf("fail to parse %v to float64, unexpected result %v", v, val)
-			} else {
-				result, err = dsModels.NewFloat64Value(dr.Name, origin, val)
+				return result, err
 			}
 		}
-	case "float64array":
-		var arr []float64
-		err = json.Unmarshal([]byte(v), &arr)
+		result, err = dsModels.NewFloat64ArrayValue(dr.Name, origin, arr)
+	case "object":
+		var obj interface{}
+		err = json.Unmarshal([]byte(v), &obj)
 		if err != nil {
 			return result, err
 		}
-		result, err = dsModels.NewFloat64ArrayValue(dr.Name, origin, arr)
+		result, err = dsModels.NewObjectValue(dr.Name, origin, obj)
 	}
 
 	if err != nil {
@@ -653,6 +754,89 @@ func createCommandValueFromDR(dr *contract.DeviceResource, v string) (*dsModels.
 	return result, err
 }
 
+// parseFloatParameter parses a float parameter in the float encoding of the
+// resource, i.e. as base64 encoding of its big-endian bytes for Base64 and in
+// decimal or eNotation otherwise. Clients may not know the encoding, so the
+// other format is accepted as fallback, except for decimal values out of the
+// range of the type. If both fail the error of the encoding is returned.
+func parseFloatParameter(v string, bitSize int, encoding string) (float64, error) {
+	if encoding == contract.Base64Encoding {
+		val, base64Err := base64FloatValue(v, bitSize)
+		if base64Err == nil {
+			return val, nil
+		}
+		val, decimalErr := strconv.ParseFloat(v, bitSize)
+		if decimalErr == nil {
+			return val, nil
+		}
+		if numError, ok := decimalErr.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return 0, decimalErr
+		}
+		return 0, base64Err
+	}
+
+	val, decimalErr := strconv.ParseFloat(v, bitSize)
+	if decimalErr == nil {
+		return val, nil
+	}
+	if numError, ok := decimalErr.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+		return 0, decimalErr
+	}
+	if val, err := base64FloatValue(v, bitSize); err == nil {
+		return val, nil
+	}
+	return 0, decimalErr
+}
+
+// parseFloatArrayParameter parses a JSON array of float parameters whose
+// elements are numbers or strings accepted by parseFloatParameter, e.g. the
+// value of a float array reading in eNotation or Base64 encoding.
+func parseFloatArrayParameter(v string, bitSize int, encoding string) ([]float64, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(v), &elements); err != nil {
+		return nil, err
+	}
+	if elements == nil {
+		return nil, nil
+	}
+
+	values := make([]float64, len(elements))
+	for i, element := range elements {
+		var str string
+		if err := json.Unmarshal(element, &str); err != nil {
+			str = string(element)
+		}
+		val, err := parseFloatParameter(str, bitSize, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid element %d: %v", i, err)
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+func base64FloatValue(v string, bitSize int) (float64, error) {
+	decodedToBytes, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return 0, err
+	}
+
+	var val float64
+	if bitSize == 32 {
+		var f float32
+		f, err = float32FromBytes(decodedToBytes)
+		val = float64(f)
+	} else {
+		val, err = float64FromBytes(decodedToBytes)
+	}
+	if err != nil {
+		return 0, err
+	} else if math.IsNaN(val) {
+		return 0, fmt.Errorf("fail to parse %v to float%d, unexpected result %v", v, bitSize, val)
+	}
+	return val, nil
+}
+
 func float64FromBytes(numericValue []byte) (res float64, err error) {
 	reader := bytes.NewReader(numericValue)
 	err = binary.Read(reader, binary.BigEndian, &res)
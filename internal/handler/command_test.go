@@ -8,6 +8,7 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
 	"strconv"
 	"strings"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
@@ -103,6 +105,63 @@ func TestParseWriteParamsNoParams(t *testing.T) {
 	}
 }
 
+func TestCreateCommandValueFromDR_object(t *testing.T) {
+	dr := &contract.DeviceResource{Name: "Position", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: dsModels.ValueTypeObject}}}
+
+	cv, err := createCommandValueFromDR(dr, `{"lat":51.5,"fix":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, dsModels.Object, cv.Type)
+	value, err := cv.ObjectValue()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"lat": 51.5, "fix": true}, value)
+
+	_, err = createCommandValueFromDR(dr, `{"lat":`)
+	assert.Error(t, err)
+}
+
+func TestCreateCommandValueFromDR_floatArray(t *testing.T) {
+	dr := &contract.DeviceResource{Name: "Temperatures", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: contract.ValueTypeFloat32Array}}}
+
+	for _, v := range []string{"[1.5,-0.25]", "[1.500000e+00,-2.500000e-01]", `["P8AAAA==","voAAAA=="]`, `["1.5","voAAAA=="]`} {
+		cv, err := createCommandValueFromDR(dr, v)
+		require.NoError(t, err, v)
+		value, err := cv.Float32ArrayValue()
+		require.NoError(t, err)
+		assert.Equal(t, []float32{1.5, -0.25}, value, v)
+	}
+
+	dr.Properties.Value.Type = contract.ValueTypeFloat64Array
+	cv, err := createCommandValueFromDR(dr, `["PoQh9fQNg3Y="]`)
+	require.NoError(t, err)
+	value, err := cv.Float64ArrayValue()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5e-7}, value)
+
+	_, err = createCommandValueFromDR(dr, `["hello"]`)
+	assert.Error(t, err)
+}
+
+func TestParseFloatParameter_encodingError(t *testing.T) {
+	_, err := parseFloatParameter("hello", 32, contract.ENotation)
+	assert.IsType(t, &strconv.NumError{}, err)
+	_, err = parseFloatParameter("hello", 32, contract.Base64Encoding)
+	assert.IsType(t, base64.CorruptInputError(0), err)
+}
+
+func TestParseFloatParameter_encoding(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte{0x3f, 0xc0, 0x00, 0x00})
+	for _, encoding := range []string{contract.ENotation, contract.Base64Encoding} {
+		val, err := parseFloatParameter(encoded, 32, encoding)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, val)
+		val, err = parseFloatParameter("1.5e0", 32, encoding)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, val)
+		_, err = parseFloatParameter("1e40", 32, encoding)
+		assert.Error(t, err)
+	}
+}
+
 func TestFilterOperationalDevices(t *testing.T) {
 	var (
 		devicesTotal2Unlocked2 = []contract.Device{{AdminState: contract.Unlocked}, {AdminState: contract.Unlocked}}
@@ -338,6 +397,32 @@ func TestExecReadCmd(t *testing.T) {
 	}
 }
 
+func TestReadValueAssertionFailKeepsMetadata(t *testing.T) {
+	dr, ok := cache.Profiles().DeviceResource(deviceIntegerGenerator.Profile.Name, "ResourceTestAssertion_Fail")
+	require.True(t, ok)
+	cv, _ := dsModels.NewInt8Value(dr.Name, 1, 42)
+	cv.Tags = map[string]string{"channel": "1"}
+	cv.Flags = []string{dsModels.FlagClamped}
+	cv.Quality = dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusClamped)
+
+	result, _ := readValue(&deviceIntegerGenerator, dr, cv, true, nil)
+
+	require.Equal(t, dsModels.String, result.Type)
+	assert.Contains(t, result.ValueToString(), "Assertion failed")
+	assert.Equal(t, cv.Tags, result.Tags)
+	assert.Equal(t, cv.Flags, result.Flags)
+	assert.Equal(t, cv.Quality, result.Quality)
+}
+
+func TestReadValueDecodeFail(t *testing.T) {
+	dr := contract.DeviceResource{Name: "RawValue_Int16", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: "Int16"}}}
+	cv, _ := dsModels.NewBinaryValue(dr.Name, 1, []byte{0x01})
+
+	_, ok := ReadValue(&deviceIntegerGenerator, dr, cv)
+
+	assert.False(t, ok, "a raw value too short for Int16 should fail the read pipeline")
+}
+
 func TestExecWriteCmd(t *testing.T) {
 	var (
 		paramsInt8                      = `{"RandomValue_Int8":"123"}`
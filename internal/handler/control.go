@@ -25,7 +25,7 @@ type discoveryLocker struct {
 
 var locker discoveryLocker
 
-func TransformHandler(requestMap map[string]string) (map[string]string, common.AppError) {
+func TransformDataHandler(requestMap map[string]string) (map[string]string, common.AppError) {
 	common.LoggingClient.Info(fmt.Sprintf("service: transform request: transformData: %s", requestMap["transformData"]))
 	return requestMap, nil
 }
@@ -0,0 +1,31 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/filter"
+)
+
+// DeadbandHandler returns the deadband filter state of all Devices, or of the
+// Device given by name.
+func DeadbandHandler(vars map[string]string) ([]filter.DeadbandState, common.AppError) {
+	name, ok := vars[common.NameVar]
+	if !ok {
+		return filter.Deadbands().All(), nil
+	}
+
+	if _, ok := cache.Devices().ForName(name); !ok {
+		msg := fmt.Sprintf("Device: %s not found", name)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewNotFoundError(msg, nil)
+	}
+	return filter.Deadbands().ForDevice(name), nil
+}
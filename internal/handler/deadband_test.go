@@ -0,0 +1,31 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+func TestDeadbandHandler(t *testing.T) {
+	states, appErr := DeadbandHandler(map[string]string{})
+	require.Nil(t, appErr)
+	assert.NotNil(t, states)
+
+	states, appErr = DeadbandHandler(map[string]string{common.NameVar: deviceIntegerGenerator.Name})
+	require.Nil(t, appErr)
+	assert.Empty(t, states)
+
+	_, appErr = DeadbandHandler(map[string]string{common.NameVar: "NotFound"})
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code())
+}
@@ -0,0 +1,207 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+const (
+	stepRaw         = "raw"
+	stepDecode      = "decode"
+	stepParameter   = "parameter"
+	stepTransform   = "transform"
+	stepAssertion   = "assertion"
+	stepMapping     = "mapping"
+	stepCommandVal  = "commandValue"
+	stepReading     = "reading"
+	stepDriverValue = "driverValue"
+)
+
+// TransformStep is the intermediate value after one stage of the read or write pipeline.
+type TransformStep struct {
+	Name      string   `json:"name"`
+	Value     string   `json:"value,omitempty"`
+	ValueType string   `json:"valueType,omitempty"`
+	Flags     []string `json:"flags,omitempty"`
+	Quality   string   `json:"quality,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// TransformResult is the outcome of a dry-run of the read or write pipeline
+// of a DeviceResource. The Device is never accessed.
+type TransformResult struct {
+	Device         string            `json:"device"`
+	DeviceResource string            `json:"deviceResource"`
+	Method         string            `json:"method"`
+	Steps          []TransformStep   `json:"steps"`
+	Reading        *contract.Reading `json:"reading,omitempty"`
+	Error          string            `json:"error,omitempty"`
+}
+
+func (r *TransformResult) addStep(name string, cv *dsModels.CommandValue, err error) {
+	step := TransformStep{Name: name}
+	if cv != nil {
+		step.Value = cv.ValueToString(contract.ENotation)
+		step.ValueType = cv.ValueTypeToString()
+		step.Flags = cv.Flags
+		if !cv.Quality.IsGood() {
+			step.Quality = cv.Quality.String()
+		}
+	}
+	if err != nil {
+		step.Error = err.Error()
+		if r.Error == "" {
+			r.Error = fmt.Sprintf("%s failed: %v", name, err)
+		}
+	}
+	r.Steps = append(r.Steps, step)
+}
+
+// TransformHandler runs the read pipeline (get) with the raw value given in the
+// query parameters, or the write pipeline (set) with the parameters given in
+// the body, for a DeviceResource without sending anything to the Driver.
+func TransformHandler(vars map[string]string, body string, method string, queryParams string) (*TransformResult, common.AppError) {
+	name := vars[common.NameVar]
+	drName := vars[common.CommandVar]
+
+	d, ok := cache.Devices().ForName(name)
+	if !ok {
+		msg := fmt.Sprintf("Device: %s not found; %s", name, method)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewNotFoundError(msg, nil)
+	}
+
+	dr, ok := cache.Profiles().DeviceResource(d.Profile.Name, drName)
+	if !ok {
+		msg := fmt.Sprintf("DeviceResource: %s for Device: %s not found; %s", drName, d.Name, method)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewNotFoundError(msg, nil)
+	}
+
+	if strings.ToLower(method) == common.GetCmdMethod {
+		m, err := url.ParseQuery(queryParams)
+		if err != nil || m.Get(common.ValueVar) == "" {
+			msg := fmt.Sprintf("no raw value specified for DeviceResource: %s; %s", dr.Name, method)
+			common.LoggingClient.Error(msg)
+			return nil, common.NewBadRequestError(msg, err)
+		}
+		return dryRunRead(&d, &dr, m.Get(common.ValueVar)), nil
+	}
+
+	paramMap, err := parseParams(body)
+	if err != nil {
+		msg := fmt.Sprintf("Put parameters parsing failed: %s", body)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewBadRequestError(msg, err)
+	}
+	p, ok := paramMap[dr.Name]
+	if !ok {
+		msg := fmt.Sprintf("there is no %s in parameters", dr.Name)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewBadRequestError(msg, nil)
+	}
+	return dryRunWrite(&d, &dr, p), nil
+}
+
+// dryRunRead runs the read pipeline on the raw value, it is the base64
+// encoded raw bytes for a DeviceResource with raw format attributes.
+func dryRunRead(device *contract.Device, dr *contract.DeviceResource, value string) *TransformResult {
+	result := &TransformResult{Device: device.Name, DeviceResource: dr.Name, Method: common.GetCmdMethod}
+
+	var cv *dsModels.CommandValue
+	var err error
+	if hasRawFormat(dr) && dsModels.ParseValueType(dr.Properties.Value.Type) != dsModels.Binary {
+		var data []byte
+		if data, err = base64.StdEncoding.DecodeString(value); err == nil {
+			cv, err = dsModels.NewBinaryValue(dr.Name, time.Now().UnixNano(), data)
+		}
+	} else {
+		cv, err = createCommandValueFromDR(dr, value)
+		if err == nil && cv == nil {
+			err = fmt.Errorf("value type %s is not supported", dr.Properties.Value.Type)
+		}
+	}
+	result.addStep(stepRaw, cv, err)
+	if err != nil {
+		return result
+	}
+
+	cv, ok := readValue(device, *dr, cv, true, result.addStep)
+	if !ok {
+		return result
+	}
+
+	reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
+	r := *reading
+	common.ReleaseReading(reading)
+	result.Steps = append(result.Steps, TransformStep{Name: stepReading, Value: r.Value, ValueType: r.ValueType})
+	result.Reading = &r
+	return result
+}
+
+// hasRawFormat reports whether the DeviceResource has raw format attributes.
+func hasRawFormat(dr *contract.DeviceResource) bool {
+	for _, name := range []string{dsModels.RawTypeAttribute, dsModels.ByteOrderAttribute, dsModels.WordOrderAttribute, dsModels.BitOffsetAttribute} {
+		if _, ok := dr.Attributes[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func dryRunWrite(device *contract.Device, dr *contract.DeviceResource, p string) *TransformResult {
+	result := &TransformResult{Device: device.Name, DeviceResource: dr.Name, Method: common.SetCmdMethod}
+	result.Steps = append(result.Steps, TransformStep{Name: stepParameter, Value: p})
+
+	ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, dr.Name, common.SetCmdMethod)
+	if err != nil {
+		ro = contract.ResourceOperation{DeviceResource: dr.Name}
+	}
+	newP, err := mapWriteParam(device.Profile.Name, ro, p)
+	if newP != p || err != nil {
+		step := TransformStep{Name: stepMapping, Value: newP}
+		if err != nil {
+			step.Error = err.Error()
+			result.Error = fmt.Sprintf("%s failed: %v", stepMapping, err)
+		}
+		result.Steps = append(result.Steps, step)
+		if err != nil {
+			return result
+		}
+	}
+
+	cv, err := createCommandValueFromDR(dr, newP)
+	if err == nil && cv == nil {
+		err = fmt.Errorf("value type %s is not supported", dr.Properties.Value.Type)
+	}
+	result.addStep(stepCommandVal, cv, err)
+	if err != nil {
+		return result
+	}
+
+	if common.CurrentConfig.Device.DataTransform {
+		err = transformer.TransformWrite(cv, *dr)
+		result.addStep(stepTransform, cv, err)
+		if err != nil {
+			return result
+		}
+	}
+
+	result.addStep(stepDriverValue, cv, nil)
+	return result
+}
@@ -0,0 +1,95 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestTransformHandler(t *testing.T) {
+	tests := []struct {
+		testName    string
+		device      string
+		resource    string
+		method      string
+		body        string
+		queryParams string
+		code        int
+		lastStep    string
+		lastValue   string
+		failed      bool
+	}{
+		{"ReadMapping", deviceIntegerGenerator.Name, "ResourceTestMapping_Pass", methodGet, "", "value=123", http.StatusOK, stepReading, "Pass", false},
+		{"ReadAssertionFail", deviceIntegerGenerator.Name, "ResourceTestAssertion_Fail", methodGet, "", "value=123", http.StatusOK, stepReading, "", true},
+		{"ReadInvalidRawValue", deviceIntegerGenerator.Name, "RandomValue_Int8", methodGet, "", "value=abc", http.StatusOK, stepRaw, "", true},
+		{"ReadNoRawValue", deviceIntegerGenerator.Name, "RandomValue_Int8", methodGet, "", "", http.StatusBadRequest, "", "", false},
+		{"WriteMapping", deviceIntegerGenerator.Name, "ResourceTestMapping_Pass", methodSet, `{"ResourceTestMapping_Pass":"Pass"}`, "", http.StatusOK, stepDriverValue, "123", false},
+		{"WriteNoParameter", deviceIntegerGenerator.Name, "ResourceTestMapping_Pass", methodSet, `{"NotFound":"Pass"}`, "", http.StatusBadRequest, "", "", false},
+		{"DeviceNotFound", "NotFound", "RandomValue_Int8", methodGet, "", "value=123", http.StatusNotFound, "", "", false},
+		{"DeviceResourceNotFound", deviceIntegerGenerator.Name, "NotFound", methodGet, "", "value=123", http.StatusNotFound, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			vars := map[string]string{common.NameVar: tt.device, common.CommandVar: tt.resource}
+			result, appErr := TransformHandler(vars, tt.body, tt.method, tt.queryParams)
+			if tt.code != http.StatusOK {
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.code, appErr.Code())
+				return
+			}
+			require.Nil(t, appErr)
+			require.NotEmpty(t, result.Steps)
+			last := result.Steps[len(result.Steps)-1]
+			assert.Equal(t, tt.lastStep, last.Name)
+			if tt.lastValue != "" {
+				assert.Equal(t, tt.lastValue, last.Value)
+			}
+			assert.Equal(t, tt.failed, result.Error != "")
+		})
+	}
+}
+
+func TestTransformHandlerDoesNotDisableDevice(t *testing.T) {
+	device := deviceIntegerGenerator
+	device.OperatingState = contract.Enabled
+	require.NoError(t, cache.Devices().Update(device))
+	vars := map[string]string{common.NameVar: device.Name, common.CommandVar: "ResourceTestAssertion_Fail"}
+
+	_, appErr := TransformHandler(vars, "", methodGet, "value=123")
+
+	require.Nil(t, appErr)
+	d, ok := cache.Devices().ForName(device.Name)
+	require.True(t, ok)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), d.OperatingState)
+}
+
+func TestDryRunReadRawValue(t *testing.T) {
+	device := deviceIntegerGenerator
+	dr := contract.DeviceResource{Name: "RawValue_Int16", Attributes: map[string]string{dsModels.ByteOrderAttribute: dsModels.LittleEndian},
+		Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: "Int16"}}}
+
+	result := dryRunRead(&device, &dr, base64.StdEncoding.EncodeToString([]byte{0x02, 0x01}))
+
+	require.Empty(t, result.Error)
+	require.True(t, len(result.Steps) >= 3)
+	assert.Equal(t, stepRaw, result.Steps[0].Name)
+	assert.Equal(t, stepDecode, result.Steps[1].Name)
+	assert.Equal(t, "258", result.Steps[1].Value)
+	require.NotNil(t, result.Reading)
+	assert.Equal(t, "258", result.Reading.Value)
+	assert.Equal(t, dr.Name, result.Reading.Name)
+}
@@ -68,10 +68,13 @@ func (DriverMock) HandleReadCommands(deviceName string, protocols map[string]con
 }
 
 func (DriverMock) HandleWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
-	for _, req := range reqs {
+	for i, req := range reqs {
 		if req.DeviceResourceName == "Error" {
 			return fmt.Errorf("error occurred in HandleReadCommands")
 		}
+		if req.Type != params[i].Type {
+			return fmt.Errorf("request Type %v of %s doesn't match the parameter Type %v", req.Type, req.DeviceResourceName, params[i].Type)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,260 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pipeline sends the events with a bounded number of workers, so that
+// a slow Core Data or message bus doesn't pile up goroutines.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// Backpressure policies if the queue is full.
+const (
+	// Block waits until a worker takes an event.
+	Block = "block"
+	// DropOldest drops the event waiting longest.
+	DropOldest = "dropOldest"
+	// DropNewest drops the new event.
+	DropNewest = "dropNewest"
+
+	// DefaultWorkers and DefaultQueueSize are used if the
+	// Device.EventPipeline settings are 0.
+	DefaultWorkers   = 4
+	DefaultQueueSize = 128
+)
+
+var (
+	pl      *Pipeline
+	plMutex sync.RWMutex
+)
+
+// item is a queued event.
+type item struct {
+	event  *dsModels.Event
+	queued time.Time
+}
+
+// Pipeline queues the events and sends them with its workers.
+type Pipeline struct {
+	workers      int
+	backpressure string
+	queue        chan item
+	// send sends an event, common.SendEvent by default.
+	send func(event *dsModels.Event) error
+	done <-chan struct{}
+
+	// stopMutex is held by Send while it queues an event, stopped is set
+	// once ctx is done so that no event is queued after the workers drained
+	// the queue.
+	stopMutex sync.RWMutex
+	stopped   bool
+
+	mutex        sync.Mutex
+	sent         uint64
+	failed       uint64
+	dropped      uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}
+
+// Init creates the Pipeline of the Device.EventPipeline configuration and
+// starts its workers, which stop when ctx is done. Concurrent workers could
+// deliver an event before one which is still being queued by the
+// EventForwarder, so a single worker is used if there is one.
+func Init(ctx context.Context, wg *sync.WaitGroup) error {
+	p, err := NewPipeline(common.CurrentConfig.Device.EventPipeline)
+	if err != nil {
+		return err
+	}
+	if common.EventForwarder != nil && p.workers > 1 {
+		common.LoggingClient.Info(fmt.Sprintf("Device.StoreAndForward is enabled, sending the events with 1 worker instead of %d to keep their order", p.workers))
+		p.workers = 1
+	}
+	p.Start(ctx, wg)
+	plMutex.Lock()
+	pl = p
+	plMutex.Unlock()
+	return nil
+}
+
+// NewPipeline creates the Pipeline of the configuration.
+func NewPipeline(config common.EventPipelineInfo) (*Pipeline, error) {
+	p := &Pipeline{workers: config.Workers, backpressure: Block, send: common.SendEvent}
+	if p.workers == 0 {
+		p.workers = DefaultWorkers
+	}
+	size := config.QueueSize
+	if size == 0 {
+		size = DefaultQueueSize
+	}
+	if p.workers < 0 || size < 0 {
+		return nil, fmt.Errorf("invalid Device.EventPipeline.Workers %d or QueueSize %d", config.Workers, config.QueueSize)
+	}
+	p.queue = make(chan item, size)
+	switch {
+	case config.Backpressure == "" || strings.EqualFold(config.Backpressure, Block):
+	case strings.EqualFold(config.Backpressure, DropOldest):
+		p.backpressure = DropOldest
+	case strings.EqualFold(config.Backpressure, DropNewest):
+		p.backpressure = DropNewest
+	default:
+		return nil, fmt.Errorf("invalid Device.EventPipeline.Backpressure %s", config.Backpressure)
+	}
+	return p, nil
+}
+
+// Start starts the workers, they send the events still queued when ctx is
+// done and stop.
+func (p *Pipeline) Start(ctx context.Context, wg *sync.WaitGroup) {
+	p.done = ctx.Done()
+	stopped := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		p.stopMutex.Lock()
+		p.stopped = true
+		p.stopMutex.Unlock()
+		close(stopped)
+	}()
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case it := <-p.queue:
+					p.process(it)
+				case <-stopped:
+					for {
+						select {
+						case it := <-p.queue:
+							p.process(it)
+						default:
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+}
+
+// Send queues the event. If the queue is full it waits for room, or drops an
+// event according to the backpressure policy. The event is dropped if the
+// Pipeline is stopped.
+func (p *Pipeline) Send(event *dsModels.Event) {
+	p.stopMutex.RLock()
+	defer p.stopMutex.RUnlock()
+	if p.stopped {
+		p.drop(event, "stopped")
+		return
+	}
+
+	it := item{event: event, queued: time.Now()}
+	select {
+	case p.queue <- it:
+		return
+	default:
+	}
+
+	switch p.backpressure {
+	case DropNewest:
+		p.drop(event, "full")
+	case DropOldest:
+		for {
+			select {
+			case p.queue <- it:
+				return
+			default:
+			}
+			select {
+			case old := <-p.queue:
+				p.drop(old.event, "full")
+			default:
+			}
+		}
+	default:
+		select {
+		case p.queue <- it:
+		case <-p.done:
+			p.drop(event, "stopped")
+		}
+	}
+}
+
+func (p *Pipeline) process(it item) {
+	err := p.send(it.event)
+	latency := time.Since(it.queued)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err != nil {
+		p.failed++
+		return
+	}
+	p.sent++
+	p.totalLatency += latency
+	if latency > p.maxLatency {
+		p.maxLatency = latency
+	}
+}
+
+func (p *Pipeline) drop(event *dsModels.Event, reason string) {
+	common.LoggingClient.Warn(fmt.Sprintf("Event pipeline is %s, dropping an event of Device %s", reason, event.Device))
+	p.mutex.Lock()
+	p.dropped++
+	p.mutex.Unlock()
+}
+
+// Metrics returns the statistics of the Pipeline.
+func (p *Pipeline) Metrics() common.EventPipelineMetrics {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	m := common.EventPipelineMetrics{
+		Queued:     len(p.queue),
+		Sent:       p.sent,
+		Failed:     p.failed,
+		Dropped:    p.dropped,
+		MaxLatency: p.maxLatency,
+	}
+	if p.sent > 0 {
+		m.Latency = p.totalLatency / time.Duration(p.sent)
+	}
+	return m
+}
+
+// Send sends the event with the Pipeline, or in a new goroutine if the
+// Pipeline is not started.
+func Send(event *dsModels.Event) {
+	plMutex.RLock()
+	p := pl
+	plMutex.RUnlock()
+	if p == nil {
+		go common.SendEvent(event)
+		return
+	}
+	p.Send(event)
+}
+
+// Metrics returns the statistics of the Pipeline, it returns false if the
+// Pipeline is not started.
+func Metrics() (common.EventPipelineMetrics, bool) {
+	plMutex.RLock()
+	p := pl
+	plMutex.RUnlock()
+	if p == nil {
+		return common.EventPipelineMetrics{}, false
+	}
+	return p.Metrics(), true
+}
@@ -0,0 +1,175 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func init() {
+	common.LoggingClient = logger.MockLogger{}
+}
+
+// sink is a send stand-in which blocks until it is released and records the
+// sent events by Device name.
+type sink struct {
+	mutex   sync.Mutex
+	release chan struct{}
+	sent    []string
+}
+
+func newSink() *sink {
+	return &sink{release: make(chan struct{})}
+}
+
+func (s *sink) send(event *dsModels.Event) error {
+	<-s.release
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sent = append(s.sent, event.Device)
+	if event.Device == "fail" {
+		return errors.New("unreachable")
+	}
+	return nil
+}
+
+func (s *sink) devices() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.sent...)
+}
+
+func event(device string) *dsModels.Event {
+	return &dsModels.Event{Event: contract.Event{Device: device}}
+}
+
+// start starts a Pipeline with a single worker, which takes the first event
+// and blocks sending it until the sink is released. The returned func stops
+// the Pipeline.
+func start(t *testing.T, backpressure string) (*Pipeline, *sink, func()) {
+	p, err := NewPipeline(common.EventPipelineInfo{Workers: 1, QueueSize: 2, Backpressure: backpressure})
+	if err != nil {
+		t.Fatalf("Failed to create the pipeline: %v", err)
+	}
+	s := newSink()
+	p.send = s.send
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	p.Start(ctx, wg)
+
+	p.Send(event("busy"))
+	deadline := time.Now().Add(time.Second)
+	for len(p.queue) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return p, s, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func TestNewPipeline(t *testing.T) {
+	invalid := []common.EventPipelineInfo{
+		{Workers: -1},
+		{QueueSize: -1},
+		{Backpressure: "dropRandom"},
+	}
+	for _, config := range invalid {
+		if _, err := NewPipeline(config); err == nil {
+			t.Fatalf("Expected an error for configuration %+v", config)
+		}
+	}
+
+	p, err := NewPipeline(common.EventPipelineInfo{Backpressure: "DROPOLDEST"})
+	if err != nil {
+		t.Fatalf("Failed to create the pipeline: %v", err)
+	}
+	if p.workers != DefaultWorkers || cap(p.queue) != DefaultQueueSize || p.backpressure != DropOldest {
+		t.Fatalf("Unexpected pipeline with %d workers, queue size %d and backpressure %s", p.workers, cap(p.queue), p.backpressure)
+	}
+}
+
+func TestDropNewest(t *testing.T) {
+	p, s, stop := start(t, DropNewest)
+	for _, d := range []string{"a", "b", "c", "d"} {
+		p.Send(event(d))
+	}
+	if m := p.Metrics(); m.Queued != 2 || m.Dropped != 2 {
+		t.Fatalf("Unexpected metrics %+v", m)
+	}
+	close(s.release)
+	stop()
+	if sent := s.devices(); len(sent) != 3 || sent[1] != "a" || sent[2] != "b" {
+		t.Fatalf("Unexpected sent events %v", sent)
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	p, s, stop := start(t, DropOldest)
+	for _, d := range []string{"a", "b", "c", "d"} {
+		p.Send(event(d))
+	}
+	if m := p.Metrics(); m.Queued != 2 || m.Dropped != 2 {
+		t.Fatalf("Unexpected metrics %+v", m)
+	}
+	close(s.release)
+	stop()
+	if sent := s.devices(); len(sent) != 3 || sent[1] != "c" || sent[2] != "d" {
+		t.Fatalf("Unexpected sent events %v", sent)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	p, s, stop := start(t, Block)
+	p.Send(event("a"))
+	p.Send(event("b"))
+
+	sent := make(chan struct{})
+	go func() {
+		p.Send(event("fail"))
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		t.Fatalf("Expected Send to block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(s.release)
+	<-sent
+	stop()
+	if devices := s.devices(); len(devices) != 4 || devices[3] != "fail" {
+		t.Fatalf("Unexpected sent events %v", devices)
+	}
+	m := p.Metrics()
+	if m.Queued != 0 || m.Sent != 3 || m.Failed != 1 || m.Dropped != 0 || m.Latency <= 0 || m.MaxLatency < m.Latency {
+		t.Fatalf("Unexpected metrics %+v", m)
+	}
+}
+
+func TestSendAfterStop(t *testing.T) {
+	p, s, stop := start(t, Block)
+	close(s.release)
+	stop()
+
+	p.Send(event("late"))
+	if m := p.Metrics(); m.Queued != 0 || m.Sent != 1 || m.Dropped != 1 {
+		t.Fatalf("Unexpected metrics %+v", m)
+	}
+	if devices := s.devices(); len(devices) != 1 {
+		t.Fatalf("Unexpected sent events %v", devices)
+	}
+}
@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package publisher
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// disconnectQuiesce is how long, in milliseconds, closing the client waits
+// for the messages in flight.
+const disconnectQuiesce = 250
+
+// mqttClient publishes to an MQTT broker with the Eclipse Paho client. It
+// connects on the first publish and reconnects on the next publish after the
+// connection was lost.
+type mqttClient struct {
+	options *mqtt.ClientOptions
+	qos     byte
+	retain  bool
+	timeout time.Duration
+
+	mutex  sync.Mutex
+	client mqtt.Client
+}
+
+// newMQTTClient creates the Client of TypeMQTT.
+func newMQTTClient(config common.MessageQueueInfo) (Client, error) {
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	options := mqtt.NewClientOptions()
+	switch strings.ToLower(config.Protocol) {
+	case "", "tcp":
+		options.AddBroker("tcp://" + addr)
+	case "tls", "ssl":
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		options.AddBroker("ssl://" + addr)
+		options.SetTLSConfig(tlsConfig)
+	default:
+		return nil, fmt.Errorf("unsupported MessageQueue.Protocol %s", config.Protocol)
+	}
+	if config.Qos < 0 || config.Qos > 1 {
+		return nil, fmt.Errorf("unsupported MessageQueue.Qos %d, it must be 0 or 1", config.Qos)
+	}
+
+	c := &mqttClient{options: options, qos: byte(config.Qos), retain: config.Retained, timeout: DefaultConnectTimeout}
+	var keepAlive time.Duration
+	var err error
+	if config.KeepAlive != "" {
+		// the Paho client checks the connection every KeepAlive/2 seconds
+		keepAlive, err = time.ParseDuration(config.KeepAlive)
+		if err != nil || keepAlive < 0 || (keepAlive > 0 && keepAlive < 2*time.Second) || keepAlive/time.Second > 65535 {
+			return nil, fmt.Errorf("invalid MessageQueue.KeepAlive %s", config.KeepAlive)
+		}
+	}
+	if config.ConnectTimeout != "" {
+		c.timeout, err = time.ParseDuration(config.ConnectTimeout)
+		if err != nil || c.timeout <= 0 {
+			return nil, fmt.Errorf("invalid MessageQueue.ConnectTimeout %s", config.ConnectTimeout)
+		}
+	}
+
+	options.SetClientID(config.ClientId)
+	if config.Username != "" {
+		options.SetUsername(config.Username)
+		options.SetPassword(config.Password)
+	}
+	options.SetProtocolVersion(4)
+	options.SetCleanSession(true)
+	options.SetAutoReconnect(false)
+	options.SetKeepAlive(keepAlive)
+	options.SetPingTimeout(c.timeout)
+	options.SetConnectTimeout(c.timeout)
+	options.SetWriteTimeout(c.timeout)
+	return c, nil
+}
+
+// connect connects to the broker unless the client is connected, the mutex
+// must be held.
+func (c *mqttClient) connect() error {
+	if c.client != nil && c.client.IsConnectionOpen() {
+		return nil
+	}
+	client := mqtt.NewClient(c.options)
+	if err := wait(client.Connect(), c.timeout, "connecting to the broker"); err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+// Publish publishes the payload to the topic, with QoS 1 it waits for the
+// PUBACK.
+func (c *mqttClient) Publish(topic string, payload []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.connect(); err != nil {
+		return err
+	}
+	err := wait(c.client.Publish(topic, c.qos, c.retain, payload), c.timeout, "publishing")
+	if err != nil {
+		// the next publish reconnects
+		c.client.Disconnect(0)
+		c.client = nil
+	}
+	return err
+}
+
+// Close disconnects from the broker.
+func (c *mqttClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil && c.client.IsConnectionOpen() {
+		c.client.Disconnect(disconnectQuiesce)
+	}
+	c.client = nil
+	return nil
+}
+
+// wait waits for the token to complete within the timeout.
+func wait(token mqtt.Token, timeout time.Duration, action string) error {
+	if !token.WaitTimeout(timeout) {
+		return fmt.Errorf("%s timed out after %v", action, timeout)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("%s failed: %v", action, err)
+	}
+	return nil
+}
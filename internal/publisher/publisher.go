@@ -0,0 +1,227 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package publisher publishes events to a message bus instead of posting them
+// to Core Data, according to the MessageQueue configuration.
+package publisher
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+)
+
+const (
+	// TypeREST posts the events to Core Data.
+	TypeREST = "rest"
+	// TypeMQTT publishes the events to an MQTT broker.
+	TypeMQTT = "mqtt"
+
+	// DefaultTopic is the topic template if the MessageQueue.Topic setting
+	// is empty.
+	DefaultTopic = "edgex/events/{profile}/{device}/{command}"
+	// DefaultConnectTimeout is used if the MessageQueue.ConnectTimeout
+	// setting is empty.
+	DefaultConnectTimeout = 5 * time.Second
+)
+
+// envelope is the message published for an event, it has the layout of the
+// EdgeX message envelope so that application services can receive it. As
+// Core Data does, the Checksum is only set for CBOR events, application
+// services mark those events as pushed by it.
+type envelope struct {
+	Checksum      string `json:",omitempty"`
+	CorrelationID string
+	Payload       []byte
+	ContentType   string
+}
+
+// Publisher publishes events to a topic given by a template.
+type Publisher interface {
+	common.Publisher
+	// Close disconnects from the message bus.
+	Close() error
+}
+
+// Client sends messages to a message bus, it's the part of a Publisher which
+// depends on the MessageQueue Type.
+type Client interface {
+	// Publish sends the payload to the topic.
+	Publish(topic string, payload []byte) error
+	// Close disconnects from the message bus.
+	Close() error
+}
+
+// ClientFactory creates the Client of a MessageQueue configuration, the
+// ClientId of the configuration is set.
+type ClientFactory func(config common.MessageQueueInfo) (Client, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = map[string]ClientFactory{TypeMQTT: newMQTTClient}
+)
+
+// RegisterClient registers the ClientFactory of a MessageQueue Type, which is
+// matched case-insensitively. It replaces the factory registered before for
+// the Type, TypeREST can't be registered.
+func RegisterClient(typ string, factory ClientFactory) error {
+	typ = strings.ToLower(typ)
+	if typ == "" || typ == TypeREST {
+		return fmt.Errorf("the MessageQueue.Type %s can't be registered", typ)
+	}
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	factories[typ] = factory
+	return nil
+}
+
+type publisher struct {
+	client Client
+	topic  string
+}
+
+// Init creates the Publisher of the MessageQueue configuration and sets it as
+// common.EventPublisher, events are posted to Core Data if the Type is empty
+// or TypeREST. The Publisher is closed when ctx is done.
+func Init(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.MessageQueue
+	p, err := NewPublisher(config, common.ServiceName)
+	if err != nil || p == nil {
+		return err
+	}
+	common.EventPublisher = p
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		if err := p.Close(); err != nil {
+			common.LoggingClient.Warn(fmt.Sprintf("Publisher - disconnecting from the message bus failed: %v", err))
+		}
+	}()
+	common.LoggingClient.Info(fmt.Sprintf("Publishing events to the %s message bus %s", config.Type,
+		net.JoinHostPort(config.Host, strconv.Itoa(config.Port))))
+	return nil
+}
+
+// NewPublisher creates the Publisher of the configuration with the Client
+// registered for its Type, it returns nil if the events are posted to Core
+// Data. The clientID is used if the configuration has none.
+func NewPublisher(config common.MessageQueueInfo, clientID string) (Publisher, error) {
+	typ := strings.ToLower(config.Type)
+	if typ == "" || typ == TypeREST {
+		return nil, nil
+	}
+	factoriesMutex.RLock()
+	factory, ok := factories[typ]
+	factoriesMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported MessageQueue.Type %s", config.Type)
+	}
+
+	if config.ClientId == "" {
+		config.ClientId = clientID
+	}
+	client, err := factory(config)
+	if err != nil {
+		return nil, err
+	}
+	topic := config.Topic
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	return &publisher{client: client, topic: topic}, nil
+}
+
+// newTLSConfig creates the TLS configuration of the connection to the broker.
+// The certificates of the CaFile replace the system roots, the client
+// certificate is loaded if the CertFile or KeyFile is set.
+func newTLSConfig(config common.MessageQueueInfo) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: config.Host}
+	if config.CaFile != "" {
+		pem, err := ioutil.ReadFile(config.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MessageQueue.CaFile %s: %v", config.CaFile, err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in MessageQueue.CaFile %s", config.CaFile)
+		}
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MessageQueue.CertFile %s and KeyFile %s: %v", config.CertFile, config.KeyFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Publish publishes the encoded event in an envelope to the topic of the
+// event.
+func (p *publisher) Publish(ctx context.Context, event *dsModels.Event) error {
+	env := envelope{
+		CorrelationID: clients.FromContext(ctx, clients.CorrelationHeader),
+		Payload:       event.EncodedEvent,
+		ContentType:   clients.FromContext(ctx, clients.ContentType),
+	}
+	if env.ContentType == clients.ContentTypeCBOR {
+		env.Checksum = fmt.Sprintf("%x", sha256.Sum256(event.EncodedEvent))
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(Topic(p.topic, event), payload)
+}
+
+func (p *publisher) Close() error {
+	return p.client.Close()
+}
+
+// Topic expands the placeholders of the topic template for the event.
+func Topic(template string, event *dsModels.Event) string {
+	var profile string
+	if d, ok := cache.Devices().ForName(event.Device); ok {
+		profile = d.Profile.Name
+	}
+	return expandTopic(template, common.ServiceName, profile, event)
+}
+
+// expandTopic expands the placeholders of the topic template. The command is
+// the name of the first reading if the event has none. Characters with a
+// meaning in topics, i.e. "/", "+" and "#", are replaced by "_" in the names.
+func expandTopic(template string, serviceName string, profile string, event *dsModels.Event) string {
+	cmd := event.Command
+	if cmd == "" && len(event.Readings) > 0 {
+		cmd = event.Readings[0].Name
+	}
+	return strings.NewReplacer(
+		"{service}", topicLevel(serviceName),
+		"{profile}", topicLevel(profile),
+		"{device}", topicLevel(event.Device),
+		"{command}", topicLevel(cmd),
+	).Replace(template)
+}
+
+func topicLevel(name string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(name)
+}
@@ -0,0 +1,440 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package publisher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func init() {
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.LoggingClient = logger.MockLogger{}
+	cache.InitCache()
+}
+
+// message is a PUBLISH received by the broker stand-in.
+type message struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// broker is an in-process MQTT broker stand-in which acknowledges the
+// connections, publishes and pings and records the published messages.
+type broker struct {
+	listener   net.Listener
+	returnCode byte
+	// drops is the number of connections closed after their first PUBLISH
+	// without acknowledging it.
+	drops     int32
+	clientIDs chan string
+	messages  chan message
+	pings     chan struct{}
+}
+
+func newBroker(t *testing.T, returnCode byte, drops int32) *broker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	return startBroker(l, returnCode, drops)
+}
+
+func startBroker(l net.Listener, returnCode byte, drops int32) *broker {
+	b := &broker{listener: l, returnCode: returnCode, drops: drops,
+		clientIDs: make(chan string, 10), messages: make(chan message, 10), pings: make(chan struct{}, 10)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *broker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			b.clientIDs <- p.ClientIdentifier
+			ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+			ack.ReturnCode = b.returnCode
+			_ = ack.Write(conn)
+		case *packets.PublishPacket:
+			b.messages <- message{topic: p.TopicName, qos: p.Qos, retained: p.Retain, payload: p.Payload}
+			if atomic.AddInt32(&b.drops, -1) >= 0 {
+				return
+			}
+			if p.Qos > 0 {
+				ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				ack.MessageID = p.MessageID
+				_ = ack.Write(conn)
+			}
+		case *packets.PingreqPacket:
+			b.pings <- struct{}{}
+			_ = packets.NewControlPacket(packets.Pingresp).Write(conn)
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+func (b *broker) config(qos int) common.MessageQueueInfo {
+	addr := b.listener.Addr().(*net.TCPAddr)
+	return common.MessageQueueInfo{Type: TypeMQTT, Host: addr.IP.String(), Port: addr.Port,
+		Topic: DefaultTopic, Qos: qos, ConnectTimeout: "1s"}
+}
+
+func (b *broker) message(t *testing.T) message {
+	select {
+	case m := <-b.messages:
+		return m
+	case <-time.After(time.Second):
+		t.Fatalf("No message received")
+		return message{}
+	}
+}
+
+func testEvent() (context.Context, *dsModels.Event) {
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, "correlation")
+	ctx = context.WithValue(ctx, clients.ContentType, clients.ContentTypeJSON)
+	event := &dsModels.Event{Event: contract.Event{Device: "Random-Boolean-Generator01", Readings: []contract.Reading{{Name: "RandomValue_Bool", Value: "true"}}}}
+	event.EncodedEvent = []byte(`{"device":"Random-Boolean-Generator01"}`)
+	return ctx, event
+}
+
+func TestNewPublisher(t *testing.T) {
+	for _, typ := range []string{"", "rest", "REST"} {
+		p, err := NewPublisher(common.MessageQueueInfo{Type: typ}, "service")
+		if err != nil || p != nil {
+			t.Fatalf("Unexpected publisher %v with error %v for type %s", p, err, typ)
+		}
+	}
+
+	invalid := []common.MessageQueueInfo{
+		{Type: "amqp"},
+		{Type: TypeMQTT, Protocol: "udp"},
+		{Type: TypeMQTT, Qos: 2},
+		{Type: TypeMQTT, KeepAlive: "forever"},
+		{Type: TypeMQTT, KeepAlive: "24h"},
+		{Type: TypeMQTT, KeepAlive: "1s"},
+		{Type: TypeMQTT, ConnectTimeout: "0s"},
+		{Type: TypeMQTT, Protocol: "tls", CaFile: "missing.pem"},
+		{Type: TypeMQTT, Protocol: "tls", CertFile: "missing.pem"},
+	}
+	for _, config := range invalid {
+		if _, err := NewPublisher(config, "service"); err == nil {
+			t.Fatalf("Expected an error for configuration %+v", config)
+		}
+	}
+
+	p, err := NewPublisher(common.MessageQueueInfo{Type: TypeMQTT, Host: "localhost", Port: 1883, Qos: 1, KeepAlive: "30s"}, "service")
+	if err != nil {
+		t.Fatalf("Failed to create the publisher: %v", err)
+	}
+	mp := p.(*publisher)
+	c := mp.client.(*mqttClient)
+	if mp.topic != DefaultTopic || c.options.ClientID != "service" || len(c.options.Servers) != 1 || c.options.Servers[0].String() != "tcp://localhost:1883" ||
+		c.qos != 1 || c.options.KeepAlive != 30 || c.timeout != DefaultConnectTimeout {
+		t.Fatalf("Unexpected publisher %+v with client %+v", mp, c)
+	}
+}
+
+// recorder is a Client recording the published messages.
+type recorder struct {
+	config   common.MessageQueueInfo
+	messages []message
+}
+
+func (r *recorder) Publish(topic string, payload []byte) error {
+	r.messages = append(r.messages, message{topic: topic, payload: payload})
+	return nil
+}
+
+func (r *recorder) Close() error {
+	return nil
+}
+
+func TestRegisterClient(t *testing.T) {
+	for _, typ := range []string{"", "REST"} {
+		if err := RegisterClient(typ, nil); err == nil {
+			t.Fatalf("Expected an error registering the type %s", typ)
+		}
+	}
+
+	r := &recorder{}
+	err := RegisterClient("Recorder", func(config common.MessageQueueInfo) (Client, error) {
+		r.config = config
+		return r, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to register the client: %v", err)
+	}
+	defer func() {
+		factoriesMutex.Lock()
+		delete(factories, "recorder")
+		factoriesMutex.Unlock()
+	}()
+
+	p, err := NewPublisher(common.MessageQueueInfo{Type: "recorder", Topic: "{service}/{device}"}, "service")
+	if err != nil {
+		t.Fatalf("Failed to create the publisher: %v", err)
+	}
+	ctx, event := testEvent()
+	if err := p.Publish(ctx, event); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if r.config.ClientId != "service" || len(r.messages) != 1 || r.messages[0].topic != "/Random-Boolean-Generator01" {
+		t.Fatalf("Unexpected client configuration %+v or messages %+v", r.config, r.messages)
+	}
+}
+
+func TestExpandTopic(t *testing.T) {
+	event := &dsModels.Event{Event: contract.Event{Device: "room/1", Readings: []contract.Reading{{Name: "Temperature"}}}}
+	if topic := expandTopic(DefaultTopic, "service", "sensor", event); topic != "edgex/events/sensor/room_1/Temperature" {
+		t.Fatalf("Unexpected topic %s", topic)
+	}
+	event.Command = "Climate#1"
+	if topic := expandTopic("{service}/{device}/{command}", "service", "sensor", event); topic != "service/room_1/Climate_1" {
+		t.Fatalf("Unexpected topic %s", topic)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	for _, qos := range []int{0, 1} {
+		b := newBroker(t, 0, 0)
+		config := b.config(qos)
+		config.ClientId = "client"
+		config.Retained = true
+		p, err := NewPublisher(config, "service")
+		if err != nil {
+			t.Fatalf("Failed to create the publisher: %v", err)
+		}
+
+		ctx, event := testEvent()
+		if err := p.Publish(ctx, event); err != nil {
+			t.Fatalf("Failed to publish with QoS %d: %v", qos, err)
+		}
+		if id := <-b.clientIDs; id != "client" {
+			t.Fatalf("Unexpected client id %s", id)
+		}
+		m := b.message(t)
+		if m.topic != "edgex/events/Random-Boolean-Generator/Random-Boolean-Generator01/RandomValue_Bool" || m.qos != byte(qos) || !m.retained {
+			t.Fatalf("Unexpected message %+v with QoS %d", m, qos)
+		}
+		var env envelope
+		if err := json.Unmarshal(m.payload, &env); err != nil {
+			t.Fatalf("Failed to decode the envelope: %v", err)
+		}
+		if env.CorrelationID != "correlation" || env.ContentType != clients.ContentTypeJSON || string(env.Payload) != string(event.EncodedEvent) || env.Checksum != "" {
+			t.Fatalf("Unexpected envelope %+v", env)
+		}
+
+		if err := p.Publish(ctx, event); err != nil {
+			t.Fatalf("Failed to publish again with QoS %d: %v", qos, err)
+		}
+		b.message(t)
+		if len(b.clientIDs) != 0 {
+			t.Fatalf("Expected the connection to be reused")
+		}
+		if err := p.Close(); err != nil {
+			t.Fatalf("Failed to close the publisher: %v", err)
+		}
+		b.listener.Close()
+	}
+}
+
+func TestPublishReconnects(t *testing.T) {
+	b := newBroker(t, 0, 1)
+	defer b.listener.Close()
+	p, err := NewPublisher(b.config(1), "service")
+	if err != nil {
+		t.Fatalf("Failed to create the publisher: %v", err)
+	}
+	ctx, event := testEvent()
+	if err := p.Publish(ctx, event); err == nil {
+		t.Fatalf("Expected an error for the unacknowledged publish")
+	}
+	b.message(t)
+
+	if err := p.Publish(ctx, event); err != nil {
+		t.Fatalf("Failed to publish after reconnecting: %v", err)
+	}
+	b.message(t)
+	if len(b.clientIDs) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(b.clientIDs))
+	}
+}
+
+func TestPublishRefused(t *testing.T) {
+	b := newBroker(t, 5, 0)
+	defer b.listener.Close()
+	p, err := NewPublisher(b.config(0), "service")
+	if err != nil {
+		t.Fatalf("Failed to create the publisher: %v", err)
+	}
+	ctx, event := testEvent()
+	if err := p.Publish(ctx, event); err == nil || !strings.Contains(err.Error(), packets.ConnErrors[packets.ErrRefusedNotAuthorised].Error()) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	config := b.config(0)
+	config.Port = port
+	p, _ = NewPublisher(config, "service")
+	if err := p.Publish(ctx, event); err == nil {
+		t.Fatalf("Expected an error publishing to port %s without broker", strconv.Itoa(port))
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	b := newBroker(t, 0, 0)
+	defer b.listener.Close()
+	config := b.config(0)
+	config.KeepAlive = "2s"
+	p, _ := NewPublisher(config, "service")
+	defer p.Close()
+
+	ctx, event := testEvent()
+	if err := p.Publish(ctx, event); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	b.message(t)
+	select {
+	case <-b.pings:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("No ping received")
+	}
+	if err := p.Publish(ctx, event); err != nil {
+		t.Fatalf("Failed to publish after the ping: %v", err)
+	}
+	b.message(t)
+	if len(b.clientIDs) != 1 {
+		t.Fatalf("Expected the connection to be kept")
+	}
+}
+
+// writeCertificate writes a self-signed certificate of 127.0.0.1 and its key
+// to PEM files in dir, it serves as CA, broker and client certificate.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode the key: %v", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	_ = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	_ = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestPublishTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "publisher")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeCertificate(t, dir)
+	cert, _ := tls.LoadX509KeyPair(certFile, keyFile)
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert},
+		ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	b := startBroker(l, 0, 0)
+	defer b.listener.Close()
+
+	config := b.config(1)
+	config.Protocol = "tls"
+	config.CaFile = certFile
+	config.CertFile, config.KeyFile = certFile, keyFile
+	p, err := NewPublisher(config, "service")
+	if err != nil {
+		t.Fatalf("Failed to create the publisher: %v", err)
+	}
+	defer p.Close()
+
+	ctx, event := testEvent()
+	ctx = context.WithValue(ctx, clients.ContentType, clients.ContentTypeCBOR)
+	if err := p.Publish(ctx, event); err != nil {
+		t.Fatalf("Failed to publish over TLS: %v", err)
+	}
+	var env envelope
+	if err := json.Unmarshal(b.message(t).payload, &env); err != nil {
+		t.Fatalf("Failed to decode the envelope: %v", err)
+	}
+	if env.Checksum != fmt.Sprintf("%x", sha256.Sum256(event.EncodedEvent)) {
+		t.Fatalf("Unexpected checksum %s of the CBOR event", env.Checksum)
+	}
+
+	config.CertFile, config.KeyFile = "", ""
+	p, _ = NewPublisher(config, "service")
+	if err := p.Publish(ctx, event); err == nil {
+		t.Fatalf("Expected an error publishing without client certificate")
+	}
+}
@@ -0,0 +1,286 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// Reserved keys of the ResourceOperation mapping table. They are not used as
+// lookup keys but configure how the table is applied.
+const (
+	// MappingDefaultKey holds the value used when no other entry matches.
+	MappingDefaultKey = common.SDKReservedPrefix + "default"
+	// MappingTypeKey holds the ValueType of the mapped reading, String by default.
+	MappingTypeKey = common.SDKReservedPrefix + "type"
+	// MappingStrictKey set to "true" makes the command fail when no entry matches.
+	MappingStrictKey = common.SDKReservedPrefix + "strict"
+
+	// mappingRegexPrefix marks a key as a regular expression, e.g. "regex:^E[0-9]+$".
+	mappingRegexPrefix = "regex:"
+	// mappingRangePrefix marks a key as a numeric range, e.g. "range:0-10".
+	// Without the prefix keys like "1-2" stay exact keys.
+	mappingRangePrefix = "range:"
+)
+
+// rangePattern matches the bounds of range keys such as "0-10", "-5-5" or
+// "0.5-1.5", both bounds are inclusive.
+var rangePattern = regexp.MustCompile(`^\s*(-?[0-9]+(?:\.[0-9]+)?)\s*-\s*(-?[0-9]+(?:\.[0-9]+)?)\s*$`)
+
+// mappingRegexps caches the compiled regular expressions of the mapping keys by
+// pattern, invalid patterns are cached as nil so that they are only reported
+// once. The patterns come from the Device Profiles, so the cache stays small.
+var mappingRegexps sync.Map
+
+// mappingKeys caches the sorted keys of the mapping tables by the address of
+// the table. A table replaced by a profile update may be followed by another
+// one at the same address, so the cached keys are checked against the table.
+var mappingKeys sync.Map
+
+// MapCommandValue maps the value of the CommandValue with the mapping table of a
+// ResourceOperation. The result is a CommandValue of the type specified by the
+// MappingTypeKey entry, or String if no type is specified.
+func MapCommandValue(value *dsModels.CommandValue, mappings map[string]string) (*dsModels.CommandValue, bool) {
+	key := value.ValueToString()
+	if value.Type == dsModels.Float32 || value.Type == dsModels.Float64 {
+		// float values are matched with their decimal representation unless
+		// the table contains the encoded value as an exact key
+		if _, exists := mappings[key]; !exists {
+			key = floatValueToString(value)
+		}
+	}
+	newValue, ok := MapValue(key, mappings)
+	if !ok {
+		return nil, false
+	}
+
+	valueType := dsModels.String
+	if t, exists := mappings[MappingTypeKey]; exists {
+		valueType = dsModels.ParseValueType(t)
+	}
+	result, err := newCommandValueFromString(value.DeviceResourceName, value.Origin, newValue, valueType)
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("mapped value %s cannot be converted to %s: %v", newValue, mappings[MappingTypeKey], err))
+		return nil, false
+	}
+	result.CopyMetadata(value)
+	return result, true
+}
+
+// MapValue looks up the value in the mapping table. Exact keys have the highest
+// priority, followed by numeric ranges, regular expressions and finally the
+// default entry.
+func MapValue(value string, mappings map[string]string) (string, bool) {
+	if newValue, ok := mappings[value]; ok && !isReservedMappingKey(value) {
+		return newValue, true
+	}
+
+	keys := sortedMappingKeys(mappings)
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		for _, k := range keys {
+			lower, upper, ok := parseRangeKey(k)
+			if ok && n >= lower && n <= upper {
+				return mappings[k], true
+			}
+		}
+	}
+
+	for _, k := range keys {
+		if !strings.HasPrefix(k, mappingRegexPrefix) {
+			continue
+		}
+		re := mappingRegexp(k)
+		if re != nil && re.MatchString(value) {
+			return mappings[k], true
+		}
+	}
+
+	if newValue, ok := mappings[MappingDefaultKey]; ok {
+		return newValue, true
+	}
+	return value, false
+}
+
+// mappingRegexp returns the compiled regular expression of the mapping key, or
+// nil if it's invalid.
+func mappingRegexp(key string) *regexp.Regexp {
+	pattern := strings.TrimPrefix(key, mappingRegexPrefix)
+	if c, ok := mappingRegexps.Load(pattern); ok {
+		return c.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		common.LoggingClient.Warn(fmt.Sprintf("invalid regular expression in mapping key %s: %v", key, err))
+	}
+	mappingRegexps.Store(pattern, re)
+	return re
+}
+
+// ReverseMapValue looks up the key whose mapped value equals the given value, it
+// is used to translate a write parameter with the mapping table of a read
+// operation. Range keys are resolved to their lower bound, regular expression
+// and default entries cannot be reversed.
+func ReverseMapValue(value string, mappings map[string]string) (string, bool) {
+	for _, k := range sortedMappingKeys(mappings) {
+		if mappings[k] != value || isReservedMappingKey(k) || strings.HasPrefix(k, mappingRegexPrefix) {
+			continue
+		}
+		if lower, _, ok := parseRangeKey(k); ok {
+			return strconv.FormatFloat(lower, 'f', -1, 64), true
+		}
+		return k, true
+	}
+	return value, false
+}
+
+// StrictMapping indicates whether a failed lookup in the mapping table should
+// fail the command.
+func StrictMapping(mappings map[string]string) bool {
+	strict, err := strconv.ParseBool(mappings[MappingStrictKey])
+	return err == nil && strict
+}
+
+func isReservedMappingKey(key string) bool {
+	return key == MappingDefaultKey || key == MappingTypeKey || key == MappingStrictKey
+}
+
+func parseRangeKey(key string) (float64, float64, bool) {
+	if !strings.HasPrefix(key, mappingRangePrefix) {
+		return 0, 0, false
+	}
+	matches := rangePattern.FindStringSubmatch(key[len(mappingRangePrefix):])
+	if matches == nil {
+		return 0, 0, false
+	}
+	lower, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	upper, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil || lower > upper {
+		return 0, 0, false
+	}
+	return lower, upper, true
+}
+
+// sortedMappingKeys returns the keys in a stable order so that overlapping
+// ranges or patterns always resolve to the same entry. The returned slice is
+// shared and must not be modified.
+func sortedMappingKeys(mappings map[string]string) []string {
+	table := reflect.ValueOf(mappings).Pointer()
+	if c, ok := mappingKeys.Load(table); ok && sameMappingKeys(c.([]string), mappings) {
+		return c.([]string)
+	}
+	keys := make([]string, 0, len(mappings))
+	for k := range mappings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	mappingKeys.Store(table, keys)
+	return keys
+}
+
+// sameMappingKeys reports whether the keys are exactly the keys of the table.
+func sameMappingKeys(keys []string, mappings map[string]string) bool {
+	if len(keys) != len(mappings) {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := mappings[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func floatValueToString(cv *dsModels.CommandValue) string {
+	if cv.Type == dsModels.Float32 {
+		v, _ := cv.Float32Value()
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	v, _ := cv.Float64Value()
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func newCommandValueFromString(name string, origin int64, value string, valueType dsModels.ValueType) (*dsModels.CommandValue, error) {
+	switch valueType {
+	case dsModels.String:
+		return dsModels.NewStringValue(name, origin, value), nil
+	case dsModels.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return dsModels.NewBoolValue(name, origin, v)
+	case dsModels.Uint8, dsModels.Uint16, dsModels.Uint32, dsModels.Uint64:
+		v, err := strconv.ParseUint(value, 10, bitSize(valueType))
+		if err != nil {
+			return nil, err
+		}
+		switch valueType {
+		case dsModels.Uint8:
+			return dsModels.NewUint8Value(name, origin, uint8(v))
+		case dsModels.Uint16:
+			return dsModels.NewUint16Value(name, origin, uint16(v))
+		case dsModels.Uint32:
+			return dsModels.NewUint32Value(name, origin, uint32(v))
+		default:
+			return dsModels.NewUint64Value(name, origin, v)
+		}
+	case dsModels.Int8, dsModels.Int16, dsModels.Int32, dsModels.Int64:
+		v, err := strconv.ParseInt(value, 10, bitSize(valueType))
+		if err != nil {
+			return nil, err
+		}
+		switch valueType {
+		case dsModels.Int8:
+			return dsModels.NewInt8Value(name, origin, int8(v))
+		case dsModels.Int16:
+			return dsModels.NewInt16Value(name, origin, int16(v))
+		case dsModels.Int32:
+			return dsModels.NewInt32Value(name, origin, int32(v))
+		default:
+			return dsModels.NewInt64Value(name, origin, v)
+		}
+	case dsModels.Float32:
+		v, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return nil, err
+		}
+		return dsModels.NewFloat32Value(name, origin, float32(v))
+	case dsModels.Float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return dsModels.NewFloat64Value(name, origin, v)
+	default:
+		return nil, fmt.Errorf("mapping to value type %v is not supported", valueType)
+	}
+}
+
+func bitSize(valueType dsModels.ValueType) int {
+	switch valueType {
+	case dsModels.Uint8, dsModels.Int8:
+		return 8
+	case dsModels.Uint16, dsModels.Int16:
+		return 16
+	case dsModels.Uint32, dsModels.Int32:
+		return 32
+	default:
+		return 64
+	}
+}
@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"regexp"
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestMapValue(t *testing.T) {
+	mappings := map[string]string{
+		"1":                "ON",
+		"range:0-10":       "LOW",
+		"range:10.5-20":    "MEDIUM",
+		"range:-20--1":     "NEGATIVE",
+		"regex:^ERR[0-9]+": "ERROR",
+		MappingDefaultKey:  "UNKNOWN",
+	}
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"exact key has priority over range", "1", "ON"},
+		{"lower bound of range", "0", "LOW"},
+		{"upper bound of range", "10", "LOW"},
+		{"float range", "15.2", "MEDIUM"},
+		{"negative range", "-5", "NEGATIVE"},
+		{"regular expression", "ERR42", "ERROR"},
+		{"default", "10.2", "UNKNOWN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := MapValue(tt.value, mappings)
+			if !ok {
+				t.Fatalf("value %s should be mapped", tt.value)
+			}
+			if result != tt.expected {
+				t.Fatalf("Unexpect test result, result '%v' should be '%v'", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapValue_rangeNeedsPrefix(t *testing.T) {
+	mappings := map[string]string{"1-2": "EXACT", "-5": "MINUS_FIVE"}
+
+	if result, ok := MapValue("1-2", mappings); !ok || result != "EXACT" {
+		t.Fatalf("Unexpect test result %v, key 1-2 should be an exact key", result)
+	}
+	if _, ok := MapValue("1.5", mappings); ok {
+		t.Fatal("value 1.5 should not be mapped without range prefix")
+	}
+	if result, ok := MapValue("-5", mappings); !ok || result != "MINUS_FIVE" {
+		t.Fatalf("Unexpect test result %v, key -5 should be an exact key", result)
+	}
+}
+
+func TestMapValue_noMatch(t *testing.T) {
+	mappings := map[string]string{"range:0-10": "LOW", MappingStrictKey: "true"}
+
+	_, ok := MapValue("11", mappings)
+
+	if ok {
+		t.Fatal("value 11 should not be mapped")
+	}
+	if !StrictMapping(mappings) {
+		t.Fatal("mapping table should be strict")
+	}
+}
+
+func TestMapCommandValue_typedResult(t *testing.T) {
+	mappings := map[string]string{"range:0-10": "1", "range:11-100": "2", MappingTypeKey: "Uint8"}
+	cv, _ := dsModels.NewInt16Value("test-object", 0, 42)
+
+	result, ok := MapCommandValue(cv, mappings)
+
+	if !ok {
+		t.Fatal("value 42 should be mapped")
+	}
+	if result.Type != dsModels.Uint8 {
+		t.Fatalf("Unexpect test result, value type '%v' should be '%v'", result.Type, dsModels.Uint8)
+	}
+	v, err := result.Uint8Value()
+	if err != nil || v != 2 {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", v, 2)
+	}
+}
+
+func TestMapCommandValue_floatRange(t *testing.T) {
+	mappings := map[string]string{"range:0-0.5": "LOW", "range:0.6-1": "HIGH"}
+	cv, _ := dsModels.NewFloat32Value("test-object", 0, 0.25)
+
+	result, ok := MapCommandValue(cv, mappings)
+
+	if !ok {
+		t.Fatal("value 0.25 should be mapped")
+	}
+	if result.ValueToString() != "LOW" {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", result.ValueToString(), "LOW")
+	}
+}
+
+func TestMapCommandValue_invalidType(t *testing.T) {
+	mappings := map[string]string{"range:0-10": "LOW", MappingTypeKey: "Int8"}
+	cv, _ := dsModels.NewInt8Value("test-object", 0, 5)
+
+	_, ok := MapCommandValue(cv, mappings)
+
+	if ok {
+		t.Fatal("mapped value LOW cannot be converted to Int8")
+	}
+}
+
+func TestReverseMapValue(t *testing.T) {
+	mappings := map[string]string{
+		"1":              "ON",
+		"0":              "OFF",
+		"range:10-20":    "HIGH",
+		"regex:^E":       "ERROR",
+		MappingStrictKey: "true",
+	}
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		ok       bool
+	}{
+		{"exact key", "ON", "1", true},
+		{"range key resolves to the lower bound", "HIGH", "10", true},
+		{"regular expression is not reversible", "ERROR", "ERROR", false},
+		{"reserved key is not reversible", "true", "true", false},
+		{"no match", "DIM", "DIM", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ReverseMapValue(tt.value, mappings)
+			if ok != tt.ok {
+				t.Fatalf("Unexpect test result, mapped '%v' should be '%v'", ok, tt.ok)
+			}
+			if result != tt.expected {
+				t.Fatalf("Unexpect test result, result '%v' should be '%v'", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapValue_regexCache(t *testing.T) {
+	mappings := map[string]string{"regex:^W[0-9]+$": "WARNING", "regex:([": "INVALID"}
+	for i := 0; i < 2; i++ {
+		if result, ok := MapValue("W42", mappings); !ok || result != "WARNING" {
+			t.Fatalf("Unexpect test result '%v', should be 'WARNING'", result)
+		}
+		if _, ok := MapValue("([", mappings); ok {
+			t.Fatal("Invalid regular expression should not match")
+		}
+	}
+	if c, ok := mappingRegexps.Load("^W[0-9]+$"); !ok || c.(*regexp.Regexp) == nil {
+		t.Fatal("Regular expression should be cached")
+	}
+	if c, ok := mappingRegexps.Load("(["); !ok || c.(*regexp.Regexp) != nil {
+		t.Fatal("Invalid regular expression should be cached as nil")
+	}
+}
+
+func TestSortedMappingKeys_cache(t *testing.T) {
+	mappings := map[string]string{"b": "2", "a": "1"}
+	keys := sortedMappingKeys(mappings)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Unexpect sorted keys %v", keys)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { sortedMappingKeys(mappings) }); allocs != 0 {
+		t.Fatalf("Unexpect %v allocations, the sorted keys should be cached", allocs)
+	}
+
+	// a changed table gets its keys sorted again
+	delete(mappings, "b")
+	mappings["c"] = "3"
+	if keys = sortedMappingKeys(mappings); keys[0] != "a" || keys[1] != "c" {
+		t.Fatalf("Unexpect sorted keys %v of the changed table", keys)
+	}
+}
@@ -0,0 +1,256 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/pkg/errors"
+)
+
+// OverflowAttribute is the DeviceResource attribute selecting what happens when
+// a base, scale or offset transform produces a value outside of the range of
+// the ValueType.
+const (
+	OverflowAttribute = "overflow"
+
+	// OverflowPolicyError fails the transform, this is the default for reads.
+	OverflowPolicyError = "error"
+	// OverflowPolicyClamp saturates the value to the minimum or maximum of the
+	// ValueType, flags it with dsModels.FlagClamped and lowers a good quality
+	// to uncertain.
+	OverflowPolicyClamp = "clamp"
+	// OverflowPolicyWiden converts the value to Float64 and flags it with
+	// dsModels.FlagWidened. The Driver writes the ValueType of the
+	// DeviceResource, so writes fail on overflow as with OverflowPolicyError.
+	OverflowPolicyWiden = "widen"
+)
+
+func overflowPolicy(attributes map[string]string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(attributes[OverflowAttribute]))
+	switch policy {
+	case "", OverflowPolicyError, OverflowPolicyClamp, OverflowPolicyWiden:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported overflow policy %s", attributes[OverflowAttribute])
+	}
+}
+
+// transformReadWithPolicy applies the built-in read transforms, on overflow the
+// base, scale and offset transforms are recomputed as Float64 and the result is
+// handled according to the overflow policy. Mask and shift always use the
+// ValueType of the CommandValue.
+func transformReadWithPolicy(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	policy, err := overflowPolicy(dr.Attributes)
+	if err != nil {
+		return err
+	}
+	err = TransformReadResult(cv, dr.Properties.Value)
+	if _, ok := errors.Cause(err).(OverflowError); !ok || policy == "" || policy == OverflowPolicyError {
+		return err
+	}
+
+	pv := dr.Properties.Value
+	err = TransformReadResult(cv, contract.PropertyValue{Mask: pv.Mask, Shift: pv.Shift})
+	if err != nil {
+		return err
+	}
+	pv.Mask, pv.Shift = "", ""
+	return transformAsFloat64(cv, policy, func(wide *dsModels.CommandValue) error {
+		return TransformReadResult(wide, pv)
+	})
+}
+
+// transformWriteWithPolicy applies the built-in write transforms. The write
+// transforms do not detect overflows by themselves, so the range is only
+// checked when an overflow policy is configured.
+func transformWriteWithPolicy(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	policy, err := overflowPolicy(dr.Attributes)
+	if err != nil {
+		return err
+	}
+	if policy == "" || !isNumericType(cv.Type) {
+		return TransformWriteParameter(cv, dr.Properties.Value)
+	}
+	if policy == OverflowPolicyWiden {
+		policy = OverflowPolicyError
+	}
+	return transformAsFloat64(cv, policy, func(wide *dsModels.CommandValue) error {
+		return TransformWriteParameter(wide, dr.Properties.Value)
+	})
+}
+
+// transformAsFloat64 runs the transform on a Float64 copy of the CommandValue
+// and converts the result back according to the overflow policy. In range
+// results of 64-bit integers are recomputed with their own type, as a float64
+// only holds integers up to 2^53 exactly.
+func transformAsFloat64(cv *dsModels.CommandValue, policy string, transform func(*dsModels.CommandValue) error) error {
+	value, err := commandValueForTransform(cv)
+	if err != nil {
+		return err
+	}
+	wide, err := dsModels.NewFloat64Value(cv.DeviceResourceName, cv.Origin, toFloat64(value))
+	if err != nil {
+		return err
+	}
+	if err = transform(wide); err != nil {
+		return err
+	}
+	result, err := wide.Float64Value()
+	if err != nil {
+		return err
+	}
+
+	inRange := checkTransformedValueInRange(value, result)
+	switch {
+	case inRange:
+		exact, ok := transformExactly(cv, value, result, transform)
+		if !ok {
+			exact = castFloat64(value, result)
+		}
+		err = replaceNewCommandValue(cv, exact)
+	case policy == OverflowPolicyWiden:
+		wide.CopyMetadata(cv)
+		wide.Flags = append(wide.Flags, dsModels.FlagWidened)
+		*cv = *wide
+	case policy == OverflowPolicyClamp:
+		if math.IsNaN(result) {
+			return NewOverflowError(value, result)
+		}
+		min, max := valueTypeRange(value)
+		result = math.Max(min, math.Min(max, result))
+		err = replaceNewCommandValue(cv, castFloat64(value, result))
+		cv.Flags = append(cv.Flags, dsModels.FlagClamped)
+		if cv.Quality.IsGood() {
+			cv.Quality = dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusClamped)
+		}
+	default:
+		return errors.Wrap(NewOverflowError(value, result), fmt.Sprintf("Overflow failed for device resource: %v", cv.DeviceResourceName))
+	}
+	return err
+}
+
+// transformExactly runs the transform on an Int64 or Uint64 copy of the
+// CommandValue. It returns false for other types, if the transform fails or if
+// its result disagrees with the float64 result, e.g. because it wrapped around.
+func transformExactly(cv *dsModels.CommandValue, value interface{}, result float64, transform func(*dsModels.CommandValue) error) (interface{}, bool) {
+	var exact *dsModels.CommandValue
+	switch v := value.(type) {
+	case uint64:
+		exact, _ = dsModels.NewUint64Value(cv.DeviceResourceName, cv.Origin, v)
+	case int64:
+		exact, _ = dsModels.NewInt64Value(cv.DeviceResourceName, cv.Origin, v)
+	default:
+		return nil, false
+	}
+	if err := transform(exact); err != nil {
+		return nil, false
+	}
+	exactValue, err := commandValueForTransform(exact)
+	if err != nil || math.Abs(toFloat64(exactValue)-result) > 1+math.Abs(result)*1e-9 {
+		return nil, false
+	}
+	return exactValue, true
+}
+
+func isNumericType(t dsModels.ValueType) bool {
+	switch t {
+	case dsModels.Uint8, dsModels.Uint16, dsModels.Uint32, dsModels.Uint64,
+		dsModels.Int8, dsModels.Int16, dsModels.Int32, dsModels.Int64,
+		dsModels.Float32, dsModels.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return value.(float64)
+	}
+}
+
+// castFloat64 converts the float64 to the type of the original value, the value
+// must be within the range of the type.
+func castFloat64(origin interface{}, value float64) interface{} {
+	switch origin.(type) {
+	case uint8:
+		return uint8(value)
+	case uint16:
+		return uint16(value)
+	case uint32:
+		return uint32(value)
+	case uint64:
+		if value >= math.MaxUint64 {
+			return uint64(math.MaxUint64)
+		}
+		return uint64(value)
+	case int8:
+		return int8(value)
+	case int16:
+		return int16(value)
+	case int32:
+		return int32(value)
+	case int64:
+		if value >= math.MaxInt64 {
+			return int64(math.MaxInt64)
+		}
+		return int64(value)
+	case float32:
+		return float32(value)
+	default:
+		return value
+	}
+}
+
+func valueTypeRange(origin interface{}) (float64, float64) {
+	switch origin.(type) {
+	case uint8:
+		return 0, math.MaxUint8
+	case uint16:
+		return 0, math.MaxUint16
+	case uint32:
+		return 0, math.MaxUint32
+	case uint64:
+		return 0, math.MaxUint64
+	case int8:
+		return math.MinInt8, math.MaxInt8
+	case int16:
+		return math.MinInt16, math.MaxInt16
+	case int32:
+		return math.MinInt32, math.MaxInt32
+	case int64:
+		return math.MinInt64, math.MaxInt64
+	case float32:
+		return -math.MaxFloat32, math.MaxFloat32
+	default:
+		return -math.MaxFloat64, math.MaxFloat64
+	}
+}
@@ -0,0 +1,153 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/pkg/errors"
+)
+
+func overflowResource(policy string, pv contract.PropertyValue) contract.DeviceResource {
+	dr := contract.DeviceResource{Name: "test-object", Properties: contract.ProfileProperty{Value: pv}}
+	if policy != "" {
+		dr.Attributes = map[string]string{OverflowAttribute: policy}
+	}
+	return dr
+}
+
+func TestTransformRead_overflowError(t *testing.T) {
+	for _, policy := range []string{"", OverflowPolicyError} {
+		dr := overflowResource(policy, contract.PropertyValue{Scale: "10"})
+		cv, _ := dsModels.NewUint8Value(dr.Name, 0, 100)
+
+		err := TransformRead(cv, dr)
+
+		if _, ok := errors.Cause(err).(OverflowError); !ok {
+			t.Fatalf("Unexpect test result with policy '%s', error '%v' should be an OverflowError", policy, err)
+		}
+	}
+}
+
+func TestTransformRead_overflowClamp(t *testing.T) {
+	dr := overflowResource(OverflowPolicyClamp, contract.PropertyValue{Scale: "10", Mask: "127"})
+	cv, _ := dsModels.NewUint8Value(dr.Name, 0, 0xFF)
+
+	err := TransformRead(cv, dr)
+
+	if err != nil {
+		t.Fatalf("Fail to transform read result, error: %v", err)
+	}
+	v, _ := cv.Uint8Value()
+	if v != 255 || len(cv.Flags) != 1 || cv.Flags[0] != dsModels.FlagClamped {
+		t.Fatalf("Unexpect test result, result '%v' with flags %v should be clamped to 255", v, cv.Flags)
+	}
+	if cv.Quality != dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusClamped) {
+		t.Fatalf("Unexpect quality %v of a clamped value", cv.Quality)
+	}
+}
+
+func TestTransformRead_overflowClampNegative(t *testing.T) {
+	dr := overflowResource(OverflowPolicyClamp, contract.PropertyValue{Offset: "-100"})
+	cv, _ := dsModels.NewInt8Value(dr.Name, 0, -100)
+
+	err := TransformRead(cv, dr)
+
+	v, _ := cv.Int8Value()
+	if err != nil || v != -128 {
+		t.Fatalf("Unexpect test result, result '%v' should be clamped to -128, error: %v", v, err)
+	}
+}
+
+func TestTransformRead_overflowWiden(t *testing.T) {
+	dr := overflowResource(OverflowPolicyWiden, contract.PropertyValue{Scale: "10", Offset: "0.5"})
+	cv, _ := dsModels.NewInt16Value(dr.Name, 0, 5000)
+
+	err := TransformRead(cv, dr)
+
+	if err != nil {
+		t.Fatalf("Fail to transform read result, error: %v", err)
+	}
+	v, err := cv.Float64Value()
+	if err != nil || v != 50000.5 || cv.Flags[0] != dsModels.FlagWidened {
+		t.Fatalf("Unexpect test result, result '%v' with flags %v should be widened to 50000.5", v, cv.Flags)
+	}
+}
+
+func TestTransformRead_noOverflowWithPolicy(t *testing.T) {
+	dr := overflowResource(OverflowPolicyClamp, contract.PropertyValue{Scale: "2"})
+	cv, _ := dsModels.NewUint8Value(dr.Name, 0, 100)
+
+	err := TransformRead(cv, dr)
+
+	v, _ := cv.Uint8Value()
+	if err != nil || v != 200 || len(cv.Flags) != 0 {
+		t.Fatalf("Unexpect test result, result '%v' with flags %v should be 200", v, cv.Flags)
+	}
+}
+
+func TestTransformWrite_overflowPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected uint8
+		flagged  bool
+		failed   bool
+	}{
+		{"clamp", OverflowPolicyClamp, 0, true, false},
+		{"error", OverflowPolicyError, 0, false, true},
+		{"widen", OverflowPolicyWiden, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dr := overflowResource(tt.policy, contract.PropertyValue{Offset: "50"})
+			cv, _ := dsModels.NewUint8Value(dr.Name, 0, 20)
+
+			err := TransformWrite(cv, dr)
+
+			if (err != nil) != tt.failed {
+				t.Fatalf("Unexpect test result, error: %v", err)
+			}
+			v, _ := cv.Uint8Value()
+			if !tt.failed && (v != tt.expected || (len(cv.Flags) > 0) != tt.flagged) {
+				t.Fatalf("Unexpect test result, result '%v' with flags %v should be '%v'", v, cv.Flags, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransformWrite_overflowPolicyExactInt64(t *testing.T) {
+	dr := overflowResource(OverflowPolicyClamp, contract.PropertyValue{Offset: "1"})
+	cv, _ := dsModels.NewUint64Value(dr.Name, 0, 1<<60+2)
+
+	if err := TransformWrite(cv, dr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v, _ := cv.Uint64Value(); v != 1<<60+1 || cv.Type != dsModels.Uint64 || len(cv.Flags) > 0 {
+		t.Fatalf("Unexpect test result, result '%v' with flags %v should be '%v'", v, cv.Flags, uint64(1<<60+1))
+	}
+
+	dr = overflowResource(OverflowPolicyClamp, contract.PropertyValue{Offset: "-3"})
+	cv, _ = dsModels.NewInt64Value(dr.Name, 0, -(1<<60)-1)
+	if err := TransformWrite(cv, dr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v, _ := cv.Int64Value(); v != -(1<<60)+2 {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", v, int64(-(1<<60)+2))
+	}
+}
+
+func TestTransformRead_invalidPolicy(t *testing.T) {
+	dr := overflowResource("wrap", contract.PropertyValue{Scale: "2"})
+	cv, _ := dsModels.NewUint8Value(dr.Name, 0, 1)
+
+	if err := TransformRead(cv, dr); err == nil {
+		t.Fatal("unsupported overflow policy should fail")
+	}
+}
@@ -0,0 +1,63 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// AssertionQualityAttribute is the DeviceResource attribute holding the
+// quality, e.g. "bad" or "uncertain:outOfRange", given to a value failing the
+// assertion. When it is set the value is kept and the Device stays enabled.
+// The sub-status defaults to dsModels.SubStatusAssertionFailed.
+const AssertionQualityAttribute = "assertionQuality"
+
+// CheckResourceAssertion checks the value against the assertion of the
+// DeviceResource. When the DeviceResource has an AssertionQualityAttribute a
+// failing value is marked with that quality, otherwise it behaves like
+// CheckAssertion and disables the Device.
+func CheckResourceAssertion(cv *dsModels.CommandValue, dr contract.DeviceResource, device *contract.Device) error {
+	err := VerifyAssertion(cv, dr.Properties.Value.Assertion)
+	if err == nil {
+		return nil
+	}
+	marked, qErr := MarkAssertionQuality(cv, dr)
+	if qErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("invalid %s attribute of device resource %s: %v", AssertionQualityAttribute, dr.Name, qErr))
+	}
+	if marked {
+		common.LoggingClient.Warn(fmt.Sprintf("%v, the value of device %s is marked as %s", err, device.Name, cv.Quality))
+		return nil
+	}
+	return CheckAssertion(cv, dr.Properties.Value.Assertion, device)
+}
+
+// MarkAssertionQuality sets the quality configured by the
+// AssertionQualityAttribute of the DeviceResource on a value which failed the
+// assertion, and reports whether the attribute is set.
+func MarkAssertionQuality(cv *dsModels.CommandValue, dr contract.DeviceResource) (bool, error) {
+	attr, ok := dr.Attributes[AssertionQualityAttribute]
+	if !ok {
+		return false, nil
+	}
+	q, err := dsModels.ParseQuality(attr)
+	if err != nil {
+		return false, err
+	}
+	if q.IsGood() {
+		return false, fmt.Errorf("a value failing the assertion cannot be of %s quality", dsModels.QualityGood)
+	}
+	if q.SubStatus == "" {
+		q.SubStatus = dsModels.SubStatusAssertionFailed
+	}
+	cv.Quality = q
+	return true, nil
+}
@@ -0,0 +1,60 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestCheckResourceAssertion_quality(t *testing.T) {
+	dr := contract.DeviceResource{
+		Name:       "Status",
+		Properties: contract.ProfileProperty{Value: contract.PropertyValue{Assertion: "OK"}},
+		Attributes: map[string]string{AssertionQualityAttribute: "bad"},
+	}
+	device := &contract.Device{Name: "device", OperatingState: contract.Enabled}
+	cv := dsModels.NewStringValue(dr.Name, 0, "FAULT")
+
+	err := CheckResourceAssertion(cv, dr, device)
+
+	if err != nil {
+		t.Fatalf("assertion with quality should not fail, error: %v", err)
+	}
+	if cv.Quality != dsModels.NewQuality(dsModels.QualityBad, dsModels.SubStatusAssertionFailed) {
+		t.Fatalf("Unexpect quality %v", cv.Quality)
+	}
+	if v, _ := cv.StringValue(); v != "FAULT" || device.OperatingState != contract.Enabled {
+		t.Fatalf("value %s should be kept and the device %v enabled", v, device.OperatingState)
+	}
+}
+
+func TestMarkAssertionQuality(t *testing.T) {
+	tests := []struct {
+		name     string
+		attr     map[string]string
+		marked   bool
+		valid    bool
+		expected dsModels.Quality
+	}{
+		{"not configured", nil, false, true, dsModels.Quality{}},
+		{"sub-status", map[string]string{AssertionQualityAttribute: "uncertain:outOfRange"}, true, true, dsModels.NewQuality(dsModels.QualityUncertain, "outOfRange")},
+		{"good quality", map[string]string{AssertionQualityAttribute: "good"}, false, false, dsModels.Quality{}},
+		{"invalid", map[string]string{AssertionQualityAttribute: "poor"}, false, false, dsModels.Quality{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv, _ := dsModels.NewInt16Value("Level", 0, 5)
+			marked, err := MarkAssertionQuality(cv, contract.DeviceResource{Attributes: tt.attr})
+			if marked != tt.marked || (err == nil) != tt.valid || cv.Quality != tt.expected {
+				t.Fatalf("Unexpect test result, marked %v quality %v error %v", marked, cv.Quality, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// DecodeRawValue decodes a Binary CommandValue returned by the driver for a
+// DeviceResource of another ValueType with the raw format attributes of the
+// DeviceResource. Other CommandValues are left unchanged.
+func DecodeRawValue(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	valueType := dsModels.ParseValueType(dr.Properties.Value.Type)
+	if cv.Type != dsModels.Binary || valueType == dsModels.Binary {
+		return nil
+	}
+	result, err := dsModels.DecodeRaw(cv.DeviceResourceName, cv.Origin, cv.BinValue, valueType, dr.Attributes)
+	if err != nil {
+		return err
+	}
+	result.CopyMetadata(cv)
+	*cv = *result
+	return nil
+}
@@ -0,0 +1,44 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestDecodeRawValue(t *testing.T) {
+	dr := contract.DeviceResource{
+		Name:       "test-object",
+		Attributes: map[string]string{dsModels.WordOrderAttribute: dsModels.LowWordFirst},
+		Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: "Int32"}},
+	}
+	cv, _ := dsModels.NewBinaryValue(dr.Name, 0, []byte{0xFF, 0xFE, 0xFF, 0xFF})
+
+	err := DecodeRawValue(cv, dr)
+
+	if err != nil {
+		t.Fatalf("Fail to decode raw value, error: %v", err)
+	}
+	v, err := cv.Int32Value()
+	if err != nil || v != -2 {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", v, -2)
+	}
+}
+
+func TestDecodeRawValue_binaryResource(t *testing.T) {
+	dr := contract.DeviceResource{Name: "test-object", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Type: "Binary"}}}
+	cv, _ := dsModels.NewBinaryValue(dr.Name, 0, []byte{0x01, 0x02})
+
+	err := DecodeRawValue(cv, dr)
+
+	if err != nil || cv.Type != dsModels.Binary {
+		t.Fatalf("Binary resources should not be decoded, type %v, error %v", cv.Type, err)
+	}
+}
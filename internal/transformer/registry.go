@@ -0,0 +1,140 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// TransformsAttribute is the DeviceResource attribute listing the driver-defined
+// transforms of the resource, comma separated in the order they are applied to
+// read values, e.g. "bcd,swapWords". Write parameters pass through them in
+// reverse order.
+const TransformsAttribute = "transforms"
+
+type customTransform struct {
+	read  dsModels.TransformFunc
+	write dsModels.TransformFunc
+}
+
+var (
+	customTransforms = make(map[string]customTransform)
+	registryMutex    sync.RWMutex
+)
+
+// RegisterTransform registers a driver-defined transform. Either function may be
+// nil if the transform only applies to one direction.
+func RegisterTransform(name string, read dsModels.TransformFunc, write dsModels.TransformFunc) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("transform name cannot be empty")
+	}
+	if read == nil && write == nil {
+		return fmt.Errorf("transform %s has neither read nor write function", name)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := customTransforms[name]; ok {
+		return fmt.Errorf("transform %s is already registered", name)
+	}
+	customTransforms[name] = customTransform{read: read, write: write}
+	return nil
+}
+
+// TransformRead applies the driver-defined transforms of the DeviceResource to
+// the read value followed by the built-in transforms of its PropertyValue,
+// overflows are handled according to the OverflowAttribute.
+func TransformRead(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	transforms, err := lookupTransforms(dr.Attributes)
+	if err != nil {
+		return err
+	}
+	for i, t := range transforms {
+		if t.read == nil {
+			continue
+		}
+		if err := applyTransform(t.read, cv, dr.Attributes); err != nil {
+			return fmt.Errorf("read transform %s failed: %v", transformNames(dr.Attributes)[i], err)
+		}
+	}
+	return transformReadWithPolicy(cv, dr)
+}
+
+// TransformWrite applies the built-in transforms of the PropertyValue to the
+// write parameter followed by the driver-defined transforms of the
+// DeviceResource in reverse order, overflows are handled according to the
+// OverflowAttribute.
+func TransformWrite(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	transforms, err := lookupTransforms(dr.Attributes)
+	if err != nil {
+		return err
+	}
+	err = transformWriteWithPolicy(cv, dr)
+	if err != nil {
+		return err
+	}
+	for i := len(transforms) - 1; i >= 0; i-- {
+		if transforms[i].write == nil {
+			continue
+		}
+		if err := applyTransform(transforms[i].write, cv, dr.Attributes); err != nil {
+			return fmt.Errorf("write transform %s failed: %v", transformNames(dr.Attributes)[i], err)
+		}
+	}
+	return nil
+}
+
+func applyTransform(f dsModels.TransformFunc, cv *dsModels.CommandValue, attributes map[string]string) error {
+	result, err := f(cv, attributes)
+	if err != nil {
+		return err
+	}
+	if result != nil && result != cv {
+		// the transform converts the value, its Flags, Quality and Tags
+		// still describe it
+		result.CopyMetadata(cv)
+		*cv = *result
+	}
+	return nil
+}
+
+func lookupTransforms(attributes map[string]string) ([]customTransform, error) {
+	names := transformNames(attributes)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	transforms := make([]customTransform, len(names))
+	for i, name := range names {
+		t, ok := customTransforms[name]
+		if !ok {
+			return nil, fmt.Errorf("transform %s is not registered", name)
+		}
+		transforms[i] = t
+	}
+	return transforms, nil
+}
+
+func transformNames(attributes map[string]string) []string {
+	var names []string
+	for _, name := range strings.Split(attributes[TransformsAttribute], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
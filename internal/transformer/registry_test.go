@@ -0,0 +1,139 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+	"testing"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// bcdRead decodes the BCD digits of an Uint16 into an Int32
+func bcdRead(cv *dsModels.CommandValue, _ map[string]string) (*dsModels.CommandValue, error) {
+	raw, err := cv.Uint16Value()
+	if err != nil {
+		return nil, err
+	}
+	result := int32(0)
+	for shift := 12; shift >= 0; shift -= 4 {
+		digit := int32(raw>>uint(shift)) & 0xF
+		if digit > 9 {
+			return nil, fmt.Errorf("invalid BCD value %x", raw)
+		}
+		result = result*10 + digit
+	}
+	return dsModels.NewInt32Value(cv.DeviceResourceName, cv.Origin, result)
+}
+
+// bcdWrite encodes an Int32 into BCD digits of an Uint16
+func bcdWrite(cv *dsModels.CommandValue, _ map[string]string) (*dsModels.CommandValue, error) {
+	v, err := cv.Int32Value()
+	if err != nil {
+		return nil, err
+	}
+	result := uint16(0)
+	for shift := uint(0); shift < 16; shift += 4 {
+		result |= uint16(v%10) << shift
+		v /= 10
+	}
+	return dsModels.NewUint16Value(cv.DeviceResourceName, cv.Origin, result)
+}
+
+func negate(cv *dsModels.CommandValue, _ map[string]string) (*dsModels.CommandValue, error) {
+	v, err := cv.Int32Value()
+	if err != nil {
+		return nil, err
+	}
+	return dsModels.NewInt32Value(cv.DeviceResourceName, cv.Origin, -v)
+}
+
+func init() {
+	_ = RegisterTransform("test-bcd", bcdRead, bcdWrite)
+	_ = RegisterTransform("test-negate", negate, nil)
+}
+
+func TestRegisterTransform_invalid(t *testing.T) {
+	if err := RegisterTransform("", bcdRead, nil); err == nil {
+		t.Fatal("transform without name should not be registered")
+	}
+	if err := RegisterTransform("test-none", nil, nil); err == nil {
+		t.Fatal("transform without functions should not be registered")
+	}
+	if err := RegisterTransform("test-bcd", bcdRead, bcdWrite); err == nil {
+		t.Fatal("transform should not be registered twice")
+	}
+}
+
+func TestTransformRead_chain(t *testing.T) {
+	dr := contract.DeviceResource{
+		Name:       "test-object",
+		Attributes: map[string]string{TransformsAttribute: "test-bcd, test-negate"},
+		Properties: contract.ProfileProperty{Value: contract.PropertyValue{Offset: "1"}},
+	}
+	cv, _ := dsModels.NewUint16Value(dr.Name, 0, 0x1234)
+
+	err := TransformRead(cv, dr)
+
+	if err != nil {
+		t.Fatalf("Fail to transform read result, error: %v", err)
+	}
+	v, err := cv.Int32Value()
+	if err != nil || v != -1233 {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", v, -1233)
+	}
+}
+
+func TestTransformRead_keepsMetadata(t *testing.T) {
+	dr := contract.DeviceResource{
+		Name:       "test-object",
+		Attributes: map[string]string{TransformsAttribute: "test-bcd"},
+	}
+	cv, _ := dsModels.NewUint16Value(dr.Name, 0, 0x0042)
+	cv.Tags = map[string]string{"unit": "C"}
+	cv.Quality = dsModels.NewQuality(dsModels.QualityUncertain, dsModels.SubStatusClamped)
+	cv.Flags = []string{dsModels.FlagClamped}
+
+	if err := TransformRead(cv, dr); err != nil {
+		t.Fatalf("Fail to transform read result, error: %v", err)
+	}
+	if v, _ := cv.Int32Value(); v != 42 || cv.Type != dsModels.Int32 {
+		t.Fatalf("Unexpect test result, result '%v' should be '%v'", v, 42)
+	}
+	if cv.Tags["unit"] != "C" || cv.Quality.IsGood() || len(cv.Flags) != 1 {
+		t.Fatalf("Unexpect metadata, tags %v, quality %v and flags %v should be kept", cv.Tags, cv.Quality, cv.Flags)
+	}
+}
+
+func TestTransformWrite_reverseOrder(t *testing.T) {
+	dr := contract.DeviceResource{
+		Name:       "test-object",
+		Attributes: map[string]string{TransformsAttribute: "test-bcd,test-negate"},
+		Properties: contract.ProfileProperty{Value: contract.PropertyValue{Scale: "2"}},
+	}
+	cv, _ := dsModels.NewInt32Value(dr.Name, 0, 617)
+
+	err := TransformWrite(cv, dr)
+
+	if err != nil {
+		t.Fatalf("Fail to transform write parameter, error: %v", err)
+	}
+	v, err := cv.Uint16Value()
+	if err != nil || v != 0x0308 {
+		t.Fatalf("Unexpect test result, result '%x' should be '%x'", v, 0x0308)
+	}
+}
+
+func TestTransformRead_notRegistered(t *testing.T) {
+	dr := contract.DeviceResource{Name: "test-object", Attributes: map[string]string{TransformsAttribute: "unknown"}}
+	cv, _ := dsModels.NewUint16Value(dr.Name, 0, 1)
+
+	if err := TransformRead(cv, dr); err == nil {
+		t.Fatal("transform that is not registered should fail")
+	}
+}
@@ -18,8 +18,8 @@ import (
 
 func TransformWriteParameter(cv *dsModels.CommandValue, pv contract.PropertyValue) error {
 	var err error
-	if cv.Type == dsModels.String || cv.Type == dsModels.Bool || cv.Type == dsModels.Binary {
-		return nil // do nothing for String, Bool and Binary
+	if cv.Type == dsModels.String || cv.Type == dsModels.Bool || cv.Type == dsModels.Binary || cv.Type == dsModels.Object {
+		return nil // do nothing for String, Bool, Binary and Object
 	}
 
 	value, err := commandValueForTransform(cv)
@@ -31,8 +31,8 @@ const (
 )
 
 func TransformReadResult(cv *dsModels.CommandValue, pv contract.PropertyValue) error {
-	if cv.Type == dsModels.String || cv.Type == dsModels.Bool || cv.Type == dsModels.Binary {
-		return nil // do nothing for String, Bool and Binary
+	if cv.Type == dsModels.String || cv.Type == dsModels.Bool || cv.Type == dsModels.Binary || cv.Type == dsModels.Object {
+		return nil // do nothing for String, Bool, Binary and Object
 	}
 
 	value, err := commandValueForTransform(cv)
@@ -597,23 +597,22 @@ func replaceNewCommandValue(cv *dsModels.CommandValue, newValue interface{}) err
 }
 
 func CheckAssertion(cv *dsModels.CommandValue, assertion string, device *contract.Device) error {
-	if assertion != "" && cv.ValueToString() != assertion {
+	err := VerifyAssertion(cv, assertion)
+	if err != nil {
 		device.OperatingState = contract.Disabled
 		cache.Devices().Update(*device)
 		ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
 		go common.DeviceClient.UpdateOpStateByName(ctx, device.Name, contract.Disabled)
-		msg := fmt.Sprintf("assertion (%s) failed with value: %s", assertion, cv.ValueToString())
-		common.LoggingClient.Error(msg)
-		return fmt.Errorf(msg)
+		common.LoggingClient.Error(err.Error())
 	}
-	return nil
+	return err
 }
 
-func MapCommandValue(value *dsModels.CommandValue, mappings map[string]string) (*dsModels.CommandValue, bool) {
-	newValue, ok := mappings[value.ValueToString()]
-	var result *dsModels.CommandValue
-	if ok {
-		result = dsModels.NewStringValue(value.DeviceResourceName, value.Origin, newValue)
+// VerifyAssertion checks the value against the assertion without changing the
+// OperatingState of the Device.
+func VerifyAssertion(cv *dsModels.CommandValue, assertion string) error {
+	if assertion != "" && cv.ValueToString() != assertion {
+		return fmt.Errorf("assertion (%s) failed with value: %s", assertion, cv.ValueToString())
 	}
-	return result, ok
+	return nil
 }
@@ -0,0 +1,154 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// appendArray appends the array value as JSON array to dst. Unsigned arrays
+// are encoded as [] when nil, like the SDK always did.
+func (cv *CommandValue) appendArray(dst []byte) []byte {
+	switch values := cv.array.(type) {
+	case []bool:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return strconv.AppendBool(b, values[i])
+		})
+	case []uint8:
+		return appendJSONArray(dst, len(values), false, func(b []byte, i int) []byte {
+			return strconv.AppendUint(b, uint64(values[i]), 10)
+		})
+	case []uint16:
+		return appendJSONArray(dst, len(values), false, func(b []byte, i int) []byte {
+			return strconv.AppendUint(b, uint64(values[i]), 10)
+		})
+	case []uint32:
+		return appendJSONArray(dst, len(values), false, func(b []byte, i int) []byte {
+			return strconv.AppendUint(b, uint64(values[i]), 10)
+		})
+	case []uint64:
+		return appendJSONArray(dst, len(values), false, func(b []byte, i int) []byte {
+			return strconv.AppendUint(b, values[i], 10)
+		})
+	case []int8:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return strconv.AppendInt(b, int64(values[i]), 10)
+		})
+	case []int16:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return strconv.AppendInt(b, int64(values[i]), 10)
+		})
+	case []int32:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return strconv.AppendInt(b, int64(values[i]), 10)
+		})
+	case []int64:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return strconv.AppendInt(b, values[i], 10)
+		})
+	case []float32:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return appendJSONFloat(b, float64(values[i]), 32)
+		})
+	case []float64:
+		return appendJSONArray(dst, len(values), values == nil, func(b []byte, i int) []byte {
+			return appendJSONFloat(b, values[i], 64)
+		})
+	default:
+		return dst
+	}
+}
+
+// setArray stores a copy of value, which must be the slice type of the
+// array ValueType of the CommandValue.
+func (cv *CommandValue) setArray(value interface{}) error {
+	var ok bool
+	switch cv.Type {
+	case BoolArray:
+		var v []bool
+		if v, ok = value.([]bool); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Uint8Array:
+		var v []uint8
+		if v, ok = value.([]uint8); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Uint16Array:
+		var v []uint16
+		if v, ok = value.([]uint16); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Uint32Array:
+		var v []uint32
+		if v, ok = value.([]uint32); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Uint64Array:
+		var v []uint64
+		if v, ok = value.([]uint64); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Int8Array:
+		var v []int8
+		if v, ok = value.([]int8); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Int16Array:
+		var v []int16
+		if v, ok = value.([]int16); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Int32Array:
+		var v []int32
+		if v, ok = value.([]int32); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Int64Array:
+		var v []int64
+		if v, ok = value.([]int64); ok {
+			cv.array = append(v[:0:0], v...)
+		}
+	case Float32Array:
+		var v []float32
+		if v, ok = value.([]float32); ok {
+			cv.array = append(v[:0:0], v...)
+			return checkFloats(value, len(v), func(i int) float64 { return float64(v[i]) })
+		}
+	case Float64Array:
+		var v []float64
+		if v, ok = value.([]float64); ok {
+			cv.array = append(v[:0:0], v...)
+			return checkFloats(value, len(v), func(i int) float64 { return v[i] })
+		}
+	}
+	if !ok {
+		return fmt.Errorf("the value %T doesn't match the ValueType %s", value, cv.ValueTypeToString())
+	}
+	return nil
+}
+
+// checkFloats fails like encoding/json for NaN and infinite elements, which
+// can't be represented in the JSON readings.
+func checkFloats(value interface{}, n int, elem func(int) float64) error {
+	for i := 0; i < n; i++ {
+		if f := elem(i); math.IsNaN(f) || math.IsInf(f, 0) {
+			_, err := json.Marshal(value)
+			return err
+		}
+	}
+	return nil
+}
+
+// noArrayError is returned by the array accessors of a CommandValue that
+// wasn't created with its array constructor.
+func (cv *CommandValue) noArrayError() error {
+	return fmt.Errorf("the CommandValue (%s) holds no %s value", cv.DeviceResourceName, cv.ValueTypeToString())
+}
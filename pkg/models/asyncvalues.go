@@ -6,8 +6,11 @@
 
 package models
 
-// AsyncValues is the struct for sending Device readings asynchronously via ProtocolDrivers
+// AsyncValues is the struct for sending Device readings asynchronously via ProtocolDrivers.
+// Its JSON encoding is the recording format replayed by Service.Replay.
 type AsyncValues struct {
-	DeviceName    string
-	CommandValues []*CommandValue
+	DeviceName    string          `json:"deviceName"`
+	CommandValues []*CommandValue `json:"commandValues"`
+	// Tags are attached to the event of the CommandValues.
+	Tags map[string]string `json:"tags,omitempty"`
 }
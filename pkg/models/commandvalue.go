@@ -9,15 +9,16 @@ package models
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/fxamacker/cbor/v2"
 )
 
 // ValueType indicates the type of value being passed back
@@ -29,7 +30,7 @@ const (
 	// stored in CommandValue's boolRes member.
 	Bool ValueType = iota
 	// BoolArray indicates that the value is array of bool,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	BoolArray
 	// String indicates that the value is a string,
 	// stored in CommandValue's stringRes member.
@@ -38,74 +39,82 @@ const (
 	// is stored in CommandValue's NumericRes member.
 	Uint8
 	// Uint8Array indicates that the value is array of uint8,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Uint8Array
 	// Uint16 indicates that the value is a uint16 that
 	// is stored in CommandValue's NumericRes member.
 	Uint16
 	// Uint16Array indicates that the value is array of uint16,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Uint16Array
 	// Uint32 indicates that the value is a uint32 that
 	// is stored in CommandValue's NumericRes member.
 	Uint32
 	// Uint32Array indicates that the value is array of uint32,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Uint32Array
 	// Uint64 indicates that the value is a uint64 that
 	// is stored in CommandValue's NumericRes member.
 	Uint64
 	// Uint64Array indicates that the value is array of uint64,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Uint64Array
 	// Int8 indicates that the value is a int8 that
 	// is stored in CommandValue's NumericRes member.
 	Int8
 	// Int8Array indicates that the value is array of int8,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Int8Array
 	// Int16 indicates that the value is a int16 that
 	// is stored in CommandValue's NumericRes member.
 	Int16
 	// Int16Array indicates that the value is array of int16,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Int16Array
 	// Int32 indicates that the value is a int32 that
 	// is stored in CommandValue's NumericRes member.
 	Int32
 	// Int32Array indicates that the value is array of int32,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Int32Array
 	// Int64 indicates that the value is a int64 that
 	// is stored in CommandValue's NumericRes member.
 	Int64
 	// Int64Array indicates that the value is array of int64,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Int64Array
 	// Float32 indicates that the value is a float32 that
 	// is stored in CommandValue's NumericRes member.
 	Float32
 	// Float32Array indicates that the value is array of float32,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Float32Array
 	// Float64 indicates that the value is a float64 that
 	// is stored in CommandValue's NumericRes member.
 	Float64
 	// Float64Array indicates that the value is array of float64,
-	// store in CommandValue's stringValue member.
+	// stored in CommandValue's array member.
 	Float64Array
 	// Binary indicates that the value is a binary payload that
 	// is stored in CommandValue's ByteArrRes member.
 	Binary
+	// Object indicates that the value is a structured, JSON-compatible
+	// value made of maps, lists and scalars, stored as JSON in
+	// CommandValue's stringValue member.
+	Object
 )
 
 const (
 	// Policy limits should be located in global config namespace
 	// Currently assigning 16MB (binary), 16 * 2^20 bytes
 	MaxBinaryBytes = 16777216
-	// DefaultFoloatEncoding indicates the representation of floating value of reading.
-	// It would be configurable in system level in the future
+	// DefaultFloatEncoding indicates the representation of floating value of
+	// reading, unless configured otherwise by the Device.FloatEncoding setting
+	// of the device service or the floatEncoding of the device resource.
 	DefaultFloatEncoding = contract.Base64Encoding
+	// ValueTypeObject is the ValueType of readings of the Object ValueType,
+	// the Value of the reading holds the JSON representation of the value.
+	ValueTypeObject = "Object"
 )
 
 // ParseValueType could get ValueType from type name in string format
@@ -160,6 +169,8 @@ func ParseValueType(typeName string) ValueType {
 		return Float64Array
 	case "BINARY":
 		return Binary
+	case "OBJECT":
+		return Object
 	default:
 		return String
 	}
@@ -183,31 +194,52 @@ type CommandValue struct {
 	// 64 bytes, used to hold a numeric value returned by a
 	// ProtocolDriver instance. The value can be converted to
 	// its native type by referring to the the value of ResType.
+	// The SDK never modifies it in place, so copies of a CommandValue
+	// may share it.
 	NumericValue []byte
 	// stringValue is a string value returned as a value by a ProtocolDriver instance.
 	stringValue string
+	// numeric holds the value of a Bool or scalar numeric CommandValue
+	// created by the SDK, NumericValue is its big-endian view.
+	numeric uint64
+	// numericData is the first byte of the view of numeric, a NumericValue
+	// replaced by a ProtocolDriver is decoded instead.
+	numericData *byte
+	// array holds a copy of the slice of an array value.
+	array interface{}
 	// BinValue is a binary value with a maximum capacity of 16 MB,
 	// used to hold binary values returned by a ProtocolDriver instance.
 	BinValue []byte
+	// stream is the reader of a Binary value created by NewBinaryStreamValue.
+	stream io.Reader
+	// Flags annotate the value, e.g. FlagClamped, and are attached to
+	// the reading created from the CommandValue.
+	Flags []string
+	// Quality tells how far the value can be trusted, it is good unless
+	// set by the ProtocolDriver, an assertion or a transform.
+	Quality Quality
+	// Tags carry context about the value known by the ProtocolDriver, e.g.
+	// the source channel, and are attached to the reading created from
+	// the CommandValue.
+	Tags map[string]string
+}
+
+// CopyMetadata copies the Flags, Quality and Tags of src, e.g. to a
+// CommandValue replacing src after a conversion.
+func (cv *CommandValue) CopyMetadata(src *CommandValue) {
+	cv.Flags, cv.Quality, cv.Tags = src.Flags, src.Quality, src.Tags
 }
 
 // NewBoolValue creates a CommandValue of Type Bool with the given value.
 func NewBoolValue(DeviceResourceName string, origin int64, value bool) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Bool}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Bool, boolBits(value), 1)
 	return
 }
 
 // NewBoolArrayValue creates a CommandValue of Type BoolArray with the given value.
 func NewBoolArrayValue(DeviceResourceName string, origin int64, value []bool) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               BoolArray,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: BoolArray}
+	err = cv.setArray(value)
 	return
 }
 
@@ -219,205 +251,131 @@ func NewStringValue(DeviceResourceName string, origin int64, value string) (cv *
 
 // NewUint8Value creates a CommandValue of Type Uint8 with the given value.
 func NewUint8Value(DeviceResourceName string, origin int64, value uint8) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint8}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Uint8, uint64(value), 1)
 	return
 }
 
 // NewUint8ArrayValue creates a CommandValue of Type Uint8Array with the given value.
 func NewUint8ArrayValue(DeviceResourceName string, origin int64, value []uint8) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Uint8Array,
-		stringValue:        strings.Join(strings.Fields(fmt.Sprintf("%d", value)), ","),
-		BinValue:           jsonValue,
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint8Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewUint16Value creates a CommandValue of Type Uint16 with the given value.
 func NewUint16Value(DeviceResourceName string, origin int64, value uint16) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint16}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Uint16, uint64(value), 2)
 	return
 }
 
 // NewUint16ArrayValue creates a CommandValue of Type Uint16Array with the given value.
 func NewUint16ArrayValue(DeviceResourceName string, origin int64, value []uint16) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Uint16Array,
-		stringValue:        strings.Join(strings.Fields(fmt.Sprintf("%d", value)), ","),
-		BinValue:           jsonValue,
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint16Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewUint32Value creates a CommandValue of Type Uint32 with the given value.
 func NewUint32Value(DeviceResourceName string, origin int64, value uint32) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint32}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Uint32, uint64(value), 4)
 	return
 }
 
 // NewUint32ArrayValue creates a CommandValue of Type Uint32Array with the given value.
 func NewUint32ArrayValue(DeviceResourceName string, origin int64, value []uint32) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Uint32Array,
-		stringValue:        strings.Join(strings.Fields(fmt.Sprintf("%d", value)), ","),
-		BinValue:           jsonValue,
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint32Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewUint64Value creates a CommandValue of Type Uint64 with the given value.
 func NewUint64Value(DeviceResourceName string, origin int64, value uint64) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint64}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Uint64, uint64(value), 8)
 	return
 }
 
 // NewUint64ArrayValue creates a CommandValue of Type Uint64Array with the given value.
 func NewUint64ArrayValue(DeviceResourceName string, origin int64, value []uint64) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Uint64Array,
-		stringValue:        strings.Join(strings.Fields(fmt.Sprintf("%d", value)), ","),
-		BinValue:           jsonValue,
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Uint64Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewInt8Value creates a CommandValue of Type Int8 with the given value.
 func NewInt8Value(DeviceResourceName string, origin int64, value int8) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int8}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Int8, uint64(value), 1)
 	return
 }
 
 // NewInt8ArrayValue creates a CommandValue of Type Int8Array with the given value.
 func NewInt8ArrayValue(DeviceResourceName string, origin int64, value []int8) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Int8Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int8Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewInt16Value creates a CommandValue of Type Int16 with the given value.
 func NewInt16Value(DeviceResourceName string, origin int64, value int16) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int16}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Int16, uint64(value), 2)
 	return
 }
 
 // NewInt16ArrayValue creates a CommandValue of Type Int16Array with the given value.
 func NewInt16ArrayValue(DeviceResourceName string, origin int64, value []int16) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Int16Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int16Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewInt32Value creates a CommandValue of Type Int32 with the given value.
 func NewInt32Value(DeviceResourceName string, origin int64, value int32) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int32}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Int32, uint64(value), 4)
 	return
 }
 
 // NewInt32ArrayValue creates a CommandValue of Type Int32Array with the given value.
 func NewInt32ArrayValue(DeviceResourceName string, origin int64, value []int32) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Int32Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int32Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewInt64Value creates a CommandValue of Type Int64 with the given value.
 func NewInt64Value(DeviceResourceName string, origin int64, value int64) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int64}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Int64, uint64(value), 8)
 	return
 }
 
 // NewInt64ArrayValue creates a CommandValue of Type Int64Array with the given value.
 func NewInt64ArrayValue(DeviceResourceName string, origin int64, value []int64) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Int64Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Int64Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewFloat32Value creates a CommandValue of Type Float32 with the given value.
 func NewFloat32Value(DeviceResourceName string, origin int64, value float32) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Float32}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Float32, uint64(math.Float32bits(value)), 4)
 	return
 }
 
 // NewFloat32ArrayValue creates a CommandValue of Type Float32Array with the given value.
 func NewFloat32ArrayValue(DeviceResourceName string, origin int64, value []float32) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Float32Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Float32Array}
+	err = cv.setArray(value)
 	return
 }
 
 // NewFloat64Value creates a CommandValue of Type Float64 with the given value.
 func NewFloat64Value(DeviceResourceName string, origin int64, value float64) (cv *CommandValue, err error) {
-	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Float64}
-	err = encodeValue(cv, value)
+	cv = newNumericValue(DeviceResourceName, origin, Float64, math.Float64bits(value), 8)
 	return
 }
 
 // NewFloat64ArrayValue creates a CommandValue of Type Float64Array with the given value.
 func NewFloat64ArrayValue(DeviceResourceName string, origin int64, value []float64) (cv *CommandValue, err error) {
-	jsonValue, err := json.Marshal(value)
-	cv = &CommandValue{
-		DeviceResourceName: DeviceResourceName,
-		Origin:             origin,
-		Type:               Float64Array,
-		stringValue:        string(jsonValue),
-	}
-
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Float64Array}
+	err = cv.setArray(value)
 	return
 }
 
@@ -430,12 +388,73 @@ func NewCommandValue(DeviceResourceName string, origin int64, value interface{},
 		cv.BinValue = value.([]byte)
 	case String:
 		cv.stringValue = value.(string)
+	case Object:
+		cv.stringValue, err = marshalObject(value)
+	case BoolArray, Uint8Array, Uint16Array, Uint32Array, Uint64Array, Int8Array, Int16Array, Int32Array, Int64Array, Float32Array, Float64Array:
+		err = cv.setArray(value)
 	default:
 		err = encodeValue(cv, value)
 	}
 	return
 }
 
+// NewObjectValue creates a CommandValue of Type Object with the given value.
+// The value must be JSON-compatible, i.e. maps with string keys, slices and
+// scalars, CBOR decoded maps with non-string keys are converted.
+func NewObjectValue(DeviceResourceName string, origin int64, value interface{}) (cv *CommandValue, err error) {
+	str, err := marshalObject(value)
+	if err != nil {
+		return nil, err
+	}
+	cv = &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Object, stringValue: str}
+	return
+}
+
+// NewObjectValueFromCBOR creates a CommandValue of Type Object from the CBOR
+// encoding of a value.
+func NewObjectValueFromCBOR(DeviceResourceName string, origin int64, data []byte) (*CommandValue, error) {
+	var value interface{}
+	if err := cbor.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("fail to decode CBOR object value: %v", err)
+	}
+	return NewObjectValue(DeviceResourceName, origin, value)
+}
+
+func marshalObject(value interface{}) (string, error) {
+	jsonValue, err := json.Marshal(normalizeObject(value))
+	if err != nil {
+		return "", fmt.Errorf("the value of Object type is not JSON-compatible: %v", err)
+	}
+	return string(jsonValue), nil
+}
+
+// normalizeObject converts the map[interface{}]interface{} produced by CBOR
+// and YAML decoders into map[string]interface{}.
+func normalizeObject(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			m[fmt.Sprint(key)] = normalizeObject(elem)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			m[key] = normalizeObject(elem)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, elem := range v {
+			l[i] = normalizeObject(elem)
+		}
+		return l
+	default:
+		return value
+	}
+}
+
 // NewBinaryValue creates a CommandValue with binary payload and enforces the memory limit for event readings.
 func NewBinaryValue(DeviceResourceName string, origin int64, value []byte) (cv *CommandValue, err error) {
 	if binary.Size(value) > MaxBinaryBytes {
@@ -445,13 +464,61 @@ func NewBinaryValue(DeviceResourceName string, origin int64, value []byte) (cv *
 	return
 }
 
+// NewBinaryStreamValue creates a CommandValue of Type Binary whose value is
+// read from r when the reading is created instead of being held in memory, so
+// it is not limited by MaxBinaryBytes. The device service stores the value
+// locally and the reading only carries the URL it is served under, marked by
+// FlagBlobReference. r is closed after reading if it is an io.Closer.
+func NewBinaryStreamValue(DeviceResourceName string, origin int64, r io.Reader) *CommandValue {
+	return &CommandValue{DeviceResourceName: DeviceResourceName, Origin: origin, Type: Binary, stream: r}
+}
+
+// BinaryStream returns the reader of a CommandValue created by
+// NewBinaryStreamValue, or nil if the value is held in BinValue.
+func (cv *CommandValue) BinaryStream() io.Reader {
+	return cv.stream
+}
+
 func encodeValue(cv *CommandValue, value interface{}) error {
-	buf := new(bytes.Buffer)
-	err := binary.Write(buf, binary.BigEndian, value)
-	if err == nil {
+	switch v := value.(type) {
+	case bool:
+		cv.setNumeric(boolBits(v), 1)
+	case uint8:
+		cv.setNumeric(uint64(v), 1)
+	case uint16:
+		cv.setNumeric(uint64(v), 2)
+	case uint32:
+		cv.setNumeric(uint64(v), 4)
+	case uint64:
+		cv.setNumeric(v, 8)
+	case int8:
+		cv.setNumeric(uint64(v), 1)
+	case int16:
+		cv.setNumeric(uint64(v), 2)
+	case int32:
+		cv.setNumeric(uint64(v), 4)
+	case int64:
+		cv.setNumeric(uint64(v), 8)
+	case float32:
+		cv.setNumeric(uint64(math.Float32bits(v)), 4)
+	case float64:
+		cv.setNumeric(math.Float64bits(v), 8)
+	default:
+		buf := new(bytes.Buffer)
+		err := binary.Write(buf, binary.BigEndian, value)
+		if err != nil {
+			return err
+		}
 		cv.NumericValue = buf.Bytes()
 	}
-	return err
+	return nil
+}
+
+func boolBits(value bool) uint64 {
+	if value {
+		return 1
+	}
+	return 0
 }
 
 func decodeValue(reader io.Reader, value interface{}) error {
@@ -462,107 +529,70 @@ func decodeValue(reader io.Reader, value interface{}) error {
 // ValueToString returns the string format of the value.
 // In EdgeX, float value has two kinds of representation, Base64, and eNotation.
 // Users can specify the floatEncoding in the properties value of the device profile, like floatEncoding: "Base64" or floatEncoding: "eNotation".
+// Float arrays are JSON arrays of decimal numbers unless an encoding is given,
+// then the elements are either in eNotation or base64 encoded strings.
 func (cv *CommandValue) ValueToString(encoding ...string) (str string) {
-	if cv.Type == String {
+	switch cv.Type {
+	case Bool:
+		v, _ := cv.numericBits(1)
+		str = strconv.FormatBool(v != 0)
+	case Uint8, Uint16, Uint32, Uint64, Int8, Int16, Int32, Int64:
+		str = cv.integerToString()
+	case Float32, Float64:
+		var buf [32]byte
+		str = string(cv.appendFloat(buf[:0], getFloatEncoding(encoding)))
+	case Float32Array, Float64Array:
+		if len(encoding) > 0 {
+			str = string(cv.appendFloatArray(nil, getFloatEncoding(encoding)))
+		} else {
+			str = string(cv.appendArray(nil))
+		}
+	case BoolArray, Uint8Array, Uint16Array, Uint32Array, Uint64Array, Int8Array, Int16Array, Int32Array, Int64Array:
+		str = string(cv.appendArray(nil))
+	case Binary:
+		// produce string representation of first 20 bytes of binary value
+		n := len(cv.BinValue)
+		if n > 20 {
+			n = 20
+		}
+		str = "Binary: [" + string(cv.BinValue[:n]) + "...]"
+	default:
+		// String and Object
 		str = cv.stringValue
-		return
 	}
 
-	reader := bytes.NewReader(cv.NumericValue)
+	return
+}
 
+// integerToString formats integer values with strconv, which doesn't allocate
+// for small values.
+func (cv *CommandValue) integerToString() string {
 	switch cv.Type {
-	case Bool:
-		var res bool
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatBool(res)
 	case Uint8:
-		var res uint8
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatUint(uint64(res), 10)
+		v, _ := cv.numericBits(1)
+		return strconv.FormatUint(v, 10)
 	case Uint16:
-		var res uint16
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatUint(uint64(res), 10)
+		v, _ := cv.numericBits(2)
+		return strconv.FormatUint(v, 10)
 	case Uint32:
-		var res uint32
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatUint(uint64(res), 10)
+		v, _ := cv.numericBits(4)
+		return strconv.FormatUint(v, 10)
 	case Uint64:
-		var res uint64
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatUint(res, 10)
+		v, _ := cv.numericBits(8)
+		return strconv.FormatUint(v, 10)
 	case Int8:
-		var res int8
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatInt(int64(res), 10)
+		v, _ := cv.numericBits(1)
+		return strconv.FormatInt(int64(int8(v)), 10)
 	case Int16:
-		var res int16
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatInt(int64(res), 10)
+		v, _ := cv.numericBits(2)
+		return strconv.FormatInt(int64(int16(v)), 10)
 	case Int32:
-		var res int32
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatInt(int64(res), 10)
-	case Int64:
-		var res int64
-		err := binary.Read(reader, binary.BigEndian, &res)
-		if err != nil {
-			str = err.Error()
-		}
-		str = strconv.FormatInt(res, 10)
-	case Float32:
-		floatEncoding := getFloatEncoding(encoding)
-
-		if floatEncoding == contract.ENotation {
-			var res float32
-			binary.Read(reader, binary.BigEndian, &res)
-			str = fmt.Sprintf("%e", res)
-		} else if floatEncoding == contract.Base64Encoding {
-			str = base64.StdEncoding.EncodeToString(cv.NumericValue)
-		}
-	case Float64:
-		floatEncoding := getFloatEncoding(encoding)
-
-		if floatEncoding == contract.ENotation {
-			var res float64
-			binary.Read(reader, binary.BigEndian, &res)
-			str = fmt.Sprintf("%e", res)
-		} else if floatEncoding == contract.Base64Encoding {
-			str = base64.StdEncoding.EncodeToString(cv.NumericValue)
-		}
-	case Binary:
-		// produce string representation of first 20 bytes of binary value
-		str = fmt.Sprintf(fmt.Sprintf("Binary: [%v...]", string(cv.BinValue[:20])))
+		v, _ := cv.numericBits(4)
+		return strconv.FormatInt(int64(int32(v)), 10)
 	default:
-		// ArrayType
-		str = cv.stringValue
+		v, _ := cv.numericBits(8)
+		return strconv.FormatInt(int64(v), 10)
 	}
-
-	return
 }
 
 // ValueTypeToString returns corresponding string representation of the ValueType.
@@ -616,6 +646,8 @@ func (cv *CommandValue) ValueTypeToString() string {
 		return contract.ValueTypeFloat64Array
 	case Binary:
 		return contract.ValueTypeBinary
+	case Object:
+		return ValueTypeObject
 	default:
 		return ""
 	}
@@ -689,6 +721,8 @@ func (cv *CommandValue) String() (str string) {
 		typeStr = "Float64Array: "
 	case Binary:
 		typeStr = "Binary: "
+	case Object:
+		typeStr = "Object: "
 	}
 
 	valueStr := typeStr + cv.ValueToString()
@@ -704,18 +738,24 @@ func (cv *CommandValue) BoolValue() (bool, error) {
 	if cv.Type != Bool {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(1)
+	if err == nil {
+		value = bits != 0
+	}
 	return value, err
 }
 
 // BoolArrayValue returns the value in an array of bool type, and returns error if the Type is not BoolArray.
 func (cv *CommandValue) BoolArrayValue() ([]bool, error) {
-	var value []bool
+	value, ok := cv.array.([]bool)
 	if cv.Type != BoolArray {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // StringValue returns the value in string data type, and returns error if the Type is not String.
@@ -733,18 +773,24 @@ func (cv *CommandValue) Uint8Value() (uint8, error) {
 	if cv.Type != Uint8 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(1)
+	if err == nil {
+		value = uint8(bits)
+	}
 	return value, err
 }
 
 // Uint8ArrayValue returns the value in an array of uint8 type, and returns error if the Type is not Uint8Array.
 func (cv *CommandValue) Uint8ArrayValue() ([]uint8, error) {
-	var value []uint8
+	value, ok := cv.array.([]uint8)
 	if cv.Type != Uint8Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal(cv.BinValue, &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Uint16Value returns the value in uint16 data type, and returns error if the Type is not Uint16.
@@ -753,18 +799,24 @@ func (cv *CommandValue) Uint16Value() (uint16, error) {
 	if cv.Type != Uint16 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(2)
+	if err == nil {
+		value = uint16(bits)
+	}
 	return value, err
 }
 
 // Uint16ArrayValue returns the value in an array of uint16 type, and returns error if the Type is not Uint16Array.
 func (cv *CommandValue) Uint16ArrayValue() ([]uint16, error) {
-	var value []uint16
+	value, ok := cv.array.([]uint16)
 	if cv.Type != Uint16Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal(cv.BinValue, &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Uint32Value returns the value in uint32 data type, and returns error if the Type is not Uint32.
@@ -773,18 +825,24 @@ func (cv *CommandValue) Uint32Value() (uint32, error) {
 	if cv.Type != Uint32 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(4)
+	if err == nil {
+		value = uint32(bits)
+	}
 	return value, err
 }
 
 // Uint32ArrayValue returns the value in an array of uint32 type, and returns error if the Type is not Uint32Array.
 func (cv *CommandValue) Uint32ArrayValue() ([]uint32, error) {
-	var value []uint32
+	value, ok := cv.array.([]uint32)
 	if cv.Type != Uint32Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal(cv.BinValue, &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Uint64Value returns the value in uint64 data type, and returns error if the Type is not Uint64.
@@ -793,18 +851,24 @@ func (cv *CommandValue) Uint64Value() (uint64, error) {
 	if cv.Type != Uint64 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(8)
+	if err == nil {
+		value = bits
+	}
 	return value, err
 }
 
 // Uint64ArrayValue returns the value in an array of uint64 type, and returns error if the Type is not Uint64Array.
 func (cv *CommandValue) Uint64ArrayValue() ([]uint64, error) {
-	var value []uint64
+	value, ok := cv.array.([]uint64)
 	if cv.Type != Uint64Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal(cv.BinValue, &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Int8Value returns the value in int8 data type, and returns error if the Type is not Int8.
@@ -813,18 +877,24 @@ func (cv *CommandValue) Int8Value() (int8, error) {
 	if cv.Type != Int8 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(1)
+	if err == nil {
+		value = int8(bits)
+	}
 	return value, err
 }
 
 // Int8ArrayValue returns the value in an array of int8 type, and returns error if the Type is not Int8Array.
 func (cv *CommandValue) Int8ArrayValue() ([]int8, error) {
-	var value []int8
+	value, ok := cv.array.([]int8)
 	if cv.Type != Int8Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Int16Value returns the value in int16 data type, and returns error if the Type is not Int16.
@@ -833,18 +903,24 @@ func (cv *CommandValue) Int16Value() (int16, error) {
 	if cv.Type != Int16 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(2)
+	if err == nil {
+		value = int16(bits)
+	}
 	return value, err
 }
 
 // Int16ArrayValue returns the value in an array of int16 type, and returns error if the Type is not Int16Array.
 func (cv *CommandValue) Int16ArrayValue() ([]int16, error) {
-	var value []int16
+	value, ok := cv.array.([]int16)
 	if cv.Type != Int16Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Int32Value returns the value in int32 data type, and returns error if the Type is not Int32.
@@ -853,18 +929,24 @@ func (cv *CommandValue) Int32Value() (int32, error) {
 	if cv.Type != Int32 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(4)
+	if err == nil {
+		value = int32(bits)
+	}
 	return value, err
 }
 
 // Int32ArrayValue returns the value in an array of int32 type, and returns error if the Type is not Int32Array.
 func (cv *CommandValue) Int32ArrayValue() ([]int32, error) {
-	var value []int32
+	value, ok := cv.array.([]int32)
 	if cv.Type != Int32Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Int64Value returns the value in int64 data type, and returns error if the Type is not Int64.
@@ -873,18 +955,24 @@ func (cv *CommandValue) Int64Value() (int64, error) {
 	if cv.Type != Int64 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(8)
+	if err == nil {
+		value = int64(bits)
+	}
 	return value, err
 }
 
 // Int64ArrayValue returns the value in an array of int64 type, and returns error if the Type is not Int64Array.
 func (cv *CommandValue) Int64ArrayValue() ([]int64, error) {
-	var value []int64
+	value, ok := cv.array.([]int64)
 	if cv.Type != Int64Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Float32Value returns the value in float32 data type, and returns error if the Type is not Float32.
@@ -893,18 +981,24 @@ func (cv *CommandValue) Float32Value() (float32, error) {
 	if cv.Type != Float32 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(4)
+	if err == nil {
+		value = math.Float32frombits(uint32(bits))
+	}
 	return value, err
 }
 
 // Float32ArrayValue returns the value in an array of float32 type, and returns error if the Type is not Float32Array.
 func (cv *CommandValue) Float32ArrayValue() ([]float32, error) {
-	var value []float32
+	value, ok := cv.array.([]float32)
 	if cv.Type != Float32Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // Float64Value returns the value in float64 data type, and returns error if the Type is not Float64.
@@ -913,18 +1007,24 @@ func (cv *CommandValue) Float64Value() (float64, error) {
 	if cv.Type != Float64 {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := decodeValue(bytes.NewReader(cv.NumericValue), &value)
+	bits, err := cv.numericBits(8)
+	if err == nil {
+		value = math.Float64frombits(bits)
+	}
 	return value, err
 }
 
 // Float64ArrayValue returns the value in an array of float64 type, and returns error if the Type is not Float64Array.
 func (cv *CommandValue) Float64ArrayValue() ([]float64, error) {
-	var value []float64
+	value, ok := cv.array.([]float64)
 	if cv.Type != Float64Array {
 		return value, fmt.Errorf("the data type is not %T", value)
 	}
-	err := json.Unmarshal([]byte(cv.stringValue), &value)
-	return value, err
+	if !ok {
+		return value, cv.noArrayError()
+	}
+	// the stored value can't be changed by the caller
+	return append(value[:0:0], value...), nil
 }
 
 // BinaryValue returns the value in []byte data type, and returns error if the Type is not Binary.
@@ -935,3 +1035,25 @@ func (cv *CommandValue) BinaryValue() ([]byte, error) {
 	}
 	return cv.BinValue, nil
 }
+
+// ObjectValue returns the value of an Object CommandValue decoded from JSON,
+// i.e. as map[string]interface{}, []interface{} or a scalar with numbers as
+// float64, and returns error if the Type is not Object.
+func (cv *CommandValue) ObjectValue() (interface{}, error) {
+	var value interface{}
+	if cv.Type != Object {
+		return value, fmt.Errorf("the CommandValue (%s) data type (%v) is not object", cv.String(), cv.Type)
+	}
+	err := json.Unmarshal([]byte(cv.stringValue), &value)
+	return value, err
+}
+
+// ObjectValueCBOR returns the CBOR encoding of the value of an Object
+// CommandValue, and returns error if the Type is not Object.
+func (cv *CommandValue) ObjectValueCBOR() ([]byte, error) {
+	value, err := cv.ObjectValue()
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(value)
+}
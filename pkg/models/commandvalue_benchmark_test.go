@@ -0,0 +1,139 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func BenchmarkNewInt32Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewInt32Value("resource", 0, int32(i))
+	}
+}
+
+func BenchmarkNewFloat64Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewFloat64Value("resource", 0, float64(i))
+	}
+}
+
+func BenchmarkInt32Value(b *testing.B) {
+	cv, _ := NewInt32Value("resource", 0, -123456)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cv.Int32Value()
+	}
+}
+
+func BenchmarkFloat64Value(b *testing.B) {
+	cv, _ := NewFloat64Value("resource", 0, 12.5)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cv.Float64Value()
+	}
+}
+
+func BenchmarkValueToString_uint8(b *testing.B) {
+	cv, _ := NewUint8Value("resource", 0, 42)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cv.ValueToString()
+	}
+}
+
+func BenchmarkValueToString_int64(b *testing.B) {
+	cv, _ := NewInt64Value("resource", 0, -1234567890)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cv.ValueToString()
+	}
+}
+
+func BenchmarkValueToString_float64Base64(b *testing.B) {
+	cv, _ := NewFloat64Value("resource", 0, 12.5)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cv.ValueToString(contract.Base64Encoding)
+	}
+}
+
+func BenchmarkValueToString_float64ENotation(b *testing.B) {
+	cv, _ := NewFloat64Value("resource", 0, 12.5)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cv.ValueToString(contract.ENotation)
+	}
+}
+
+func BenchmarkAppendValueToString_float64ENotation(b *testing.B) {
+	cv, _ := NewFloat64Value("resource", 0, 12.5)
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = cv.AppendValueToString(buf[:0], contract.ENotation)
+	}
+}
+
+func BenchmarkNewUint16ArrayValue(b *testing.B) {
+	value := make([]uint16, 64)
+	for i := range value {
+		value[i] = uint16(i * 1000)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewUint16ArrayValue("resource", 0, value)
+	}
+}
+
+func BenchmarkNewFloat32ArrayValue(b *testing.B) {
+	value := make([]float32, 64)
+	for i := range value {
+		value[i] = float32(i) * 1.5
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewFloat32ArrayValue("resource", 0, value)
+	}
+}
+
+func BenchmarkFloat32ArrayValue(b *testing.B) {
+	value := make([]float32, 64)
+	cv, _ := NewFloat32ArrayValue("resource", 0, value)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cv.Float32ArrayValue()
+	}
+}
+
+func BenchmarkAppendValueToString_float32Array(b *testing.B) {
+	value := make([]float32, 64)
+	for i := range value {
+		value[i] = float32(i) * 1.5
+	}
+	cv, _ := NewFloat32ArrayValue("resource", 0, value)
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = cv.AppendValueToString(buf[:0])
+	}
+}
@@ -9,11 +9,15 @@ package models
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -1061,3 +1065,245 @@ func TestNewBinaryValueExceedsConstraints(t *testing.T) {
 		// PASS
 	}
 }
+
+func TestNewBinaryStreamValue(t *testing.T) {
+	r := strings.NewReader("frame")
+	cv := NewBinaryStreamValue("Image", 1, r)
+	if cv.Type != Binary || cv.BinaryStream() != r || cv.BinValue != nil {
+		t.Fatalf("Unexpect binary stream value %v", cv)
+	}
+	if bin, _ := NewBinaryValue("Image", 1, []byte("frame")); bin.BinaryStream() != nil {
+		t.Fatal("Binary value should not have a stream")
+	}
+}
+
+func TestNewObjectValue(t *testing.T) {
+	var origin int64 = time.Now().UnixNano()
+	value := map[string]interface{}{
+		"latitude":  51.5,
+		"longitude": -0.12,
+		"alarms":    []interface{}{"low-battery", map[string]interface{}{"code": 17.0}},
+	}
+	cv, err := NewObjectValue("GPS", origin, value)
+	if err != nil {
+		t.Fatalf("NewObjectValue: error %v", err)
+	}
+	if cv.Type != Object || cv.ValueTypeToString() != ValueTypeObject {
+		t.Fatalf("Expected Object type! invalid Type: %v", cv.Type)
+	}
+	if cv.ValueToString() != `{"alarms":["low-battery",{"code":17}],"latitude":51.5,"longitude":-0.12}` {
+		t.Fatalf("Unexpected JSON representation %s", cv.ValueToString())
+	}
+	val, err := cv.ObjectValue()
+	if err != nil {
+		t.Fatalf("ObjectValue: error %v", err)
+	}
+	if !reflect.DeepEqual(val, value) {
+		t.Fatalf("ObjectValue() result %v doesn't match expected value %v", val, value)
+	}
+	if _, err = cv.BinaryValue(); err == nil {
+		t.Fatal("BinaryValue() should fail for an Object CommandValue")
+	}
+
+	if _, err = NewObjectValue("GPS", origin, map[string]interface{}{"callback": func() {}}); err == nil {
+		t.Fatal("NewObjectValue should fail for a value which is not JSON-compatible")
+	}
+}
+
+func TestObjectValueCBOR(t *testing.T) {
+	cv, _ := NewObjectValue("Batch", 0, []interface{}{map[string]interface{}{"id": "a", "count": 2.0}, true})
+
+	data, err := cv.ObjectValueCBOR()
+	if err != nil {
+		t.Fatalf("ObjectValueCBOR: error %v", err)
+	}
+	decoded, err := NewObjectValueFromCBOR("Batch", 0, data)
+	if err != nil {
+		t.Fatalf("NewObjectValueFromCBOR: error %v", err)
+	}
+	if decoded.ValueToString() != cv.ValueToString() {
+		t.Fatalf("CBOR round trip result %s should be %s", decoded.ValueToString(), cv.ValueToString())
+	}
+
+	// CBOR maps may have non-string keys
+	data, _ = cbor.Marshal(map[int]string{1: "one"})
+	decoded, err = NewObjectValueFromCBOR("Batch", 0, data)
+	if err != nil || decoded.ValueToString() != `{"1":"one"}` {
+		t.Fatalf("Unexpected result %v, error %v", decoded, err)
+	}
+}
+
+func TestArrayValueEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		cv       func() (*CommandValue, error)
+		expected interface{}
+	}{
+		{"BoolArray", func() (*CommandValue, error) { return NewBoolArrayValue("r", 0, []bool{true, false}) }, []bool{true, false}},
+		{"nil BoolArray", func() (*CommandValue, error) { return NewBoolArrayValue("r", 0, nil) }, []bool(nil)},
+		{"Int8Array", func() (*CommandValue, error) { return NewInt8ArrayValue("r", 0, []int8{-128, 0, 127}) }, []int8{-128, 0, 127}},
+		{"Int64Array", func() (*CommandValue, error) {
+			return NewInt64ArrayValue("r", 0, []int64{math.MinInt64, math.MaxInt64})
+		}, []int64{math.MinInt64, math.MaxInt64}},
+		{"empty Int32Array", func() (*CommandValue, error) { return NewInt32ArrayValue("r", 0, []int32{}) }, []int32{}},
+		{"Float32Array", func() (*CommandValue, error) {
+			return NewFloat32ArrayValue("r", 0, []float32{0, 1.5, -3.4e38, 1e-7, 123456789, 0.1})
+		}, []float32{0, 1.5, -3.4e38, 1e-7, 123456789, 0.1}},
+		{"Float64Array", func() (*CommandValue, error) {
+			return NewFloat64ArrayValue("r", 0, []float64{0, -0.000001, 1e21, 5e-324, math.MaxFloat64, 0.1})
+		}, []float64{0, -0.000001, 1e21, 5e-324, math.MaxFloat64, 0.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv, err := tt.cv()
+			if err != nil {
+				t.Fatalf("Fail to create CommandValue, error: %v", err)
+			}
+			expected, _ := json.Marshal(tt.expected)
+			if cv.ValueToString() != string(expected) {
+				t.Fatalf("Unexpect encoding %s, should be %s", cv.ValueToString(), expected)
+			}
+		})
+	}
+
+	if _, err := NewFloat64ArrayValue("r", 0, []float64{math.NaN()}); err == nil {
+		t.Fatal("NaN should not be encoded to JSON")
+	}
+}
+
+func TestUintArrayValueEncoding(t *testing.T) {
+	value := []uint16{0, 1, math.MaxUint16}
+	cv, _ := NewUint16ArrayValue("r", 0, value)
+	if cv.ValueToString() != "[0,1,65535]" || cv.BinValue != nil {
+		t.Fatalf("Unexpect encoding %s and binary value %v", cv.ValueToString(), cv.BinValue)
+	}
+
+	bytesValue := []uint8{1, 2, 255}
+	cv, _ = NewUint8ArrayValue("r", 0, bytesValue)
+	if cv.ValueToString() != "[1,2,255]" {
+		t.Fatalf("Unexpect encoding %s", cv.ValueToString())
+	}
+	if v, err := cv.Uint8ArrayValue(); err != nil || !bytes.Equal(v, bytesValue) {
+		t.Fatalf("Unexpect value %v, error: %v", v, err)
+	}
+
+	cv, _ = NewUint64ArrayValue("r", 0, nil)
+	if v, err := cv.Uint64ArrayValue(); cv.ValueToString() != "[]" || v != nil || err != nil {
+		t.Fatalf("Unexpect encoding %s and value %v of nil array, error: %v", cv.ValueToString(), v, err)
+	}
+}
+
+func TestArrayValue_copy(t *testing.T) {
+	value := []int32{1, 2}
+	cv, _ := NewInt32ArrayValue("r", 0, value)
+	value[0] = 10
+	v, _ := cv.Int32ArrayValue()
+	v[1] = 20
+	if v, _ := cv.Int32ArrayValue(); v[0] != 1 || v[1] != 2 || cv.ValueToString() != "[1,2]" {
+		t.Fatalf("Unexpect value %v after changing the given and the returned slices", v)
+	}
+
+	cv, err := NewCommandValue("r", 0, []float64{0.5}, Float64Array)
+	if v, _ := cv.Float64ArrayValue(); err != nil || len(v) != 1 || v[0] != 0.5 {
+		t.Fatalf("Unexpect value %v, error: %v", v, err)
+	}
+	if _, err := NewCommandValue("r", 0, []int8{1}, Int16Array); err == nil {
+		t.Fatal("The slice type should match the ValueType")
+	}
+	literal := &CommandValue{Type: BoolArray}
+	if _, err := literal.BoolArrayValue(); err == nil {
+		t.Fatal("A CommandValue without array should fail")
+	}
+}
+
+func TestValueToString_float(t *testing.T) {
+	f32, _ := NewFloat32Value("r", 0, -1.25e-3)
+	f64, _ := NewFloat64Value("r", 0, math.Inf(1))
+
+	if s := f32.ValueToString(contract.ENotation); s != fmt.Sprintf("%e", float32(-1.25e-3)) {
+		t.Fatalf("Unexpect eNotation %s", s)
+	}
+	if s := f64.ValueToString(contract.ENotation); s != fmt.Sprintf("%e", math.Inf(1)) {
+		t.Fatalf("Unexpect eNotation %s", s)
+	}
+	if s := f64.ValueToString(); s != base64.StdEncoding.EncodeToString(f64.NumericValue) {
+		t.Fatalf("Unexpect base64 encoding %s", s)
+	}
+}
+
+func TestValueToString_floatArray(t *testing.T) {
+	f32, _ := NewFloat32ArrayValue("r", 0, []float32{1.5, -0.25})
+	f64, _ := NewFloat64ArrayValue("r", 0, []float64{1.5e-7})
+
+	if s := f32.ValueToString(); s != "[1.5,-0.25]" {
+		t.Fatalf("Unexpect JSON array %s", s)
+	}
+	if s := f32.ValueToString(contract.ENotation); s != "[1.500000e+00,-2.500000e-01]" {
+		t.Fatalf("Unexpect eNotation array %s", s)
+	}
+	if s := f32.ValueToString(contract.Base64Encoding); s != `["P8AAAA==","voAAAA=="]` {
+		t.Fatalf("Unexpect base64 array %s", s)
+	}
+	if s := f64.ValueToString(contract.ENotation); s != "[1.500000e-07]" {
+		t.Fatalf("Unexpect eNotation array %s", s)
+	}
+	if s := string(f64.AppendValueToString(nil, contract.Base64Encoding)); s != `["PoQh9fQNg3Y="]` {
+		t.Fatalf("Unexpect base64 array %s", s)
+	}
+}
+
+func TestAppendValueToString(t *testing.T) {
+	i16, _ := NewInt16Value("r", 0, -12345)
+	f64, _ := NewFloat64Value("r", 0, 12.5)
+	s := NewStringValue("r", 0, "text")
+	buf := make([]byte, 0, 64)
+
+	for _, cv := range []*CommandValue{i16, f64, s} {
+		allocs := testing.AllocsPerRun(10, func() {
+			buf = cv.AppendValueToString(buf[:0], contract.ENotation)
+		})
+		if string(buf) != cv.ValueToString(contract.ENotation) || allocs != 0 {
+			t.Fatalf("Unexpect result %s with %v allocations, should be %s", buf, allocs, cv.ValueToString(contract.ENotation))
+		}
+	}
+}
+
+func TestNumericValue_tooShort(t *testing.T) {
+	cv := &CommandValue{Type: Int32, NumericValue: []byte{0x01}}
+	if _, err := cv.Int32Value(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Unexpect error %v", err)
+	}
+	cv.NumericValue = nil
+	if _, err := cv.Int32Value(); err != io.EOF {
+		t.Fatalf("Unexpect error %v", err)
+	}
+}
+
+func TestNumericValue_copy(t *testing.T) {
+	cv, _ := NewInt32Value("r", 0, 1)
+	other, _ := NewInt32Value("r", 0, 2)
+	copied := *cv
+
+	*cv = *other
+	if v, _ := copied.Int32Value(); v != 1 {
+		t.Fatalf("Unexpect value %d of the copy after overwriting the original", v)
+	}
+	_ = encodeValue(cv, int32(3))
+	if v, _ := other.Int32Value(); v != 2 {
+		t.Fatalf("Unexpect value %d of the original after changing the copy", v)
+	}
+}
+
+func TestNumericValue_replaced(t *testing.T) {
+	cv, _ := NewUint16Value("r", 0, 1)
+	// a ProtocolDriver replacing the NumericValue of a created CommandValue
+	cv.NumericValue = []byte{0x01, 0x02}
+	if v, _ := cv.Uint16Value(); v != 0x0102 {
+		t.Fatalf("Unexpect value %d, should be decoded from the replaced NumericValue", v)
+	}
+
+	cv, _ = NewInt8Value("r", 0, -1)
+	if v, _ := cv.Int8Value(); v != -1 || cv.numeric != 0xFF || !bytes.Equal(cv.NumericValue, []byte{0xFF}) {
+		t.Fatalf("Unexpect value %d stored as %x and %v", v, cv.numeric, cv.NumericValue)
+	}
+}
@@ -7,13 +7,58 @@
 package models
 
 import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/OneOfOne/xxhash"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// FlagClamped marks a value saturated to the range of its ValueType.
+	FlagClamped = "clamped"
+	// FlagWidened marks a value converted to Float64 because it overflowed its ValueType.
+	FlagWidened = "widened"
+	// FlagBlobReference marks a Binary reading whose Value is the URL of the
+	// locally stored binary value instead of carrying it as BinaryValue.
+	FlagBlobReference = "blobReference"
 )
 
 // Event is a wrapper of contract.Event to provide more Binary related operation in Device Service.
 type Event struct {
 	contract.Event
 	EncodedEvent []byte
+	// ReadingFlags holds the Flags of the CommandValues of the readings, keyed
+	// by the index of the reading, as several readings of a merged event may
+	// have the same name. contract.Reading has no place for them, so they are
+	// encoded as the "flags" field of the readings by MarshalJSON and
+	// MarshalCBOR.
+	ReadingFlags map[int][]string
+	// ReadingQuality holds the Quality of the CommandValues of the readings
+	// which are not good, keyed by reading index. Like ReadingFlags it is
+	// encoded as the "quality" field of the readings.
+	ReadingQuality map[int]Quality
+	// ReadingTags holds the tags of the readings, keyed by reading index.
+	ReadingTags map[int]map[string]string
+	// Tags are the tags of the event, e.g. taken from the Device labels.
+	Tags map[string]string
+	// Command is the command or DeviceResource read for the event. It is not
+	// encoded, but used for the topic the event is published to.
+	Command string
+	// ReadingChecksums holds the checksums of the binary values of the
+	// readings which only carry a reference, keyed by reading index. They
+	// compare the values of consecutive reads and are encoded in hex as the
+	// "checksum" field of the readings.
+	ReadingChecksums map[int]uint64
+}
+
+type flaggedReading struct {
+	contract.Reading
+	Flags    []string          `json:"flags,omitempty"`
+	Quality  *Quality          `json:"quality,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Checksum string            `json:"checksum,omitempty"`
 }
 
 // HasBinaryValue confirms whether an event contains one or more
@@ -28,3 +73,154 @@ func (e Event) HasBinaryValue() bool {
 	}
 	return false
 }
+
+// AddReadingFlags records the flags of the reading with the given index.
+func (e *Event) AddReadingFlags(index int, flags []string) {
+	if len(flags) == 0 {
+		return
+	}
+	if e.ReadingFlags == nil {
+		e.ReadingFlags = make(map[int][]string)
+	}
+	e.ReadingFlags[index] = append(e.ReadingFlags[index], flags...)
+}
+
+// AddReadingQuality records the quality of the reading with the given index,
+// good quality is not recorded.
+func (e *Event) AddReadingQuality(index int, quality Quality) {
+	if quality.IsGood() {
+		return
+	}
+	if e.ReadingQuality == nil {
+		e.ReadingQuality = make(map[int]Quality)
+	}
+	e.ReadingQuality[index] = quality
+}
+
+// QualityOf returns the quality of the reading with the given index.
+func (e Event) QualityOf(index int) Quality {
+	if q, ok := e.ReadingQuality[index]; ok {
+		return q
+	}
+	return Quality{Status: QualityGood}
+}
+
+// AddReadingTags merges the tags into the tags of the reading with the given
+// index.
+func (e *Event) AddReadingTags(index int, tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+	if e.ReadingTags == nil {
+		e.ReadingTags = make(map[int]map[string]string)
+	}
+	if e.ReadingTags[index] == nil {
+		e.ReadingTags[index] = make(map[string]string, len(tags))
+	}
+	for k, v := range tags {
+		e.ReadingTags[index][k] = v
+	}
+}
+
+// AddTags merges the tags into the tags of the event, existing tags with the
+// same key are replaced.
+func (e *Event) AddTags(tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+	if e.Tags == nil {
+		e.Tags = make(map[string]string, len(tags))
+	}
+	for k, v := range tags {
+		e.Tags[k] = v
+	}
+}
+
+// AddReadingChecksum records the checksum of the binary value of the reading
+// with the given index.
+func (e *Event) AddReadingChecksum(index int, checksum uint64) {
+	if e.ReadingChecksums == nil {
+		e.ReadingChecksums = make(map[int]uint64)
+	}
+	e.ReadingChecksums[index] = checksum
+}
+
+// Checksum returns the checksum of the binary value of the reading with the
+// given index, which is the recorded one for readings carrying a reference.
+// It returns false if the reading has no binary value.
+func (e Event) Checksum(index int) (uint64, bool) {
+	if c, ok := e.ReadingChecksums[index]; ok {
+		return c, true
+	}
+	if index < len(e.Readings) && len(e.Readings[index].BinaryValue) > 0 {
+		return xxhash.Checksum64(e.Readings[index].BinaryValue), true
+	}
+	return 0, false
+}
+
+// KeepReadings removes the readings for which keep returns false along with
+// their flags, quality, tags and checksums, the metadata of the remaining
+// readings follows their new index. It returns the number of readings left.
+func (e *Event) KeepReadings(keep func(contract.Reading) bool) int {
+	readings := e.Readings[:0]
+	flags, quality := e.ReadingFlags, e.ReadingQuality
+	tags, checksums := e.ReadingTags, e.ReadingChecksums
+	e.ReadingFlags, e.ReadingQuality, e.ReadingTags, e.ReadingChecksums = nil, nil, nil, nil
+	for i, r := range e.Readings {
+		if !keep(r) {
+			continue
+		}
+		index := len(readings)
+		readings = append(readings, r)
+		e.AddReadingFlags(index, flags[i])
+		if q, ok := quality[i]; ok {
+			e.AddReadingQuality(index, q)
+		}
+		e.AddReadingTags(index, tags[i])
+		if c, ok := checksums[i]; ok {
+			e.AddReadingChecksum(index, c)
+		}
+	}
+	e.Readings = readings
+	return len(readings)
+}
+
+// HasMetadata reports whether the event or any reading has flags, a quality,
+// tags or a checksum which contract.Event cannot carry.
+func (e Event) HasMetadata() bool {
+	return len(e.ReadingFlags) > 0 || len(e.ReadingQuality) > 0 || len(e.ReadingTags) > 0 || len(e.Tags) > 0 || len(e.ReadingChecksums) > 0
+}
+
+// MarshalJSON encodes the event like contract.Event with its tags and the
+// flags, quality, tags and checksum of every reading.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.withMetadata())
+}
+
+// MarshalCBOR encodes the event like contract.Event.CBOR with the same
+// metadata as MarshalJSON, so binary events keep it too.
+func (e Event) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(e.withMetadata())
+}
+
+// eventWithMetadata is the encoding of an Event, decoders of contract.Event
+// ignore the fields they don't know.
+type eventWithMetadata struct {
+	contract.Event
+	Readings []flaggedReading  `json:"readings,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+func (e Event) withMetadata() eventWithMetadata {
+	readings := make([]flaggedReading, len(e.Readings))
+	for i, r := range e.Readings {
+		readings[i] = flaggedReading{Reading: r, Flags: e.ReadingFlags[i], Tags: e.ReadingTags[i]}
+		if q, ok := e.ReadingQuality[i]; ok {
+			readings[i].Quality = &q
+		}
+		if c, ok := e.ReadingChecksums[i]; ok {
+			readings[i].Checksum = strconv.FormatUint(c, 16)
+		}
+	}
+	return eventWithMetadata{Event: e.Event, Readings: readings, Tags: e.Tags}
+}
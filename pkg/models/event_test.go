@@ -0,0 +1,178 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestEvent_MarshalJSON(t *testing.T) {
+	event := Event{Event: contract.Event{Device: "device", Origin: 1, Readings: []contract.Reading{
+		{Name: "temperature", Value: "255", ValueType: "Uint8"},
+		{Name: "humidity", Value: "50", ValueType: "Uint8"},
+	}}}
+	event.AddReadingFlags(0, []string{FlagClamped})
+	event.AddReadingFlags(1, nil)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Fail to marshal event, error: %v", err)
+	}
+
+	var decoded struct {
+		Device   string `json:"device"`
+		Origin   int64  `json:"origin"`
+		Readings []struct {
+			Name  string   `json:"name"`
+			Value string   `json:"value"`
+			Flags []string `json:"flags"`
+		} `json:"readings"`
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Fail to unmarshal event, error: %v", err)
+	}
+	if decoded.Device != "device" || decoded.Origin != 1 || len(decoded.Readings) != 2 {
+		t.Fatalf("Unexpect encoded event %s", data)
+	}
+	if !reflect.DeepEqual(decoded.Readings[0].Flags, []string{FlagClamped}) || decoded.Readings[1].Flags != nil {
+		t.Fatalf("Unexpect reading flags in encoded event %s", data)
+	}
+
+	var contractEvent contract.Event
+	if err = json.Unmarshal(data, &contractEvent); err != nil || contractEvent.Readings[0].Value != "255" {
+		t.Fatalf("encoded event should be decoded as contract.Event, error: %v", err)
+	}
+}
+
+func TestEvent_MarshalJSONQuality(t *testing.T) {
+	event := Event{Event: contract.Event{Device: "device", Readings: []contract.Reading{
+		{Name: "temperature", Value: "21", ValueType: "Uint8"},
+		{Name: "humidity", Value: "50", ValueType: "Uint8"},
+	}}}
+	event.AddReadingQuality(0, NewQuality(QualityUncertain, SubStatusStale))
+	event.AddReadingQuality(1, Quality{})
+
+	if !event.HasMetadata() || event.QualityOf(1).Status != QualityGood {
+		t.Fatalf("Unexpect reading quality %v", event.ReadingQuality)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Fail to marshal event, error: %v", err)
+	}
+
+	var decoded struct {
+		Readings []struct {
+			Name    string   `json:"name"`
+			Quality *Quality `json:"quality"`
+		} `json:"readings"`
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Fail to unmarshal event, error: %v", err)
+	}
+	if decoded.Readings[0].Quality == nil || *decoded.Readings[0].Quality != NewQuality(QualityUncertain, SubStatusStale) {
+		t.Fatalf("Unexpect reading quality in encoded event %s", data)
+	}
+	if decoded.Readings[1].Quality != nil {
+		t.Fatalf("good quality should not be encoded, event %s", data)
+	}
+}
+
+func TestEvent_MarshalJSONTags(t *testing.T) {
+	event := Event{Event: contract.Event{Device: "device", Readings: []contract.Reading{{Name: "temperature", Value: "21"}}}}
+	event.AddTags(map[string]string{"site": "plant-1"})
+	event.AddReadingTags(0, map[string]string{"channel": "1"})
+	event.AddReadingTags(0, map[string]string{"sensor": "pt100"})
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Fail to marshal event, error: %v", err)
+	}
+
+	var decoded struct {
+		Tags     map[string]string `json:"tags"`
+		Readings []struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"readings"`
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Fail to unmarshal event, error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Tags, map[string]string{"site": "plant-1"}) ||
+		!reflect.DeepEqual(decoded.Readings[0].Tags, map[string]string{"channel": "1", "sensor": "pt100"}) {
+		t.Fatalf("Unexpect tags in encoded event %s", data)
+	}
+}
+
+func TestEvent_KeepReadings(t *testing.T) {
+	// two readings of the same resource, e.g. of a merged event
+	event := Event{Event: contract.Event{Device: "device", Readings: []contract.Reading{
+		{Name: "temperature", Value: "1"}, {Name: "humidity"}, {Name: "temperature", Value: "2"},
+	}}}
+	for i := range event.Readings {
+		event.AddReadingFlags(i, []string{FlagClamped})
+		event.AddReadingTags(i, map[string]string{"index": strconv.Itoa(i)})
+		event.AddReadingChecksum(i, uint64(i))
+	}
+	event.AddReadingQuality(1, NewQuality(QualityUncertain, SubStatusClamped))
+	event.AddReadingQuality(2, NewQuality(QualityBad, ""))
+
+	left := event.KeepReadings(func(r contract.Reading) bool { return r.Name != "humidity" })
+	if left != 2 || len(event.ReadingFlags) != 2 || len(event.ReadingQuality) != 1 || len(event.ReadingTags) != 2 || len(event.ReadingChecksums) != 2 {
+		t.Fatalf("Unexpect metadata %v %v %v %v", event.ReadingFlags, event.ReadingQuality, event.ReadingTags, event.ReadingChecksums)
+	}
+	if event.Readings[1].Value != "2" || event.QualityOf(1).Status != QualityBad || event.ReadingTags[1]["index"] != "2" || event.ReadingChecksums[1] != 2 {
+		t.Fatalf("Metadata of the remaining readings should follow them, got %v %v %v", event.ReadingQuality, event.ReadingTags, event.ReadingChecksums)
+	}
+}
+
+func TestEvent_MarshalCBOR(t *testing.T) {
+	event := Event{Event: contract.Event{Device: "device", Origin: 1, Readings: []contract.Reading{
+		{Name: "image", BinaryValue: []byte{0x01, 0x02}, ValueType: contract.ValueTypeBinary},
+		{Name: "image", Value: "http://localhost/blob", ValueType: contract.ValueTypeBinary},
+	}}}
+	event.AddReadingQuality(0, NewQuality(QualityUncertain, SubStatusStale))
+	event.AddReadingTags(0, map[string]string{"camera": "1"})
+	event.AddReadingFlags(1, []string{FlagBlobReference})
+	event.AddReadingChecksum(1, 0xabc)
+	event.AddTags(map[string]string{"site": "plant-1"})
+
+	data, err := cbor.Marshal(event)
+	if err != nil {
+		t.Fatalf("Fail to marshal event, error: %v", err)
+	}
+
+	var decoded struct {
+		Tags     map[string]string `json:"tags"`
+		Readings []flaggedReading  `json:"readings"`
+	}
+	if err = cbor.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Fail to unmarshal event, error: %v", err)
+	}
+	if len(decoded.Readings) != 2 || decoded.Tags["site"] != "plant-1" {
+		t.Fatalf("Unexpect decoded event %+v", decoded)
+	}
+	first, second := decoded.Readings[0], decoded.Readings[1]
+	if first.Quality == nil || *first.Quality != NewQuality(QualityUncertain, SubStatusStale) || first.Tags["camera"] != "1" || first.Flags != nil {
+		t.Fatalf("Unexpect metadata of the first reading %+v", first)
+	}
+	if second.Quality != nil || !reflect.DeepEqual(second.Flags, []string{FlagBlobReference}) || second.Checksum != "abc" {
+		t.Fatalf("Unexpect metadata of the second reading %+v", second)
+	}
+
+	var contractEvent contract.Event
+	if err = cbor.Unmarshal(data, &contractEvent); err != nil || contractEvent.Device != "device" ||
+		!bytes.Equal(contractEvent.Readings[0].BinaryValue, []byte{0x01, 0x02}) {
+		t.Fatalf("encoded event should be decoded as contract.Event %+v, error: %v", contractEvent, err)
+	}
+}
@@ -0,0 +1,464 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Non-finite floats have no JSON number representation, they are encoded as
+// JSON strings instead.
+const (
+	jsonNaN         = "NaN"
+	jsonPositiveInf = "+Inf"
+	jsonNegativeInf = "-Inf"
+)
+
+// commandValueJSON is the serialized form of a CommandValue. Type holds the
+// name returned by ValueTypeToString and Value the value in its native JSON
+// or CBOR type, e.g. a number for Int16 or a list of booleans for BoolArray.
+type commandValueJSON struct {
+	DeviceResourceName string            `json:"deviceResourceName"`
+	Origin             int64             `json:"origin,omitempty"`
+	Type               string            `json:"type"`
+	Value              json.RawMessage   `json:"value"`
+	Flags              []string          `json:"flags,omitempty"`
+	Quality            *Quality          `json:"quality,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+type commandValueCBOR struct {
+	DeviceResourceName string            `json:"deviceResourceName"`
+	Origin             int64             `json:"origin,omitempty"`
+	Type               string            `json:"type"`
+	Value              cbor.RawMessage   `json:"value"`
+	Flags              []string          `json:"flags,omitempty"`
+	Quality            *Quality          `json:"quality,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+// MarshalJSON encodes the CommandValue with its ValueType, so that it is
+// decoded by UnmarshalJSON to an identical CommandValue.
+func (cv CommandValue) MarshalJSON() ([]byte, error) {
+	value, err := cv.jsonValue()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(commandValueJSON{
+		DeviceResourceName: cv.DeviceResourceName,
+		Origin:             cv.Origin,
+		Type:               cv.ValueTypeToString(),
+		Value:              value,
+		Flags:              cv.Flags,
+		Quality:            cv.qualityRef(),
+		Tags:               cv.Tags,
+	})
+}
+
+// UnmarshalJSON decodes a CommandValue encoded by MarshalJSON.
+func (cv *CommandValue) UnmarshalJSON(data []byte) error {
+	var encoded commandValueJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	t, err := parseSerializedValueType(encoded.Type)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	switch t {
+	case Float32, Float64:
+		value, err = unmarshalJSONFloat(encoded.Value, t)
+	case Uint8Array:
+		// encoded as list of numbers rather than base64 string
+		var numbers []uint16
+		if err = json.Unmarshal(encoded.Value, &numbers); err == nil {
+			value, err = uint16sToUint8s(numbers)
+		}
+	case Object:
+		err = json.Unmarshal(encoded.Value, &value)
+	default:
+		value, err = unmarshalTypedValue(t, func(v interface{}) error { return json.Unmarshal(encoded.Value, v) })
+	}
+	if err != nil {
+		return fmt.Errorf("fail to decode %s value of CommandValue %s: %v", encoded.Type, encoded.DeviceResourceName, err)
+	}
+	return cv.assign(encoded.DeviceResourceName, encoded.Origin, t, value, encoded.Flags, encoded.Quality, encoded.Tags)
+}
+
+// MarshalCBOR encodes the CommandValue with its ValueType like MarshalJSON,
+// the value is encoded with the native CBOR type, e.g. a byte string for
+// Binary.
+func (cv CommandValue) MarshalCBOR() ([]byte, error) {
+	value, err := cv.typedValue()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := cbor.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(commandValueCBOR{
+		DeviceResourceName: cv.DeviceResourceName,
+		Origin:             cv.Origin,
+		Type:               cv.ValueTypeToString(),
+		Value:              raw,
+		Flags:              cv.Flags,
+		Quality:            cv.qualityRef(),
+		Tags:               cv.Tags,
+	})
+}
+
+// UnmarshalCBOR decodes a CommandValue encoded by MarshalCBOR.
+func (cv *CommandValue) UnmarshalCBOR(data []byte) error {
+	var encoded commandValueCBOR
+	if err := cbor.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	t, err := parseSerializedValueType(encoded.Type)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if t == Object {
+		err = cbor.Unmarshal(encoded.Value, &value)
+	} else {
+		value, err = unmarshalTypedValue(t, func(v interface{}) error { return cbor.Unmarshal(encoded.Value, v) })
+	}
+	if err != nil {
+		return fmt.Errorf("fail to decode %s value of CommandValue %s: %v", encoded.Type, encoded.DeviceResourceName, err)
+	}
+	return cv.assign(encoded.DeviceResourceName, encoded.Origin, t, value, encoded.Flags, encoded.Quality, encoded.Tags)
+}
+
+func (cv *CommandValue) qualityRef() *Quality {
+	if cv.Quality == (Quality{}) {
+		return nil
+	}
+	q := cv.Quality
+	return &q
+}
+
+// jsonValue returns the JSON encoding of the value.
+func (cv *CommandValue) jsonValue() (json.RawMessage, error) {
+	switch cv.Type {
+	case Float32, Float64:
+		var f float64
+		var bits int
+		if cv.Type == Float32 {
+			v, err := cv.Float32Value()
+			if err != nil {
+				return nil, err
+			}
+			f, bits = float64(v), 32
+		} else {
+			v, err := cv.Float64Value()
+			if err != nil {
+				return nil, err
+			}
+			f, bits = v, 64
+		}
+		switch {
+		case math.IsNaN(f):
+			return json.Marshal(jsonNaN)
+		case math.IsInf(f, 1):
+			return json.Marshal(jsonPositiveInf)
+		case math.IsInf(f, -1):
+			return json.Marshal(jsonNegativeInf)
+		}
+		return appendJSONFloat(nil, f, bits), nil
+	case Uint8Array:
+		// a list of numbers rather than the base64 string of encoding/json
+		return json.RawMessage(cv.appendArray(nil)), nil
+	case Object:
+		return json.RawMessage(cv.stringValue), nil
+	}
+	value, err := cv.typedValue()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// typedValue returns the value in the Go type of its ValueType.
+func (cv *CommandValue) typedValue() (interface{}, error) {
+	switch cv.Type {
+	case Bool:
+		return cv.BoolValue()
+	case BoolArray:
+		return cv.BoolArrayValue()
+	case String:
+		return cv.stringValue, nil
+	case Uint8:
+		return cv.Uint8Value()
+	case Uint8Array:
+		return cv.Uint8ArrayValue()
+	case Uint16:
+		return cv.Uint16Value()
+	case Uint16Array:
+		return cv.Uint16ArrayValue()
+	case Uint32:
+		return cv.Uint32Value()
+	case Uint32Array:
+		return cv.Uint32ArrayValue()
+	case Uint64:
+		return cv.Uint64Value()
+	case Uint64Array:
+		return cv.Uint64ArrayValue()
+	case Int8:
+		return cv.Int8Value()
+	case Int8Array:
+		return cv.Int8ArrayValue()
+	case Int16:
+		return cv.Int16Value()
+	case Int16Array:
+		return cv.Int16ArrayValue()
+	case Int32:
+		return cv.Int32Value()
+	case Int32Array:
+		return cv.Int32ArrayValue()
+	case Int64:
+		return cv.Int64Value()
+	case Int64Array:
+		return cv.Int64ArrayValue()
+	case Float32:
+		return cv.Float32Value()
+	case Float32Array:
+		return cv.Float32ArrayValue()
+	case Float64:
+		return cv.Float64Value()
+	case Float64Array:
+		return cv.Float64ArrayValue()
+	case Binary:
+		return cv.BinValue, nil
+	case Object:
+		return cv.ObjectValue()
+	default:
+		return nil, fmt.Errorf("unsupported ValueType %v", cv.Type)
+	}
+}
+
+// unmarshalTypedValue decodes the value into the Go type of the ValueType
+// with the unmarshal function.
+func unmarshalTypedValue(t ValueType, unmarshal func(interface{}) error) (interface{}, error) {
+	var target interface{}
+	switch t {
+	case Bool:
+		target = new(bool)
+	case BoolArray:
+		target = new([]bool)
+	case String:
+		target = new(string)
+	case Uint8:
+		target = new(uint8)
+	case Uint8Array:
+		target = new([]uint8)
+	case Uint16:
+		target = new(uint16)
+	case Uint16Array:
+		target = new([]uint16)
+	case Uint32:
+		target = new(uint32)
+	case Uint32Array:
+		target = new([]uint32)
+	case Uint64:
+		target = new(uint64)
+	case Uint64Array:
+		target = new([]uint64)
+	case Int8:
+		target = new(int8)
+	case Int8Array:
+		target = new([]int8)
+	case Int16:
+		target = new(int16)
+	case Int16Array:
+		target = new([]int16)
+	case Int32:
+		target = new(int32)
+	case Int32Array:
+		target = new([]int32)
+	case Int64:
+		target = new(int64)
+	case Int64Array:
+		target = new([]int64)
+	case Float32:
+		target = new(float32)
+	case Float32Array:
+		target = new([]float32)
+	case Float64:
+		target = new(float64)
+	case Float64Array:
+		target = new([]float64)
+	case Binary:
+		target = new([]byte)
+	default:
+		return nil, fmt.Errorf("unsupported ValueType %v", t)
+	}
+	if err := unmarshal(target); err != nil {
+		return nil, err
+	}
+	// dereference the pointer to the decoded value
+	switch v := target.(type) {
+	case *bool:
+		return *v, nil
+	case *[]bool:
+		return *v, nil
+	case *string:
+		return *v, nil
+	case *uint8:
+		return *v, nil
+	case *uint16:
+		return *v, nil
+	case *[]uint16:
+		return *v, nil
+	case *uint32:
+		return *v, nil
+	case *[]uint32:
+		return *v, nil
+	case *uint64:
+		return *v, nil
+	case *[]uint64:
+		return *v, nil
+	case *int8:
+		return *v, nil
+	case *[]int8:
+		return *v, nil
+	case *int16:
+		return *v, nil
+	case *[]int16:
+		return *v, nil
+	case *int32:
+		return *v, nil
+	case *[]int32:
+		return *v, nil
+	case *int64:
+		return *v, nil
+	case *[]int64:
+		return *v, nil
+	case *float32:
+		return *v, nil
+	case *[]float32:
+		return *v, nil
+	case *float64:
+		return *v, nil
+	case *[]float64:
+		return *v, nil
+	default:
+		return *target.(*[]byte), nil
+	}
+}
+
+func uint16sToUint8s(values []uint16) ([]uint8, error) {
+	if values == nil {
+		return nil, nil
+	}
+	result := make([]uint8, len(values))
+	for i, v := range values {
+		if v > math.MaxUint8 {
+			return nil, fmt.Errorf("value %d overflows uint8", v)
+		}
+		result[i] = uint8(v)
+	}
+	return result, nil
+}
+
+// unmarshalJSONFloat decodes a JSON number or one of the strings used for
+// non-finite floats.
+func unmarshalJSONFloat(data json.RawMessage, t ValueType) (interface{}, error) {
+	bits := 64
+	if t == Float32 {
+		bits = 32
+	}
+	var f float64
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		switch s {
+		case jsonNaN:
+			f = math.NaN()
+		case jsonPositiveInf:
+			f = math.Inf(1)
+		case jsonNegativeInf:
+			f = math.Inf(-1)
+		default:
+			return nil, fmt.Errorf("invalid float value %s", s)
+		}
+	} else {
+		var err error
+		f, err = strconv.ParseFloat(strings.TrimSpace(string(data)), bits)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t == Float32 {
+		return float32(f), nil
+	}
+	return f, nil
+}
+
+// parseSerializedValueType parses the name of the ValueType, unlike
+// ParseValueType an unknown name is an error.
+func parseSerializedValueType(name string) (ValueType, error) {
+	t := ParseValueType(name)
+	if t == String && !strings.EqualFold(name, "String") {
+		return t, fmt.Errorf("unsupported ValueType %s", name)
+	}
+	return t, nil
+}
+
+// assign replaces the CommandValue by a new one created from the decoded
+// value with the constructor of the ValueType.
+func (cv *CommandValue) assign(name string, origin int64, t ValueType, value interface{}, flags []string, quality *Quality, tags map[string]string) error {
+	var result *CommandValue
+	var err error
+	switch v := value.(type) {
+	case []bool:
+		result, err = NewBoolArrayValue(name, origin, v)
+	case []uint8:
+		if t == Binary {
+			result, err = NewBinaryValue(name, origin, v)
+		} else {
+			result, err = NewUint8ArrayValue(name, origin, v)
+		}
+	case []uint16:
+		result, err = NewUint16ArrayValue(name, origin, v)
+	case []uint32:
+		result, err = NewUint32ArrayValue(name, origin, v)
+	case []uint64:
+		result, err = NewUint64ArrayValue(name, origin, v)
+	case []int8:
+		result, err = NewInt8ArrayValue(name, origin, v)
+	case []int16:
+		result, err = NewInt16ArrayValue(name, origin, v)
+	case []int32:
+		result, err = NewInt32ArrayValue(name, origin, v)
+	case []int64:
+		result, err = NewInt64ArrayValue(name, origin, v)
+	case []float32:
+		result, err = NewFloat32ArrayValue(name, origin, v)
+	case []float64:
+		result, err = NewFloat64ArrayValue(name, origin, v)
+	default:
+		result, err = NewCommandValue(name, origin, value, t)
+	}
+	if err != nil {
+		return err
+	}
+	result.Flags, result.Tags = flags, tags
+	if quality != nil {
+		result.Quality = *quality
+	}
+	*cv = *result
+	return nil
+}
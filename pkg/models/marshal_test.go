@@ -0,0 +1,154 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func marshalTestValues(t *testing.T) []*CommandValue {
+	must := func(cv *CommandValue, err error) *CommandValue {
+		if err != nil {
+			t.Fatalf("Fail to create CommandValue, error: %v", err)
+		}
+		return cv
+	}
+	object := must(NewObjectValue("Object", 1, map[string]interface{}{"fix": true, "position": []interface{}{51.5, -0.12}}))
+	object.Quality = NewQuality(QualityUncertain, SubStatusStale)
+	object.Tags = map[string]string{"channel": "1"}
+	clamped := must(NewUint8Value("Clamped", 1, 255))
+	clamped.Flags = []string{FlagClamped}
+
+	return []*CommandValue{
+		must(NewBoolValue("Bool", 1, true)),
+		must(NewBoolArrayValue("BoolArray", 1, []bool{true, false})),
+		NewStringValue("String", 1, "text \"quoted\""),
+		clamped,
+		must(NewUint8ArrayValue("Uint8Array", 1, []uint8{0, 1, 255})),
+		must(NewUint16Value("Uint16", 1, math.MaxUint16)),
+		must(NewUint16ArrayValue("Uint16Array", 1, []uint16{0, math.MaxUint16})),
+		must(NewUint32Value("Uint32", 1, math.MaxUint32)),
+		must(NewUint32ArrayValue("Uint32Array", 1, []uint32{0, math.MaxUint32})),
+		must(NewUint64Value("Uint64", 1, math.MaxUint64)),
+		must(NewUint64ArrayValue("Uint64Array", 1, []uint64{0, math.MaxUint64})),
+		must(NewInt8Value("Int8", 1, math.MinInt8)),
+		must(NewInt8ArrayValue("Int8Array", 1, []int8{math.MinInt8, math.MaxInt8})),
+		must(NewInt16Value("Int16", 1, math.MinInt16)),
+		must(NewInt16ArrayValue("Int16Array", 1, []int16{math.MinInt16, math.MaxInt16})),
+		must(NewInt32Value("Int32", 1, math.MinInt32)),
+		must(NewInt32ArrayValue("Int32Array", 1, []int32{math.MinInt32, math.MaxInt32})),
+		must(NewInt64Value("Int64", 1, math.MinInt64)),
+		must(NewInt64ArrayValue("Int64Array", 1, []int64{math.MinInt64, math.MaxInt64})),
+		must(NewFloat32Value("Float32", 1, 0.1)),
+		must(NewFloat32Value("Float32NaN", 1, float32(math.NaN()))),
+		must(NewFloat32ArrayValue("Float32Array", 1, []float32{0.1, -math.MaxFloat32})),
+		must(NewFloat64Value("Float64", 1, math.SmallestNonzeroFloat64)),
+		must(NewFloat64Value("Float64Inf", 1, math.Inf(-1))),
+		must(NewFloat64ArrayValue("Float64Array", 1, []float64{0.1, math.MaxFloat64})),
+		must(NewBinaryValue("Binary", 1, []byte{0x00, 0xFF, 0x10})),
+		object,
+	}
+}
+
+func assertSameCommandValue(t *testing.T, result *CommandValue, expected *CommandValue) {
+	if result.DeviceResourceName != expected.DeviceResourceName || result.Origin != expected.Origin || result.Type != expected.Type {
+		t.Fatalf("Unexpect CommandValue %v, should be %v", result, expected)
+	}
+	if result.ValueToString(contract.ENotation) != expected.ValueToString(contract.ENotation) ||
+		result.ValueToString() != expected.ValueToString() ||
+		!reflect.DeepEqual(result.BinValue, expected.BinValue) {
+		t.Fatalf("Unexpect value %s, should be %s", result.ValueToString(), expected.ValueToString())
+	}
+	if !reflect.DeepEqual(result.Flags, expected.Flags) || result.Quality != expected.Quality || !reflect.DeepEqual(result.Tags, expected.Tags) {
+		t.Fatalf("Unexpect metadata %v %v %v of %s", result.Flags, result.Quality, result.Tags, result.DeviceResourceName)
+	}
+}
+
+func TestCommandValue_JSONRoundTrip(t *testing.T) {
+	for _, cv := range marshalTestValues(t) {
+		t.Run(cv.DeviceResourceName, func(t *testing.T) {
+			data, err := json.Marshal(cv)
+			if err != nil {
+				t.Fatalf("Fail to marshal CommandValue, error: %v", err)
+			}
+			var result CommandValue
+			if err = json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("Fail to unmarshal %s, error: %v", data, err)
+			}
+			assertSameCommandValue(t, &result, cv)
+		})
+	}
+}
+
+func TestCommandValue_CBORRoundTrip(t *testing.T) {
+	for _, cv := range marshalTestValues(t) {
+		t.Run(cv.DeviceResourceName, func(t *testing.T) {
+			data, err := cbor.Marshal(cv)
+			if err != nil {
+				t.Fatalf("Fail to marshal CommandValue, error: %v", err)
+			}
+			var result CommandValue
+			if err = cbor.Unmarshal(data, &result); err != nil {
+				t.Fatalf("Fail to unmarshal CommandValue, error: %v", err)
+			}
+			assertSameCommandValue(t, &result, cv)
+		})
+	}
+}
+
+func TestCommandValue_MarshalJSONFormat(t *testing.T) {
+	cv, _ := NewInt16ArrayValue("Levels", 5, []int16{-1, 2})
+
+	data, _ := json.Marshal(cv)
+
+	expected := `{"deviceResourceName":"Levels","origin":5,"type":"Int16Array","value":[-1,2]}`
+	if string(data) != expected {
+		t.Fatalf("Unexpect encoding %s, should be %s", data, expected)
+	}
+}
+
+func TestCommandValue_UnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown type", `{"deviceResourceName":"r","type":"Decimal","value":1}`},
+		{"overflow", `{"deviceResourceName":"r","type":"Int8","value":128}`},
+		{"wrong type", `{"deviceResourceName":"r","type":"Bool","value":"true"}`},
+		{"array overflow", `{"deviceResourceName":"r","type":"Uint8Array","value":[256]}`},
+		{"invalid float", `{"deviceResourceName":"r","type":"Float64","value":"Infinity"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cv CommandValue
+			if err := json.Unmarshal([]byte(tt.data), &cv); err == nil {
+				t.Fatalf("%s should not be decoded, result %v", tt.data, cv.String())
+			}
+		})
+	}
+}
+
+func TestAsyncValues_JSON(t *testing.T) {
+	recorded := `{"deviceName":"device","commandValues":[{"deviceResourceName":"Temperature","origin":1,"type":"Float32","value":21.5}],"tags":{"replay":"true"}}`
+
+	var acv AsyncValues
+	if err := json.Unmarshal([]byte(recorded), &acv); err != nil {
+		t.Fatalf("Fail to decode recorded values, error: %v", err)
+	}
+	if acv.DeviceName != "device" || len(acv.CommandValues) != 1 || acv.Tags["replay"] != "true" {
+		t.Fatalf("Unexpect recorded values %v", acv)
+	}
+	if v, err := acv.CommandValues[0].Float32Value(); err != nil || v != 21.5 {
+		t.Fatalf("Unexpect recorded value %v, error: %v", v, err)
+	}
+}
@@ -0,0 +1,238 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// numericCommandValue is a CommandValue allocated together with the storage of
+// its NumericValue.
+type numericCommandValue struct {
+	cv      CommandValue
+	numeric [8]byte
+}
+
+// newNumericValue creates a CommandValue holding the lowest size bytes of bits
+// with a single allocation. The value is stored typed, NumericValue is its
+// big-endian view kept for the ProtocolDriver implementations reading it. The
+// storage of the view is not part of the CommandValue, so copying the
+// CommandValue struct can't change the value of another CommandValue.
+func newNumericValue(name string, origin int64, t ValueType, bits uint64, size int) *CommandValue {
+	n := &numericCommandValue{cv: CommandValue{DeviceResourceName: name, Origin: origin, Type: t}}
+	n.cv.storeNumeric(n.numeric[:size:size], bits)
+	return &n.cv
+}
+
+// setNumeric replaces the value by the lowest size bytes of bits, the previous
+// NumericValue may be shared by copies of the CommandValue.
+func (cv *CommandValue) setNumeric(bits uint64, size int) {
+	cv.storeNumeric(make([]byte, size), bits)
+}
+
+// storeNumeric stores the lowest len(data) bytes of bits as the typed value
+// and data as its NumericValue view.
+func (cv *CommandValue) storeNumeric(data []byte, bits uint64) {
+	if len(data) < 8 {
+		bits &= 1<<(8*uint(len(data))) - 1
+	}
+	putNumeric(data, bits)
+	cv.numeric = bits
+	cv.numericData = &data[0]
+	cv.NumericValue = data
+}
+
+// putNumeric stores bits big-endian in data, whose length is 1, 2, 4 or 8.
+func putNumeric(data []byte, bits uint64) {
+	switch len(data) {
+	case 1:
+		data[0] = byte(bits)
+	case 2:
+		binary.BigEndian.PutUint16(data, uint16(bits))
+	case 4:
+		binary.BigEndian.PutUint32(data, uint32(bits))
+	default:
+		binary.BigEndian.PutUint64(data, bits)
+	}
+}
+
+// numericBits returns the typed value, or decodes the first size bytes of
+// NumericValue if it was set by a ProtocolDriver. It fails like binary.Read
+// if NumericValue is too short.
+func (cv *CommandValue) numericBits(size int) (uint64, error) {
+	data := cv.NumericValue
+	if len(data) == size && cv.numericData == &data[0] {
+		return cv.numeric, nil
+	}
+	if len(data) < size {
+		if len(data) == 0 {
+			return 0, io.EOF
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	switch size {
+	case 1:
+		return uint64(data[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(data)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), nil
+	default:
+		return binary.BigEndian.Uint64(data), nil
+	}
+}
+
+// AppendValueToString appends the string format of the value, as returned by
+// ValueToString, to dst and returns the extended buffer. Unlike ValueToString
+// it doesn't allocate when dst has enough capacity.
+func (cv *CommandValue) AppendValueToString(dst []byte, encoding ...string) []byte {
+	switch cv.Type {
+	case Bool:
+		v, _ := cv.numericBits(1)
+		return strconv.AppendBool(dst, v != 0)
+	case Uint8:
+		v, _ := cv.numericBits(1)
+		return strconv.AppendUint(dst, v, 10)
+	case Uint16:
+		v, _ := cv.numericBits(2)
+		return strconv.AppendUint(dst, v, 10)
+	case Uint32:
+		v, _ := cv.numericBits(4)
+		return strconv.AppendUint(dst, v, 10)
+	case Uint64:
+		v, _ := cv.numericBits(8)
+		return strconv.AppendUint(dst, v, 10)
+	case Int8:
+		v, _ := cv.numericBits(1)
+		return strconv.AppendInt(dst, int64(int8(v)), 10)
+	case Int16:
+		v, _ := cv.numericBits(2)
+		return strconv.AppendInt(dst, int64(int16(v)), 10)
+	case Int32:
+		v, _ := cv.numericBits(4)
+		return strconv.AppendInt(dst, int64(int32(v)), 10)
+	case Int64:
+		v, _ := cv.numericBits(8)
+		return strconv.AppendInt(dst, int64(v), 10)
+	case Float32, Float64:
+		return cv.appendFloat(dst, getFloatEncoding(encoding))
+	case Float32Array, Float64Array:
+		if len(encoding) > 0 {
+			return cv.appendFloatArray(dst, getFloatEncoding(encoding))
+		}
+		return cv.appendArray(dst)
+	case Binary:
+		return append(dst, cv.ValueToString()...)
+	case BoolArray, Uint8Array, Uint16Array, Uint32Array, Uint64Array, Int8Array, Int16Array, Int32Array, Int64Array:
+		return cv.appendArray(dst)
+	default:
+		// String and Object
+		return append(dst, cv.stringValue...)
+	}
+}
+
+// appendFloat appends the float value in eNotation or as base64 encoding of
+// NumericValue.
+func (cv *CommandValue) appendFloat(dst []byte, floatEncoding string) []byte {
+	if floatEncoding == contract.Base64Encoding {
+		n := base64.StdEncoding.EncodedLen(len(cv.NumericValue))
+		if cap(dst)-len(dst) < n {
+			grown := make([]byte, len(dst), len(dst)+n)
+			copy(grown, dst)
+			dst = grown
+		}
+		base64.StdEncoding.Encode(dst[len(dst):len(dst)+n], cv.NumericValue)
+		return dst[:len(dst)+n]
+	}
+	if cv.Type == Float32 {
+		v, _ := cv.numericBits(4)
+		return strconv.AppendFloat(dst, float64(math.Float32frombits(uint32(v))), 'e', 6, 32)
+	}
+	v, _ := cv.numericBits(8)
+	return strconv.AppendFloat(dst, math.Float64frombits(v), 'e', 6, 64)
+}
+
+// appendFloatArray appends the float array as JSON array whose elements are
+// in eNotation, or strings holding the base64 encoding of the big-endian bytes
+// of each element.
+func (cv *CommandValue) appendFloatArray(dst []byte, floatEncoding string) []byte {
+	var n int
+	var isNil bool
+	var elem func(int) (float64, uint64)
+	bits := 64
+	switch values := cv.array.(type) {
+	case []float32:
+		n, isNil, bits = len(values), values == nil, 32
+		elem = func(i int) (float64, uint64) {
+			return float64(values[i]), uint64(math.Float32bits(values[i]))
+		}
+	case []float64:
+		n, isNil = len(values), values == nil
+		elem = func(i int) (float64, uint64) {
+			return values[i], math.Float64bits(values[i])
+		}
+	default:
+		return dst
+	}
+
+	return appendJSONArray(dst, n, isNil, func(b []byte, i int) []byte {
+		f, raw := elem(i)
+		if floatEncoding != contract.Base64Encoding {
+			return strconv.AppendFloat(b, f, 'e', 6, bits)
+		}
+		var data [8]byte
+		size := bits / 8
+		putNumeric(data[:size], raw)
+		b = append(b, '"')
+		b = append(b, base64.StdEncoding.EncodeToString(data[:size])...)
+		return append(b, '"')
+	})
+}
+
+// appendJSONArray appends the JSON array of n elements like encoding/json,
+// a nil slice is encoded as null.
+func appendJSONArray(dst []byte, n int, isNil bool, elem func([]byte, int) []byte) []byte {
+	if isNil {
+		return append(dst, "null"...)
+	}
+	dst = append(dst, '[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = elem(dst, i)
+	}
+	return append(dst, ']')
+}
+
+// appendJSONFloat appends the float like encoding/json, which uses the
+// exponent format only for very small and very large values.
+func appendJSONFloat(dst []byte, f float64, bits int) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
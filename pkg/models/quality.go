@@ -0,0 +1,87 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QualityStatus is the overall status of the quality of a value.
+type QualityStatus string
+
+const (
+	// QualityGood marks a value that can be trusted, it is the status of a
+	// CommandValue whose Quality is not set.
+	QualityGood QualityStatus = "good"
+	// QualityUncertain marks a value that is present but may be inaccurate,
+	// e.g. stale or out of calibration.
+	QualityUncertain QualityStatus = "uncertain"
+	// QualityBad marks a value that must not be trusted, e.g. because of a
+	// sensor fault.
+	QualityBad QualityStatus = "bad"
+)
+
+// Sub-statuses detailing the reason of a QualityUncertain or QualityBad status.
+// Drivers may use their own sub-statuses as well.
+const (
+	SubStatusStale            = "stale"
+	SubStatusSensorFault      = "sensorFault"
+	SubStatusOutOfCalibration = "outOfCalibration"
+	SubStatusCommFailure      = "commFailure"
+	SubStatusAssertionFailed  = "assertionFailed"
+	SubStatusClamped          = "clamped"
+)
+
+// QualitySeparator separates the status and the sub-status in the string
+// representation of a Quality, e.g. "uncertain:stale".
+const QualitySeparator = ":"
+
+// Quality describes how far the value of a CommandValue can be trusted. The
+// zero value is a good quality.
+type Quality struct {
+	Status    QualityStatus `json:"status"`
+	SubStatus string        `json:"subStatus,omitempty"`
+}
+
+// NewQuality creates a Quality with the given status and sub-status.
+func NewQuality(status QualityStatus, subStatus string) Quality {
+	return Quality{Status: status, SubStatus: subStatus}
+}
+
+// ParseQuality parses the string representation of a Quality, i.e. the status
+// optionally followed by the QualitySeparator and a sub-status.
+func ParseQuality(s string) (Quality, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), QualitySeparator, 2)
+	q := Quality{Status: QualityStatus(strings.ToLower(parts[0]))}
+	switch q.Status {
+	case "", QualityGood, QualityUncertain, QualityBad:
+	default:
+		return Quality{}, fmt.Errorf("unsupported quality status %s", parts[0])
+	}
+	if len(parts) == 2 {
+		q.SubStatus = parts[1]
+	}
+	return q, nil
+}
+
+// IsGood reports whether the status is good or not set.
+func (q Quality) IsGood() bool {
+	return q.Status == "" || q.Status == QualityGood
+}
+
+// String returns the string representation parsed by ParseQuality.
+func (q Quality) String() string {
+	status := q.Status
+	if status == "" {
+		status = QualityGood
+	}
+	if q.SubStatus == "" {
+		return string(status)
+	}
+	return string(status) + QualitySeparator + q.SubStatus
+}
@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"testing"
+)
+
+func TestParseQuality(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected Quality
+		valid    bool
+	}{
+		{"empty", "", Quality{}, true},
+		{"status", "BAD", Quality{Status: QualityBad}, true},
+		{"sub-status", "uncertain:stale", Quality{Status: QualityUncertain, SubStatus: SubStatusStale}, true},
+		{"unknown status", "poor", Quality{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuality(tt.value)
+			if (err == nil) != tt.valid || q != tt.expected {
+				t.Fatalf("Unexpect test result, quality %v error %v, should be %v", q, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuality_String(t *testing.T) {
+	if s := (Quality{}).String(); s != "good" {
+		t.Fatalf("Unexpect string %s of the zero quality", s)
+	}
+	q := NewQuality(QualityBad, SubStatusSensorFault)
+	if q.IsGood() || q.String() != "bad:sensorFault" {
+		t.Fatalf("Unexpect string %s", q.String())
+	}
+	if parsed, _ := ParseQuality(q.String()); parsed != q {
+		t.Fatalf("Unexpect round trip result %v", parsed)
+	}
+}
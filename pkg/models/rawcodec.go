@@ -0,0 +1,413 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Attributes of a DeviceResource describing the raw representation of its value
+// on register-oriented devices.
+const (
+	// RawTypeAttribute is the ValueType of the raw data, the declared ValueType by default.
+	RawTypeAttribute = "rawType"
+	// ByteOrderAttribute is the order of the bytes within a 16-bit word, BigEndian by default.
+	ByteOrderAttribute = "byteOrder"
+	// WordOrderAttribute is the order of the 16-bit words, HighFirst by default.
+	WordOrderAttribute = "wordOrder"
+	// BitOffsetAttribute is the position of the least significant bit of the
+	// value, counted from the least significant bit of the raw data.
+	BitOffsetAttribute = "bitOffset"
+
+	BigEndian     = "BigEndian"
+	LittleEndian  = "LittleEndian"
+	HighWordFirst = "HighFirst"
+	LowWordFirst  = "LowFirst"
+)
+
+// RawFormat describes how a value is laid out in raw bytes.
+type RawFormat struct {
+	RawType      ValueType
+	LittleEndian bool
+	LowWordFirst bool
+	BitOffset    uint
+}
+
+// ParseRawFormat parses the raw format attributes of a DeviceResource whose
+// declared ValueType is valueType.
+func ParseRawFormat(valueType ValueType, attributes map[string]string) (RawFormat, error) {
+	f := RawFormat{RawType: valueType}
+	if t, ok := attributes[RawTypeAttribute]; ok {
+		f.RawType = ParseValueType(t)
+	}
+	if rawSize(f.RawType) < 0 {
+		return f, fmt.Errorf("raw type %s is not a scalar numeric or Bool type", attributes[RawTypeAttribute])
+	}
+
+	switch order := attributes[ByteOrderAttribute]; {
+	case order == "" || strings.EqualFold(order, BigEndian):
+	case strings.EqualFold(order, LittleEndian):
+		f.LittleEndian = true
+	default:
+		return f, fmt.Errorf("unsupported byte order %s", order)
+	}
+	switch order := attributes[WordOrderAttribute]; {
+	case order == "" || strings.EqualFold(order, HighWordFirst):
+	case strings.EqualFold(order, LowWordFirst):
+		f.LowWordFirst = true
+	default:
+		return f, fmt.Errorf("unsupported word order %s", order)
+	}
+	if offset, ok := attributes[BitOffsetAttribute]; ok {
+		v, err := strconv.ParseUint(offset, 10, 8)
+		if err != nil || v > 63 {
+			return f, fmt.Errorf("invalid bit offset %s", offset)
+		}
+		f.BitOffset = uint(v)
+	}
+	return f, nil
+}
+
+// DecodeRaw decodes raw bytes, e.g. the BinValue of a Binary CommandValue,
+// according to the raw format attributes into a CommandValue of valueType.
+// Raw bytes of the Object valueType are decoded as CBOR.
+func DecodeRaw(DeviceResourceName string, origin int64, raw []byte, valueType ValueType, attributes map[string]string) (*CommandValue, error) {
+	if valueType == Object {
+		return NewObjectValueFromCBOR(DeviceResourceName, origin, raw)
+	}
+	f, err := ParseRawFormat(valueType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.reorder(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	size := rawSize(f.RawType)
+	if f.RawType == Bool || f.BitOffset > 0 || len(data) != size {
+		bits, err := f.extractBits(data)
+		if err != nil {
+			return nil, err
+		}
+		if f.RawType == Bool {
+			return convertRawValue(DeviceResourceName, origin, bits == 1, valueType)
+		}
+		data = make([]byte, 8)
+		binary.BigEndian.PutUint64(data, bits)
+		data = data[8-size:]
+	}
+
+	value, err := decodeRawType(data, f.RawType)
+	if err != nil {
+		return nil, err
+	}
+	return convertRawValue(DeviceResourceName, origin, value, valueType)
+}
+
+// DecodeRegisters decodes a block of 16-bit registers according to the raw
+// format attributes into a CommandValue of valueType.
+func DecodeRegisters(DeviceResourceName string, origin int64, registers []uint16, valueType ValueType, attributes map[string]string) (*CommandValue, error) {
+	raw := make([]byte, len(registers)*2)
+	for i, r := range registers {
+		binary.BigEndian.PutUint16(raw[i*2:], r)
+	}
+	return DecodeRaw(DeviceResourceName, origin, raw, valueType, attributes)
+}
+
+// EncodeRaw encodes the value of the CommandValue into raw bytes according to
+// the raw format attributes. With a bit offset the value is shifted into whole
+// 16-bit words, the caller is responsible for merging it with the other bits.
+// Object values are encoded as CBOR.
+func EncodeRaw(cv *CommandValue, attributes map[string]string) ([]byte, error) {
+	if cv.Type == Object {
+		return cv.ObjectValueCBOR()
+	}
+	f, err := ParseRawFormat(cv.Type, attributes)
+	if err != nil {
+		return nil, err
+	}
+	value, err := cv.scalarValue()
+	if err != nil {
+		return nil, err
+	}
+	rawCV, err := convertRawValue(cv.DeviceResourceName, cv.Origin, value, f.RawType)
+	if err != nil {
+		return nil, err
+	}
+	data := rawCV.NumericValue
+
+	if f.RawType == Bool || f.BitOffset > 0 {
+		bitSize := uint(len(data) * 8)
+		if f.RawType == Bool {
+			bitSize = 1
+		}
+		if f.BitOffset+bitSize > 64 {
+			return nil, fmt.Errorf("bit offset %d exceeds 64 bits for raw type %v", f.BitOffset, f.RawType)
+		}
+		var bits uint64
+		for _, b := range data {
+			bits = bits<<8 | uint64(b)
+		}
+		words := (f.BitOffset + bitSize + 15) / 16
+		shifted := make([]byte, 8)
+		binary.BigEndian.PutUint64(shifted, bits<<f.BitOffset)
+		data = shifted[8-words*2:]
+	}
+	return f.reorder(data)
+}
+
+// EncodeRegisters encodes the value of the CommandValue into 16-bit registers
+// according to the raw format attributes, see EncodeRaw.
+func EncodeRegisters(cv *CommandValue, attributes map[string]string) ([]uint16, error) {
+	data, err := EncodeRaw(cv, attributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%2 != 0 {
+		data = append([]byte{0}, data...)
+	}
+	registers := make([]uint16, len(data)/2)
+	for i := range registers {
+		registers[i] = binary.BigEndian.Uint16(data[i*2:])
+	}
+	return registers, nil
+}
+
+// reorder converts between the device byte and word order and big-endian,
+// both swaps are their own inverse.
+func (f RawFormat) reorder(raw []byte) ([]byte, error) {
+	data := make([]byte, len(raw))
+	copy(data, raw)
+	if len(data) <= 1 || (!f.LittleEndian && !f.LowWordFirst) {
+		return data, nil
+	}
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("raw data of %d bytes cannot be reordered in 16-bit words", len(data))
+	}
+	if f.LittleEndian {
+		for i := 0; i < len(data); i += 2 {
+			data[i], data[i+1] = data[i+1], data[i]
+		}
+	}
+	if f.LowWordFirst {
+		for i, j := 0, len(data)-2; i < j; i, j = i+2, j-2 {
+			data[i], data[i+1], data[j], data[j+1] = data[j], data[j+1], data[i], data[i+1]
+		}
+	}
+	return data, nil
+}
+
+func (f RawFormat) extractBits(data []byte) (uint64, error) {
+	bitSize := uint(rawSize(f.RawType) * 8)
+	if f.RawType == Bool {
+		bitSize = 1
+	}
+	if len(data) > 8 || uint(len(data)*8) < f.BitOffset+bitSize {
+		return 0, fmt.Errorf("raw data of %d bytes does not hold %d bits at offset %d", len(data), bitSize, f.BitOffset)
+	}
+	var bits uint64
+	for _, b := range data {
+		bits = bits<<8 | uint64(b)
+	}
+	bits >>= f.BitOffset
+	if bitSize < 64 {
+		bits &= 1<<bitSize - 1
+	}
+	return bits, nil
+}
+
+// rawSize returns the size in bytes of a raw type, 0 for Bool which is a single
+// bit, and -1 for types that cannot be decoded from raw data.
+func rawSize(t ValueType) int {
+	switch t {
+	case Bool:
+		return 0
+	case Uint8, Int8:
+		return 1
+	case Uint16, Int16:
+		return 2
+	case Uint32, Int32, Float32:
+		return 4
+	case Uint64, Int64, Float64:
+		return 8
+	default:
+		return -1
+	}
+}
+
+func decodeRawType(data []byte, t ValueType) (interface{}, error) {
+	var value interface{}
+	switch t {
+	case Uint8:
+		value = new(uint8)
+	case Uint16:
+		value = new(uint16)
+	case Uint32:
+		value = new(uint32)
+	case Uint64:
+		value = new(uint64)
+	case Int8:
+		value = new(int8)
+	case Int16:
+		value = new(int16)
+	case Int32:
+		value = new(int32)
+	case Int64:
+		value = new(int64)
+	case Float32:
+		value = new(float32)
+	case Float64:
+		value = new(float64)
+	default:
+		return nil, fmt.Errorf("raw type %v is not supported", t)
+	}
+	if err := decodeValue(bytes.NewReader(data), value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case *uint8:
+		return *v, nil
+	case *uint16:
+		return *v, nil
+	case *uint32:
+		return *v, nil
+	case *uint64:
+		return *v, nil
+	case *int8:
+		return *v, nil
+	case *int16:
+		return *v, nil
+	case *int32:
+		return *v, nil
+	case *int64:
+		return *v, nil
+	case *float32:
+		return *v, nil
+	default:
+		return *value.(*float64), nil
+	}
+}
+
+// scalarValue returns the value of a scalar numeric or Bool CommandValue.
+func (cv *CommandValue) scalarValue() (interface{}, error) {
+	switch cv.Type {
+	case Bool:
+		return cv.BoolValue()
+	case Uint8, Uint16, Uint32, Uint64, Int8, Int16, Int32, Int64, Float32, Float64:
+		return decodeRawType(cv.NumericValue, cv.Type)
+	default:
+		return nil, fmt.Errorf("value type %v cannot be encoded to raw data", cv.Type)
+	}
+}
+
+// convertRawValue converts a Go scalar value to a CommandValue of the given
+// type, failing if the value is out of the range of the type.
+func convertRawValue(DeviceResourceName string, origin int64, value interface{}, t ValueType) (*CommandValue, error) {
+	var i int64
+	var u uint64
+	var f float64
+	var signed, unsigned bool
+	switch v := value.(type) {
+	case bool:
+		if t == Bool {
+			return NewBoolValue(DeviceResourceName, origin, v)
+		}
+		if v {
+			u = 1
+		}
+		unsigned = true
+	case uint8:
+		u, unsigned = uint64(v), true
+	case uint16:
+		u, unsigned = uint64(v), true
+	case uint32:
+		u, unsigned = uint64(v), true
+	case uint64:
+		u, unsigned = v, true
+	case int8:
+		i, signed = int64(v), true
+	case int16:
+		i, signed = int64(v), true
+	case int32:
+		i, signed = int64(v), true
+	case int64:
+		i, signed = v, true
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return nil, fmt.Errorf("raw value %v of type %T is not supported", value, value)
+	}
+	switch {
+	case signed:
+		f = float64(i)
+		unsigned = i >= 0
+		u = uint64(i)
+	case unsigned:
+		f = float64(u)
+		signed = u <= math.MaxInt64
+		i = int64(u)
+	default:
+		if f == math.Trunc(f) {
+			signed = f >= math.MinInt64 && f < math.MaxInt64
+			unsigned = f >= 0 && f < math.MaxUint64
+			i, u = int64(f), uint64(f)
+		}
+	}
+
+	outOfRange := fmt.Errorf("raw value %v cannot be represented as %v", value, t)
+	switch t {
+	case Bool:
+		return NewBoolValue(DeviceResourceName, origin, f != 0)
+	case Uint8, Uint16, Uint32, Uint64:
+		bits := uint(rawSize(t) * 8)
+		if !unsigned || (bits < 64 && u >= 1<<bits) {
+			return nil, outOfRange
+		}
+		switch t {
+		case Uint8:
+			return NewUint8Value(DeviceResourceName, origin, uint8(u))
+		case Uint16:
+			return NewUint16Value(DeviceResourceName, origin, uint16(u))
+		case Uint32:
+			return NewUint32Value(DeviceResourceName, origin, uint32(u))
+		default:
+			return NewUint64Value(DeviceResourceName, origin, u)
+		}
+	case Int8, Int16, Int32, Int64:
+		bits := uint(rawSize(t) * 8)
+		if !signed || (bits < 64 && (i < -(1<<(bits-1)) || i >= 1<<(bits-1))) {
+			return nil, outOfRange
+		}
+		switch t {
+		case Int8:
+			return NewInt8Value(DeviceResourceName, origin, int8(i))
+		case Int16:
+			return NewInt16Value(DeviceResourceName, origin, int16(i))
+		case Int32:
+			return NewInt32Value(DeviceResourceName, origin, int32(i))
+		default:
+			return NewInt64Value(DeviceResourceName, origin, i)
+		}
+	case Float32:
+		if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+			return nil, outOfRange
+		}
+		return NewFloat32Value(DeviceResourceName, origin, float32(f))
+	case Float64:
+		return NewFloat64Value(DeviceResourceName, origin, f)
+	default:
+		return nil, fmt.Errorf("raw value cannot be converted to %v", t)
+	}
+}
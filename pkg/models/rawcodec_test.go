@@ -0,0 +1,128 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeRegisters(t *testing.T) {
+	tests := []struct {
+		name       string
+		registers  []uint16
+		valueType  ValueType
+		attributes map[string]string
+		expected   interface{}
+	}{
+		{"Int32 big-endian", []uint16{0xFFFF, 0xFFFE}, Int32, nil, int32(-2)},
+		{"Int32 low word first", []uint16{0xFFFE, 0xFFFF}, Int32, map[string]string{WordOrderAttribute: LowWordFirst}, int32(-2)},
+		{"Uint32 little-endian", []uint16{0x3412, 0x7856}, Uint32, map[string]string{ByteOrderAttribute: LittleEndian, WordOrderAttribute: LowWordFirst}, uint32(0x56781234)},
+		{"Float32 word swap", []uint16{0x0000, 0x3FC0}, Float32, map[string]string{WordOrderAttribute: LowWordFirst}, float32(1.5)},
+		{"Int16 raw to Float64", []uint16{0xFF9C}, Float64, map[string]string{RawTypeAttribute: "Int16"}, float64(-100)},
+		{"Bool bit offset", []uint16{0x0010}, Bool, map[string]string{BitOffsetAttribute: "4"}, true},
+		{"Uint8 high byte", []uint16{0xAB12}, Uint8, map[string]string{BitOffsetAttribute: "8"}, uint8(0xAB)},
+		{"Int8 low byte of register", []uint16{0x00FF}, Int16, map[string]string{RawTypeAttribute: "Int8"}, int16(-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv, err := DecodeRegisters("resource", 0, tt.registers, tt.valueType, tt.attributes)
+			if err != nil {
+				t.Fatalf("Fail to decode registers, error: %v", err)
+			}
+			if cv.Type != tt.valueType {
+				t.Fatalf("Unexpect value type %v, should be %v", cv.Type, tt.valueType)
+			}
+			value, _ := cv.scalarValue()
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Fatalf("Unexpect test result, result '%v' should be '%v'", value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeRaw_errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        []byte
+		valueType  ValueType
+		attributes map[string]string
+	}{
+		{"too short", []byte{0x01}, Int32, nil},
+		{"out of range", []byte{0xFF, 0xFF}, Uint8, map[string]string{RawTypeAttribute: "Uint16"}},
+		{"negative to unsigned", []byte{0xFF}, Uint16, map[string]string{RawTypeAttribute: "Int8"}},
+		{"odd length word swap", []byte{0x01, 0x02, 0x03}, Uint16, map[string]string{WordOrderAttribute: LowWordFirst}},
+		{"invalid byte order", []byte{0x01, 0x02}, Uint16, map[string]string{ByteOrderAttribute: "Middle"}},
+		{"invalid raw type", []byte{0x01}, Uint16, map[string]string{RawTypeAttribute: "Binary"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeRaw("resource", 0, tt.raw, tt.valueType, tt.attributes); err == nil {
+				t.Fatal("decoding should fail")
+			}
+		})
+	}
+}
+
+func TestEncodeRaw(t *testing.T) {
+	attributes := map[string]string{ByteOrderAttribute: LittleEndian, WordOrderAttribute: LowWordFirst}
+	cv, _ := NewFloat64Value("resource", 0, math.Pi)
+
+	raw, err := EncodeRaw(cv, attributes)
+	if err != nil {
+		t.Fatalf("Fail to encode raw value, error: %v", err)
+	}
+	if !bytes.Equal(raw, []byte{0x18, 0x2D, 0x44, 0x54, 0xFB, 0x21, 0x09, 0x40}) {
+		t.Fatalf("Unexpect little-endian encoding % X", raw)
+	}
+
+	decoded, err := DecodeRaw("resource", 0, raw, Float64, attributes)
+	if err != nil {
+		t.Fatalf("Fail to decode raw value, error: %v", err)
+	}
+	if v, _ := decoded.Float64Value(); v != math.Pi {
+		t.Fatalf("Unexpect round trip result %v", v)
+	}
+}
+
+func TestEncodeRegisters(t *testing.T) {
+	tests := []struct {
+		name       string
+		cv         func() *CommandValue
+		attributes map[string]string
+		expected   []uint16
+	}{
+		{"Int32 low word first", func() *CommandValue { cv, _ := NewInt32Value("resource", 0, -2); return cv },
+			map[string]string{WordOrderAttribute: LowWordFirst}, []uint16{0xFFFE, 0xFFFF}},
+		{"Float64 to raw Int16", func() *CommandValue { cv, _ := NewFloat64Value("resource", 0, -100); return cv },
+			map[string]string{RawTypeAttribute: "Int16"}, []uint16{0xFF9C}},
+		{"Bool bit offset", func() *CommandValue { cv, _ := NewBoolValue("resource", 0, true); return cv },
+			map[string]string{BitOffsetAttribute: "17"}, []uint16{0x0002, 0x0000}},
+		{"Uint8", func() *CommandValue { cv, _ := NewUint8Value("resource", 0, 7); return cv }, nil, []uint16{0x0007}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registers, err := EncodeRegisters(tt.cv(), tt.attributes)
+			if err != nil {
+				t.Fatalf("Fail to encode registers, error: %v", err)
+			}
+			if !reflect.DeepEqual(registers, tt.expected) {
+				t.Fatalf("Unexpect test result, result '%04X' should be '%04X'", registers, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeRaw_outOfRange(t *testing.T) {
+	cv, _ := NewFloat64Value("resource", 0, 1.5)
+
+	if _, err := EncodeRaw(cv, map[string]string{RawTypeAttribute: "Int16"}); err == nil {
+		t.Fatal("non-integral value should not be encoded to an integer raw type")
+	}
+}
@@ -0,0 +1,14 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+// TransformFunc is a driver-defined transform registered with the Device
+// Service and referenced by name in the "transforms" attribute of a
+// DeviceResource. attributes are the Attributes of the DeviceResource. The
+// returned CommandValue replaces the given one and may be of a different
+// ValueType, e.g. a Uint16 holding BCD digits decoded to an Int32.
+type TransformFunc func(cv *CommandValue, attributes map[string]string) (*CommandValue, error)
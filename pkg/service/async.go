@@ -14,10 +14,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	"github.com/edgexfoundry/device-sdk-go/internal/blob"
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/filter"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler"
-	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
+	"github.com/edgexfoundry/device-sdk-go/internal/pipeline"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -27,10 +30,8 @@ import (
 // before being pushed to Core Data.
 func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
-	defer func() {
-		close(svc.asyncCh)
-		wg.Done()
-	}()
+	// asyncCh isn't closed, the driver and Replay may still send to it
+	defer wg.Done()
 
 	for {
 		select {
@@ -38,6 +39,7 @@ func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 			return
 		case acv := <-svc.asyncCh:
 			readings := make([]contract.Reading, 0, len(acv.CommandValues))
+			event := &dsModels.Event{}
 
 			device, ok := cache.Devices().ForName(acv.DeviceName)
 			if !ok {
@@ -53,41 +55,48 @@ func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 					continue
 				}
 
-				if common.CurrentConfig.Device.DataTransform {
-					err := transformer.TransformReadResult(cv, dr.Properties.Value)
-					if err != nil {
-						common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - CommandValue (%s) transformed failed: %v", cv.String(), err))
-						cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Transformation failed for device resource, with value: %s, property value: %v, and error: %v", cv.String(), dr.Properties.Value, err))
-					}
+				// the same pipeline as a read command, a value failing it is
+				// dropped like the reading of a failed command
+				cv, ok = handler.ReadValue(&device, dr, cv)
+				if !ok {
+					common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - the value of Device Resource %s of Device %s failed the read pipeline, the reading is dropped", dr.Name, device.Name))
+					continue
 				}
 
-				err := transformer.CheckAssertion(cv, dr.Properties.Value.Assertion, &device)
+				reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
+				stored, checksum, err := blob.StoreReading(cv, reading)
 				if err != nil {
-					common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - Assertion failed for device resource: %s, with value: %s and assertion: %s, %v", cv.DeviceResourceName, cv.String(), dr.Properties.Value.Assertion, err))
-					cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
+					common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - storing binary value of Device Resource %s failed: %v", cv.DeviceResourceName, err))
+					common.ReleaseReading(reading)
+					continue
 				}
-
-				ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, cv.DeviceResourceName, common.GetCmdMethod)
-				if err != nil {
-					common.LoggingClient.Debug(fmt.Sprintf("processAsyncResults - getting resource operation failed: %s", err.Error()))
-				} else if len(ro.Mappings) > 0 {
-					newCV, ok := transformer.MapCommandValue(cv, ro.Mappings)
-					if ok {
-						cv = newCV
-					} else {
-						common.LoggingClient.Warn(fmt.Sprintf("processAsyncResults - Mapping failed for Device Resource Operation: %s, with value: %s, %v", ro.DeviceCommand, cv.String(), err))
-					}
+				index := len(readings)
+				if stored {
+					event.AddReadingFlags(index, []string{dsModels.FlagBlobReference})
+					event.AddReadingChecksum(index, checksum)
 				}
-
-				reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
 				readings = append(readings, *reading)
+				event.AddReadingFlags(index, cv.Flags)
+				event.AddReadingQuality(index, cv.Quality)
+				event.AddReadingTags(index, common.ReadingTags(cv, dr))
+				common.ReleaseReading(reading)
+			}
+			if len(readings) == 0 {
+				common.LoggingClient.Debug(fmt.Sprintf("processAsyncResults - no reading left for Device %s", device.Name))
+				continue
 			}
 
 			// push to Core Data
-			cevent := contract.Event{Device: device.Name, Readings: readings}
-			event := &dsModels.Event{Event: cevent}
+			event.Event = contract.Event{Device: device.Name, Readings: readings}
+			event.AddTags(common.DeviceTags(device))
+			event.AddTags(acv.Tags)
+			if !filter.Deadbands().Filter(event, device.Profile.Name) {
+				common.LoggingClient.Debug(fmt.Sprintf("processAsyncResults - all readings of Device %s are within the deadband", device.Name))
+				continue
+			}
 			event.Origin = common.GetUniqueOrigin()
-			common.SendEvent(event)
+			pipeline.Send(event)
+			autoevent.EvaluateTriggers(event)
 
 		}
 	}
@@ -16,11 +16,15 @@ import (
 
 	"github.com/edgexfoundry/device-sdk-go/internal/autodiscovery"
 	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	"github.com/edgexfoundry/device-sdk-go/internal/blob"
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/clients"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/container"
+	"github.com/edgexfoundry/device-sdk-go/internal/forward"
+	"github.com/edgexfoundry/device-sdk-go/internal/pipeline"
 	"github.com/edgexfoundry/device-sdk-go/internal/provision"
+	"github.com/edgexfoundry/device-sdk-go/internal/publisher"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	bootstrapContainer "github.com/edgexfoundry/go-mod-bootstrap/bootstrap/container"
 	"github.com/edgexfoundry/go-mod-bootstrap/bootstrap/startup"
@@ -49,6 +53,7 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, _
 	// error in following bootstrap process the device service can correctly
 	// call svc.Stop and gracefully shut down.
 	svc = newService(dic)
+	svc.done = ctx.Done()
 	autoevent.NewManager(ctx, wg)
 
 	if svc.svcInfo.EnableAsyncReadings {
@@ -59,7 +64,37 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, _
 	svc.deviceCh = make(chan []dsModels.DiscoveredDevice)
 	go processAsyncFilterAndAdd(ctx, wg)
 
-	err := clients.InitDependencyClients(ctx, wg)
+	err := common.ValidateFloatEncoding(common.CurrentConfig.Device.FloatEncoding)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: invalid Device.FloatEncoding setting: %v\n", err)
+		return false
+	}
+
+	err = blob.Init(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: failed to create the binary value store: %v\n", err)
+		return false
+	}
+
+	err = publisher.Init(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: failed to create the event publisher: %v\n", err)
+		return false
+	}
+
+	err = forward.Init(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: failed to create the event queue: %v\n", err)
+		return false
+	}
+
+	err = pipeline.Init(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: failed to create the event pipeline: %v\n", err)
+		return false
+	}
+
+	err = clients.InitDependencyClients(ctx, wg)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return false
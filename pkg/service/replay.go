@@ -0,0 +1,44 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// Replay reads recorded driver output, a stream of JSON encoded AsyncValues,
+// and pushes it through the asynchronous readings pipeline as if the driver
+// had sent it. The CommandValues keep their recorded Origin. It returns the
+// number of AsyncValues replayed, and an error if the service stops before
+// all are replayed.
+func (s *Service) Replay(r io.Reader) (int, error) {
+	if !s.AsyncReadings() || s.asyncCh == nil {
+		return 0, fmt.Errorf("asynchronous readings are not enabled, cannot replay recorded values")
+	}
+
+	decoder := json.NewDecoder(r)
+	count := 0
+	for {
+		acv := &dsModels.AsyncValues{}
+		err := decoder.Decode(acv)
+		if err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, fmt.Errorf("fail to decode recorded values #%d: %v", count+1, err)
+		}
+		select {
+		case s.asyncCh <- acv:
+			count++
+		case <-s.done:
+			return count, fmt.Errorf("service stopped, %d recorded values replayed", count)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/container"
 	"github.com/edgexfoundry/device-sdk-go/internal/controller"
+	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	"github.com/edgexfoundry/go-mod-bootstrap/di"
 
@@ -37,6 +38,7 @@ var (
 type Service struct {
 	svcInfo     *common.ServiceInfo
 	asyncCh     chan *dsModels.AsyncValues
+	done        <-chan struct{} // closed when the service stops
 	deviceCh    chan []dsModels.DiscoveredDevice
 	startTime   time.Time
 	controller  controller.RestController
@@ -63,6 +65,14 @@ func (s *Service) AddRoute(route string, handler func(http.ResponseWriter, *http
 	return s.controller.AddRoute(route, handler, methods...)
 }
 
+// AddTransform registers a named read and write transform which DeviceResources
+// reference in their "transforms" attribute. The read function is applied to
+// values returned by the driver before the built-in transforms, and the write
+// function to parameters after the built-in transforms. Either may be nil.
+func (s *Service) AddTransform(name string, read dsModels.TransformFunc, write dsModels.TransformFunc) error {
+	return transformer.RegisterTransform(name, read, write)
+}
+
 // Stop shuts down the Service
 func (s *Service) Stop(force bool) {
 	if s.initiazlied {